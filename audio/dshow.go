@@ -0,0 +1,39 @@
+package audio
+
+import "strings"
+
+// parseDshowAudioDevices 解析 ffmpeg -list_devices true -f dshow 的输出，返回音频设备名称
+// 新版本 ffmpeg 在每行末尾标注 (audio)/(video)，旧版本按 "DirectShow audio devices" 分组列出
+func parseDshowAudioDevices(output string) []string {
+	var devices []string
+	audioSection := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, "] "); strings.HasPrefix(line, "[") && i > 0 {
+			line = strings.TrimSpace(line[i+2:])
+		}
+
+		switch {
+		case strings.HasPrefix(line, "DirectShow audio devices"):
+			audioSection = true
+			continue
+		case strings.HasPrefix(line, "DirectShow video devices"):
+			audioSection = false
+			continue
+		case !strings.HasPrefix(line, `"`):
+			// 设备的替代名称等其他输出
+			continue
+		}
+
+		end := strings.Index(line[1:], `"`)
+		if end < 0 {
+			continue
+		}
+		name := line[1 : end+1]
+		kind := strings.TrimSpace(line[end+2:])
+		if kind == "(audio)" || (kind == "" && audioSection) {
+			devices = append(devices, name)
+		}
+	}
+	return devices
+}
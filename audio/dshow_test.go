@@ -0,0 +1,50 @@
+package audio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDshowAudioDevices(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			"tagged",
+			`[dshow @ 000001d2c5e0e8c0] "Integrated Camera" (video)
+[dshow @ 000001d2c5e0e8c0]   Alternative name "@device_pnp_\\?\usb#vid_5986&pid_2113&mi_00"
+[dshow @ 000001d2c5e0e8c0] "麦克风阵列 (Realtek(R) Audio)" (audio)
+[dshow @ 000001d2c5e0e8c0]   Alternative name "@device_cm_{33D9A762-90C8-11D0-BD43-00A0C911CE86}\wave_{1A2B}"
+[dshow @ 000001d2c5e0e8c0] "Headset Microphone (USB Audio)" (audio)
+dummy: Immediate exit requested`,
+			[]string{"麦克风阵列 (Realtek(R) Audio)", "Headset Microphone (USB Audio)"},
+		},
+		{
+			"sections",
+			`[dshow @ 0000000000e4a240] DirectShow video devices (some may be both video and audio devices)
+[dshow @ 0000000000e4a240]  "Integrated Camera"
+[dshow @ 0000000000e4a240]     Alternative name "@device_pnp_\\?\usb#vid_5986"
+[dshow @ 0000000000e4a240] DirectShow audio devices
+[dshow @ 0000000000e4a240]  "Microphone (Realtek High Definition Audio)"
+[dshow @ 0000000000e4a240]     Alternative name "@device_cm_{33D9A762-90C8-11D0-BD43-00A0C911CE86}\wave_{4A3B}"
+dummy: Immediate exit requested`,
+			[]string{"Microphone (Realtek High Definition Audio)"},
+		},
+		{
+			"none",
+			`[dshow @ 000001] "Integrated Camera" (video)
+[dshow @ 000001] Could not enumerate audio only devices (or none found).`,
+			nil,
+		},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDshowAudioDevices(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDshowAudioDevices() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
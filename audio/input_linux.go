@@ -0,0 +1,34 @@
+package audio
+
+import "os"
+
+// 采集后端
+const (
+	BackendPulse = "pulse"
+	BackendALSA  = "alsa"
+)
+
+// microphoneInput 读取默认输入设备，未指定后端时优先 PulseAudio
+// 没有 PulseAudio 服务时回退到 ALSA
+func microphoneInput(backend, device string) ([]string, error) {
+	if backend == "" {
+		backend = BackendALSA
+		if os.Getenv("PULSE_SERVER") != "" || pulseRunning() {
+			backend = BackendPulse
+		}
+	}
+	if device == "" {
+		device = "default"
+	}
+	return []string{"-f", backend, "-i", device}, nil
+}
+
+// pulseRunning 根据当前用户的 PulseAudio/PipeWire 套接字判断服务是否在运行
+func pulseRunning() bool {
+	runtime := os.Getenv("XDG_RUNTIME_DIR")
+	if runtime == "" {
+		return false
+	}
+	_, err := os.Stat(runtime + "/pulse/native")
+	return err == nil
+}
@@ -0,0 +1,53 @@
+//go:build !linux
+
+package audio
+
+import (
+	"os/exec"
+	"runtime"
+
+	"monitor-desktop-client/ffmpeg"
+)
+
+// 采集后端
+const (
+	BackendPulse = "pulse"
+	BackendALSA  = "alsa"
+)
+
+// microphoneInput 非 Linux 平台需要显式指定 ffmpeg 输入格式和设备
+// Windows 的 dshow 没有 default 设备，未指定设备时枚举后使用第一个音频输入设备
+func microphoneInput(backend, device string) ([]string, error) {
+	if runtime.GOOS == "darwin" {
+		if backend == "" {
+			backend = "avfoundation"
+		}
+		if device == "" {
+			device = ":0"
+		}
+		return []string{"-f", backend, "-i", device}, nil
+	}
+
+	if backend == "" {
+		backend = "dshow"
+	}
+	if device == "" {
+		if backend != "dshow" {
+			return nil, ErrNoMicrophone
+		}
+		devices := dshowAudioDevices()
+		if len(devices) == 0 {
+			return nil, ErrNoMicrophone
+		}
+		device = "audio=" + devices[0]
+	}
+	return []string{"-f", backend, "-i", device}, nil
+}
+
+// dshowAudioDevices 通过 ffmpeg -list_devices 枚举 DirectShow 音频输入设备
+func dshowAudioDevices() []string {
+	cmd := exec.Command(ffmpeg.Path(), "-hide_banner", "-list_devices", "true", "-f", "dshow", "-i", "dummy")
+	// 列出设备后 ffmpeg 因输入 dummy 无效而返回错误，设备列表在错误输出中
+	output, _ := cmd.CombinedOutput()
+	return parseDshowAudioDevices(string(output))
+}
@@ -0,0 +1,124 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// 采集格式: 16kHz 单声道 16位小端 PCM
+const (
+	SampleRate     = 16000
+	BytesPerSample = 2
+	FrameDuration  = 100 * time.Millisecond // 每帧计算一次音量
+	frameSamples   = SampleRate / 10
+	FrameBytes     = frameSamples * BytesPerSample
+	silenceDBFS    = -96.0 // 全零样本的音量下限
+)
+
+// Level 一帧的音量
+type Level struct {
+	Time   time.Time
+	DBFS   float64 // RMS 音量(dBFS)，0为满幅
+	Speech bool    // 是否判定为语音
+}
+
+// RMS 计算 16位 PCM 样本的均方根音量(dBFS)
+func RMS(pcm []byte) float64 {
+	n := len(pcm) / BytesPerSample
+	if n == 0 {
+		return silenceDBFS
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		sample := float64(int16(binary.LittleEndian.Uint16(pcm[i*BytesPerSample:]))) / 32768
+		sum += sample * sample
+	}
+	rms := math.Sqrt(sum / float64(n))
+	if rms == 0 {
+		return silenceDBFS
+	}
+	return max(20*math.Log10(rms), silenceDBFS)
+}
+
+// VADConfig 基于能量的语音检测参数
+type VADConfig struct {
+	Threshold    float64       // 绝对阈值(dBFS)，低于该值一律视为静音
+	NoiseMargin  float64       // 高于背景噪声多少 dB 才视为语音
+	Window       time.Duration // 统计语音占比的滑动窗口
+	SpeechRatio  float64       // 窗口内语音帧占比达到该值视为持续说话
+	MinSustained time.Duration // 持续说话达到该时长才触发事件
+}
+
+// DefaultVADConfig 默认语音检测参数
+var DefaultVADConfig = VADConfig{
+	Threshold:    -45,
+	NoiseMargin:  12,
+	Window:       3 * time.Second,
+	SpeechRatio:  0.5,
+	MinSustained: 5 * time.Second,
+}
+
+// noiseFloorSmoothing 背景噪声跟踪系数: 下降快、上升慢，避免说话声抬高噪声基线
+const (
+	noiseFloorFall = 0.3
+	noiseFloorRise = 0.01
+)
+
+// VAD 逐帧判定语音并检测持续说话
+type VAD struct {
+	config     VADConfig
+	noiseFloor float64
+	window     []bool // 最近 Window 时长内每帧是否为语音
+	frames     int
+	speechAt   time.Time // 本次持续说话的开始时间，零值表示未在说话
+	reported   bool      // 本次持续说话是否已触发事件
+}
+
+// NewVAD 创建语音检测器
+func NewVAD(config VADConfig) *VAD {
+	frames := max(int(config.Window/FrameDuration), 1)
+	return &VAD{config: config, noiseFloor: config.Threshold, frames: frames}
+}
+
+// Process 处理一帧音量，返回该帧是否为语音，以及持续说话刚达到 MinSustained 时的开始时间
+func (v *VAD) Process(dbfs float64, now time.Time) (speech bool, sustainedSince time.Time) {
+	speech = dbfs > v.config.Threshold && dbfs > v.noiseFloor+v.config.NoiseMargin
+	if !speech {
+		if dbfs < v.noiseFloor {
+			v.noiseFloor += (dbfs - v.noiseFloor) * noiseFloorFall
+		} else {
+			v.noiseFloor += (dbfs - v.noiseFloor) * noiseFloorRise
+		}
+	}
+
+	if len(v.window) == v.frames {
+		v.window = v.window[1:]
+	}
+	v.window = append(v.window, speech)
+
+	var count int
+	for _, s := range v.window {
+		if s {
+			count++
+		}
+	}
+	talking := len(v.window) == v.frames && float64(count)/float64(len(v.window)) >= v.config.SpeechRatio
+
+	switch {
+	case !talking:
+		v.speechAt = time.Time{}
+		v.reported = false
+	case v.speechAt.IsZero():
+		v.speechAt = now.Add(-time.Duration(count) * FrameDuration)
+	case !v.reported && now.Sub(v.speechAt) >= v.config.MinSustained:
+		v.reported = true
+		return speech, v.speechAt
+	}
+	return speech, time.Time{}
+}
+
+// Talking 当前是否处于持续说话状态
+func (v *VAD) Talking() bool {
+	return !v.speechAt.IsZero()
+}
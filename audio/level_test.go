@@ -0,0 +1,134 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// pcmFrame 生成一帧幅度恒定的方波，RMS 音量约为 20*log10(amplitude/32768)
+func pcmFrame(amplitude int16) []byte {
+	frame := make([]byte, FrameBytes)
+	for i := 0; i < frameSamples; i++ {
+		sample := amplitude
+		if i%2 == 1 {
+			sample = -amplitude
+		}
+		binary.LittleEndian.PutUint16(frame[i*BytesPerSample:], uint16(sample))
+	}
+	return frame
+}
+
+func TestRMS(t *testing.T) {
+	tests := []struct {
+		name string
+		pcm  []byte
+		want float64
+	}{
+		{"empty", nil, silenceDBFS},
+		{"half-sample", []byte{0x7f}, silenceDBFS},
+		{"zero", make([]byte, FrameBytes), silenceDBFS},
+		{"full-scale", pcmFrame(32767), 0},
+		{"half", pcmFrame(16384), -6.02},
+		{"quiet", pcmFrame(33), -59.9},
+		// 单个最小样本仍高于下限
+		{"floor", pcmFrame(1), -90.3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RMS(tt.pcm); math.Abs(got-tt.want) > 0.05 {
+				t.Errorf("RMS() = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+// 测试用检测参数: 1秒窗口(10帧)，持续 2 秒触发
+var testVADConfig = VADConfig{
+	Threshold:    -45,
+	NoiseMargin:  12,
+	Window:       time.Second,
+	SpeechRatio:  0.5,
+	MinSustained: 2 * time.Second,
+}
+
+type vadSegment struct {
+	dbfs   float64
+	frames int
+}
+
+// runVAD 按帧处理音量序列，返回触发事件的帧序号和开始时间
+func runVAD(config VADConfig, start time.Time, segments []vadSegment) (frames []int, since []time.Time) {
+	vad := NewVAD(config)
+	var i int
+	for _, segment := range segments {
+		for n := 0; n < segment.frames; n++ {
+			now := start.Add(time.Duration(i) * FrameDuration)
+			if _, s := vad.Process(segment.dbfs, now); !s.IsZero() {
+				frames = append(frames, i)
+				since = append(since, s)
+			}
+			i++
+		}
+	}
+	return frames, since
+}
+
+func TestVAD(t *testing.T) {
+	const (
+		silence = -70.0
+		speech  = -20.0
+	)
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		segments []vadSegment
+		events   int
+	}{
+		{"silence", []vadSegment{{silence, 100}}, 0},
+		{"short", []vadSegment{{silence, 20}, {speech, 10}, {silence, 20}}, 0},
+		{"sustained", []vadSegment{{silence, 20}, {speech, 30}, {silence, 20}}, 1},
+		// 说话中的短暂停顿在窗口占比之内，不会重新计时
+		{"hangover", []vadSegment{{silence, 20}, {speech, 30}, {silence, 3}, {speech, 30}, {silence, 20}}, 1},
+		// 停顿超过窗口后重新开始计时，再次持续说话时触发新的事件
+		{"two", []vadSegment{{silence, 20}, {speech, 30}, {silence, 20}, {speech, 30}}, 2},
+		// 低于绝对阈值的声音即使高于背景噪声也不是语音
+		{"below-threshold", []vadSegment{{-90, 20}, {-50, 50}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, since := runVAD(testVADConfig, start, tt.segments)
+			if len(frames) != tt.events {
+				t.Fatalf("触发 %d 次 (帧 %v), want %d", len(frames), frames, tt.events)
+			}
+			for i, frame := range frames {
+				detected := start.Add(time.Duration(frame) * FrameDuration)
+				if d := detected.Sub(since[i]); d < testVADConfig.MinSustained || d > testVADConfig.MinSustained+FrameDuration {
+					t.Errorf("事件 %d 开始于触发前 %v, want %v", i, d, testVADConfig.MinSustained)
+				}
+			}
+		})
+	}
+
+	// 开始时间为第一帧语音
+	_, since := runVAD(testVADConfig, start, []vadSegment{{silence, 20}, {speech, 30}})
+	if speechStart := start.Add(20 * FrameDuration); since[0].Sub(speechStart).Abs() > FrameDuration {
+		t.Errorf("开始时间 = %v, want %v", since[0], speechStart)
+	}
+}
+
+// 背景噪声升高后，只有明显高于噪声的声音才视为语音
+func TestVADNoiseFloor(t *testing.T) {
+	vad := NewVAD(testVADConfig)
+	now := time.Now()
+	for i := 0; i < 600; i++ {
+		vad.Process(-40, now)
+	}
+	if speech, _ := vad.Process(-35, now); speech {
+		t.Error("接近背景噪声的声音不应视为语音")
+	}
+	if speech, _ := vad.Process(-20, now); !speech {
+		t.Error("明显高于背景噪声的声音应视为语音")
+	}
+}
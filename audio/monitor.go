@@ -0,0 +1,283 @@
+package audio
+
+import (
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrNoMicrophone 没有可用的麦克风
+var ErrNoMicrophone = errors.New("没有检测到可用的麦克风")
+
+const (
+	DefaultSummaryInterval = time.Minute      // 音量统计上报间隔
+	DefaultClipPreRoll     = 3 * time.Second  // 片段保留事件前的时长
+	DefaultClipPostRoll    = 5 * time.Second  // 片段保留事件后的时长
+	RetryDelay             = 10 * time.Second // 采集中断后重新打开设备的间隔
+)
+
+// SpeechEvent 检测到持续说话
+type SpeechEvent struct {
+	Start    time.Time // 开始说话的时间
+	Detected time.Time // 判定为持续说话的时间
+	PeakDBFS float64   // 说话期间的最大音量
+	Clip     []byte    // 事件前后的 WAV 片段，未开启片段保留时为空
+}
+
+// Summary 一个统计窗口内的音量概况，只包含统计值不包含音频
+type Summary struct {
+	Start       time.Time
+	End         time.Time
+	AvgDBFS     float64
+	PeakDBFS    float64
+	SpeechRatio float64 // 语音帧占比
+}
+
+// SpeechCallback 持续说话回调
+type SpeechCallback func(event SpeechEvent)
+
+// SummaryCallback 音量统计回调
+type SummaryCallback func(summary Summary)
+
+// StatusCallback 麦克风可用状态变化回调，err 为不可用的原因
+type StatusCallback func(available bool, err error)
+
+// Monitor 持续读取麦克风计算音量，检测到持续说话时回调
+// 默认不保留任何音频，开启 ClipEnabled 后只保留事件前后的短片段
+type Monitor struct {
+	Source          Source
+	VAD             VADConfig
+	SummaryInterval time.Duration
+	ClipEnabled     bool
+	ClipPreRoll     time.Duration
+	ClipPostRoll    time.Duration
+	OnSpeech        SpeechCallback
+	OnSummary       SummaryCallback
+	OnStatus        StatusCallback
+
+	mu        sync.Mutex
+	stop      chan struct{}
+	stream    io.ReadCloser
+	available bool
+}
+
+// NewMonitor 创建麦克风监控，source 为空时只会上报麦克风缺失
+func NewMonitor(source Source) *Monitor {
+	return &Monitor{
+		Source:          source,
+		VAD:             DefaultVADConfig,
+		SummaryInterval: DefaultSummaryInterval,
+		ClipPreRoll:     DefaultClipPreRoll,
+		ClipPostRoll:    DefaultClipPostRoll,
+		available:       true,
+	}
+}
+
+// Start 开始监控
+func (m *Monitor) Start() {
+	m.mu.Lock()
+	if m.stop != nil {
+		m.mu.Unlock()
+		return
+	}
+	m.stop = make(chan struct{})
+	stop := m.stop
+	m.mu.Unlock()
+
+	go m.run(stop)
+}
+
+// Stop 停止监控并结束采集进程
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+	if m.stream != nil {
+		_ = m.stream.Close()
+		m.stream = nil
+	}
+}
+
+// run 采集中断后间隔 RetryDelay 重新打开设备
+func (m *Monitor) run(stop chan struct{}) {
+	for {
+		err := m.listen(stop)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		log.Printf("麦克风监控中断: %v", err)
+		m.setAvailable(false, err)
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(RetryDelay):
+		}
+	}
+}
+
+// listen 打开一次采集并逐帧处理，直到读取出错
+func (m *Monitor) listen(stop chan struct{}) error {
+	if m.Source == nil {
+		return ErrNoMicrophone
+	}
+	stream, err := m.Source.Open()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if m.stop != stop {
+		m.mu.Unlock()
+		_ = stream.Close()
+		return nil
+	}
+	m.stream = stream
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		if m.stream == stream {
+			m.stream = nil
+		}
+		m.mu.Unlock()
+		_ = stream.Close()
+	}()
+
+	state := newListenState(m)
+	frame := make([]byte, FrameBytes)
+	for {
+		if _, err = io.ReadFull(stream, frame); err != nil {
+			return err
+		}
+		m.setAvailable(true, nil)
+		state.process(frame, time.Now())
+	}
+}
+
+// listenState 单次采集的处理状态
+type listenState struct {
+	m   *Monitor
+	vad *VAD
+
+	// 事件前的音频环形缓冲，以及等待补齐事件后音频的片段
+	preRoll     [][]byte
+	pending     *SpeechEvent
+	pendingPCM  []byte
+	pendingLeft int
+
+	// 当前统计窗口
+	summaryStart time.Time
+	frames       int
+	speechFrames int
+	energySum    float64
+	peak         float64
+	speechPeak   float64
+}
+
+func newListenState(m *Monitor) *listenState {
+	return &listenState{m: m, vad: NewVAD(m.VAD), peak: silenceDBFS, speechPeak: silenceDBFS}
+}
+
+func (s *listenState) process(frame []byte, now time.Time) {
+	dbfs := RMS(frame)
+	speech, since := s.vad.Process(dbfs, now)
+
+	s.summarize(dbfs, speech, now)
+	if speech {
+		s.speechPeak = max(s.speechPeak, dbfs)
+	} else if !s.vad.Talking() {
+		s.speechPeak = silenceDBFS
+	}
+
+	if s.m.ClipEnabled {
+		s.buffer(frame)
+	}
+	if !since.IsZero() {
+		event := SpeechEvent{Start: since, Detected: now, PeakDBFS: s.speechPeak}
+		if s.m.ClipEnabled && s.pending == nil {
+			s.pending = &event
+			for _, f := range s.preRoll {
+				s.pendingPCM = append(s.pendingPCM, f...)
+			}
+			s.pendingLeft = int(s.m.ClipPostRoll / FrameDuration)
+			return
+		}
+		s.emit(event)
+	}
+}
+
+// buffer 维护事件前的环形缓冲，并为等待中的片段补齐事件后的音频
+func (s *listenState) buffer(frame []byte) {
+	if s.pending != nil {
+		s.pendingPCM = append(s.pendingPCM, frame...)
+		s.pendingLeft--
+		if s.pendingLeft <= 0 {
+			event := *s.pending
+			event.Clip = EncodeWAV(s.pendingPCM)
+			s.pending, s.pendingPCM = nil, nil
+			s.emit(event)
+		}
+	}
+
+	size := int(s.m.ClipPreRoll / FrameDuration)
+	if size <= 0 {
+		return
+	}
+	if len(s.preRoll) == size {
+		s.preRoll = s.preRoll[1:]
+	}
+	s.preRoll = append(s.preRoll, append([]byte(nil), frame...))
+}
+
+// summarize 累计统计窗口，窗口结束时回调
+func (s *listenState) summarize(dbfs float64, speech bool, now time.Time) {
+	if s.summaryStart.IsZero() {
+		s.summaryStart = now
+	}
+	s.frames++
+	if speech {
+		s.speechFrames++
+	}
+	s.energySum += dbfs
+	s.peak = max(s.peak, dbfs)
+
+	if now.Sub(s.summaryStart) < s.m.SummaryInterval {
+		return
+	}
+	if s.m.OnSummary != nil {
+		s.m.OnSummary(Summary{
+			Start:       s.summaryStart,
+			End:         now,
+			AvgDBFS:     s.energySum / float64(s.frames),
+			PeakDBFS:    s.peak,
+			SpeechRatio: float64(s.speechFrames) / float64(s.frames),
+		})
+	}
+	s.summaryStart = now
+	s.frames, s.speechFrames, s.energySum, s.peak = 0, 0, 0, silenceDBFS
+}
+
+func (s *listenState) emit(event SpeechEvent) {
+	if s.m.OnSpeech != nil {
+		s.m.OnSpeech(event)
+	}
+}
+
+// setAvailable 只在状态变化时回调
+func (m *Monitor) setAvailable(available bool, err error) {
+	m.mu.Lock()
+	changed := m.available != available
+	m.available = available
+	m.mu.Unlock()
+
+	if changed && m.OnStatus != nil {
+		m.OnStatus(available, err)
+	}
+}
@@ -0,0 +1,171 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+)
+
+// 测试帧的幅度: 静音帧按序号区分，语音帧在序号基础上加大幅度
+func testFrameAmplitude(i int, speech bool) int16 {
+	if speech {
+		return int16(8000 + i)
+	}
+	return int16(1 + i%20)
+}
+
+// clipFrames 从 WAV 片段中按每帧第一个样本还原帧的幅度
+func clipFrames(t *testing.T, clip []byte) []int16 {
+	t.Helper()
+	pcm := clip[44:]
+	if len(pcm)%FrameBytes != 0 {
+		t.Fatalf("片段长度 %d 不是整帧", len(pcm))
+	}
+	var amplitudes []int16
+	for i := 0; i < len(pcm); i += FrameBytes {
+		amplitudes = append(amplitudes, int16(binary.LittleEndian.Uint16(pcm[i:])))
+	}
+	return amplitudes
+}
+
+func TestListenStateClip(t *testing.T) {
+	m := NewMonitor(nil)
+	m.VAD = testVADConfig
+	m.ClipEnabled = true
+	m.ClipPreRoll = 300 * time.Millisecond
+	m.ClipPostRoll = 500 * time.Millisecond
+	var events []SpeechEvent
+	m.OnSpeech = func(event SpeechEvent) {
+		events = append(events, event)
+	}
+
+	state := newListenState(m)
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	detectedFrame := -1
+	for i := 0; i < 80; i++ {
+		speech := i >= 20 && i < 60
+		state.process(pcmFrame(testFrameAmplitude(i, speech)), start.Add(time.Duration(i)*FrameDuration))
+		if detectedFrame < 0 && state.pending != nil {
+			detectedFrame = i
+		}
+	}
+
+	if len(events) != 1 || detectedFrame < 0 {
+		t.Fatalf("events = %d, detected at %d", len(events), detectedFrame)
+	}
+	event := events[0]
+	if event.Detected != start.Add(time.Duration(detectedFrame)*FrameDuration) {
+		t.Errorf("Detected = %v, want frame %d", event.Detected, detectedFrame)
+	}
+	if event.PeakDBFS < -13 || event.PeakDBFS > -12 {
+		t.Errorf("PeakDBFS = %.2f", event.PeakDBFS)
+	}
+
+	// 片段为触发帧及之前共 3 帧，加上之后的 5 帧
+	var want []int16
+	for i := detectedFrame - 2; i <= detectedFrame+5; i++ {
+		want = append(want, testFrameAmplitude(i, i >= 20 && i < 60))
+	}
+	got := clipFrames(t, event.Clip)
+	if len(got) != len(want) {
+		t.Fatalf("片段帧数 = %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("片段第 %d 帧幅度 = %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+func TestListenStateWithoutClip(t *testing.T) {
+	m := NewMonitor(nil)
+	m.VAD = testVADConfig
+	m.SummaryInterval = time.Second
+	var (
+		events    []SpeechEvent
+		summaries []Summary
+	)
+	m.OnSpeech = func(event SpeechEvent) {
+		events = append(events, event)
+	}
+	m.OnSummary = func(summary Summary) {
+		summaries = append(summaries, summary)
+	}
+
+	state := newListenState(m)
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 60; i++ {
+		state.process(pcmFrame(testFrameAmplitude(i, i >= 20)), start.Add(time.Duration(i)*FrameDuration))
+	}
+
+	if len(events) != 1 || events[0].Clip != nil {
+		t.Fatalf("events = %+v", events)
+	}
+	// 60 帧覆盖 5.9 秒，每秒统计一次
+	if len(summaries) != 5 {
+		t.Fatalf("summaries = %d, want 5", len(summaries))
+	}
+	if first := summaries[0]; first.SpeechRatio != 0 || first.PeakDBFS > -60 {
+		t.Errorf("静音窗口统计 = %+v", first)
+	}
+	if last := summaries[len(summaries)-1]; last.SpeechRatio != 1 || last.AvgDBFS < -13 {
+		t.Errorf("说话窗口统计 = %+v", last)
+	}
+}
+
+// pcmSource 返回预先生成的 PCM 数据，读完后以 EOF 结束
+type pcmSource struct {
+	pcm []byte
+}
+
+func (s *pcmSource) Open() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.pcm)), nil
+}
+
+func (s *pcmSource) Name() string {
+	return "test"
+}
+
+// 采集进程退出时上报麦克风不可用并附带原因
+func TestMonitorStreamEnded(t *testing.T) {
+	m := NewMonitor(&pcmSource{pcm: pcmFrame(testFrameAmplitude(0, true))})
+	errs := make(chan error, 1)
+	m.OnStatus = func(available bool, err error) {
+		if available {
+			t.Error("麦克风初始为可用，读取成功时不应回调")
+		}
+		errs <- err
+	}
+	m.Start()
+	defer m.Stop()
+
+	select {
+	case err := <-errs:
+		if err != io.EOF {
+			t.Errorf("err = %v, want EOF", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("等待采集结束超时")
+	}
+}
+
+func TestMonitorNoMicrophone(t *testing.T) {
+	m := NewMonitor(nil)
+	errs := make(chan error, 1)
+	m.OnStatus = func(available bool, err error) {
+		errs <- err
+	}
+	m.Start()
+	defer m.Stop()
+
+	select {
+	case err := <-errs:
+		if err != ErrNoMicrophone {
+			t.Errorf("err = %v, want ErrNoMicrophone", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有上报麦克风缺失")
+	}
+}
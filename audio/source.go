@@ -0,0 +1,93 @@
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"sync"
+
+	"monitor-desktop-client/ffmpeg"
+)
+
+// Source 麦克风 PCM 数据来源，Open 返回 16kHz 单声道 16位小端 PCM 流
+type Source interface {
+	Open() (io.ReadCloser, error)
+	Name() string
+}
+
+// FFmpegSource 通过系统 ffmpeg 读取默认输入设备
+type FFmpegSource struct {
+	Backend string // pulse/alsa/dshow，为空时按平台自动选择
+	Device  string // 为空时使用默认设备，Windows 下为枚举到的第一个音频输入设备
+}
+
+func (s *FFmpegSource) Name() string {
+	if s.Device == "" {
+		return "default"
+	}
+	return s.Device
+}
+
+// Open 启动 ffmpeg 并返回其标准输出，Close 时结束进程
+func (s *FFmpegSource) Open() (io.ReadCloser, error) {
+	input, err := microphoneInput(s.Backend, s.Device)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"-hide_banner", "-loglevel", "error", "-nostdin"}
+	args = append(args, input...)
+	args = append(args,
+		"-ac", "1",
+		"-ar", strconv.Itoa(SampleRate),
+		"-f", "s16le",
+		"pipe:1",
+	)
+	cmd := exec.Command(ffmpeg.Path(), args...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动 ffmpeg 失败: %w", err)
+	}
+	return &ffmpegStream{ReadCloser: stdout, cmd: cmd, stderr: stderr}, nil
+}
+
+type ffmpegStream struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+
+	waitOnce sync.Once
+	waitErr  error
+}
+
+// wait 等待进程退出，Read 和 Close 可能在不同协程中调用，cmd.Wait 只能执行一次
+func (s *ffmpegStream) wait() error {
+	s.waitOnce.Do(func() {
+		s.waitErr = s.cmd.Wait()
+	})
+	return s.waitErr
+}
+
+// Read 进程退出导致读取结束时，附带 ffmpeg 的错误输出便于排查设备问题
+func (s *ffmpegStream) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	if err == io.EOF {
+		if waitErr := s.wait(); waitErr != nil {
+			return n, fmt.Errorf("麦克风采集中断: %v %s", waitErr, bytes.TrimSpace(s.stderr.Bytes()))
+		}
+	}
+	return n, err
+}
+
+// Close 结束进程并等待退出，进程已退出时 Kill 不产生影响
+func (s *ffmpegStream) Close() error {
+	_ = s.cmd.Process.Kill()
+	_ = s.wait()
+	return nil
+}
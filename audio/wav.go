@@ -0,0 +1,30 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// EncodeWAV 将 16kHz 单声道 16位 PCM 封装为 WAV
+func EncodeWAV(pcm []byte) []byte {
+	var buffer bytes.Buffer
+	buffer.Grow(44 + len(pcm))
+
+	buffer.WriteString("RIFF")
+	_ = binary.Write(&buffer, binary.LittleEndian, uint32(36+len(pcm)))
+	buffer.WriteString("WAVE")
+
+	buffer.WriteString("fmt ")
+	_ = binary.Write(&buffer, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&buffer, binary.LittleEndian, uint16(1)) // PCM
+	_ = binary.Write(&buffer, binary.LittleEndian, uint16(1)) // 单声道
+	_ = binary.Write(&buffer, binary.LittleEndian, uint32(SampleRate))
+	_ = binary.Write(&buffer, binary.LittleEndian, uint32(SampleRate*BytesPerSample))
+	_ = binary.Write(&buffer, binary.LittleEndian, uint16(BytesPerSample))
+	_ = binary.Write(&buffer, binary.LittleEndian, uint16(8*BytesPerSample))
+
+	buffer.WriteString("data")
+	_ = binary.Write(&buffer, binary.LittleEndian, uint32(len(pcm)))
+	buffer.Write(pcm)
+	return buffer.Bytes()
+}
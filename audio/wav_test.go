@@ -0,0 +1,52 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestEncodeWAV(t *testing.T) {
+	tests := []struct {
+		name string
+		pcm  []byte
+	}{
+		{"empty", nil},
+		{"one-frame", bytes.Repeat([]byte{0x34, 0x12}, frameSamples)},
+		{"odd", []byte{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wav := EncodeWAV(tt.pcm)
+			if len(wav) != 44+len(tt.pcm) {
+				t.Fatalf("len = %d, want %d", len(wav), 44+len(tt.pcm))
+			}
+			le := binary.LittleEndian
+			fields := []struct {
+				name      string
+				got, want uint32
+			}{
+				{"RIFF size", le.Uint32(wav[4:]), uint32(36 + len(tt.pcm))},
+				{"fmt size", le.Uint32(wav[16:]), 16},
+				{"format", uint32(le.Uint16(wav[20:])), 1},
+				{"channels", uint32(le.Uint16(wav[22:])), 1},
+				{"sample rate", le.Uint32(wav[24:]), SampleRate},
+				{"byte rate", le.Uint32(wav[28:]), SampleRate * BytesPerSample},
+				{"block align", uint32(le.Uint16(wav[32:])), BytesPerSample},
+				{"bits", uint32(le.Uint16(wav[34:])), 16},
+				{"data size", le.Uint32(wav[40:]), uint32(len(tt.pcm))},
+			}
+			for _, f := range fields {
+				if f.got != f.want {
+					t.Errorf("%s = %d, want %d", f.name, f.got, f.want)
+				}
+			}
+			if string(wav[0:4]) != "RIFF" || string(wav[8:16]) != "WAVEfmt " || string(wav[36:40]) != "data" {
+				t.Errorf("chunk ids = %q %q %q", wav[0:4], wav[8:16], wav[36:40])
+			}
+			if !bytes.Equal(wav[44:], tt.pcm) {
+				t.Error("data 与原始 PCM 不一致")
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package camera
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os/exec"
+	"sync"
+	"time"
+
+	"monitor-desktop-client/ffmpeg"
+)
+
+// ErrNoCamera 没有检测到摄像头
+var ErrNoCamera = errors.New("没有检测到可用的摄像头")
+
+// ErrUnsupported 当前平台不支持摄像头采集，此时不应启动抓拍也不应上报摄像头缺失
+var ErrUnsupported = errors.New("当前平台暂不支持摄像头采集")
+
+// Device 摄像头设备
+type Device struct {
+	Path string // 设备路径，例如 /dev/video0
+	Name string // 设备名称
+}
+
+// Source 摄像头画面来源，Snapshot 返回一帧 JPEG 图像
+type Source interface {
+	Snapshot() ([]byte, error)
+	Name() string
+}
+
+// SnapshotTimeout 单次拍照超时，摄像头被拔出或被占用时 ffmpeg 可能一直阻塞
+const SnapshotTimeout = 10 * time.Second
+
+// FFmpegSource 通过系统 ffmpeg 从摄像头抓取单帧
+type FFmpegSource struct {
+	Device Device
+}
+
+// Open 打开第一个可用的摄像头
+func Open() (*FFmpegSource, error) {
+	devices, err := Devices()
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, ErrNoCamera
+	}
+	return &FFmpegSource{Device: devices[0]}, nil
+}
+
+func (s *FFmpegSource) Name() string {
+	return s.Device.Name
+}
+
+// Snapshot 抓取一帧并编码为 JPEG
+func (s *FFmpegSource) Snapshot() ([]byte, error) {
+	args := append(cameraInput(s.Device),
+		"-frames:v", "1",
+		"-f", "image2",
+		"-c:v", "mjpeg",
+		"-q:v", "5",
+		"pipe:1",
+	)
+	cmd := exec.Command(ffmpeg.Path(), args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动 ffmpeg 失败: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, fmt.Errorf("摄像头 %s 拍照失败: %w", s.Device.Path, err)
+		}
+	case <-time.After(SnapshotTimeout):
+		_ = cmd.Process.Kill()
+		<-done
+		return nil, fmt.Errorf("摄像头 %s 拍照超时", s.Device.Path)
+	}
+
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("摄像头 %s 未返回图像", s.Device.Path)
+	}
+	return stdout.Bytes(), nil
+}
+
+// FakeSource 生成合成画面的摄像头，用于没有摄像头和 v4l2loopback 的测试环境
+type FakeSource struct {
+	Width  int
+	Height int
+
+	mu          sync.Mutex
+	frame       int
+	unavailable bool
+}
+
+// NewFakeSource 创建合成画面摄像头
+func NewFakeSource(width, height int) *FakeSource {
+	return &FakeSource{Width: width, Height: height}
+}
+
+func (s *FakeSource) Name() string {
+	return "fake"
+}
+
+// SetAvailable 模拟摄像头拔出和重新接入
+func (s *FakeSource) SetAvailable(available bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unavailable = !available
+}
+
+// Snapshot 生成一帧随帧号变化的渐变画面
+func (s *FakeSource) Snapshot() ([]byte, error) {
+	s.mu.Lock()
+	if s.unavailable {
+		s.mu.Unlock()
+		return nil, ErrNoCamera
+	}
+	s.frame++
+	frame := s.frame
+	s.mu.Unlock()
+
+	img := image.NewRGBA(image.Rect(0, 0, s.Width, s.Height))
+	for y := 0; y < s.Height; y++ {
+		for x := 0; x < s.Width; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x + frame*8), G: uint8(y), B: uint8(frame * 16), A: 255})
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := jpeg.Encode(&buffer, img, nil); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,48 @@
+package camera
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sysVideo4Linux V4L2 设备信息目录
+const sysVideo4Linux = "/sys/class/video4linux"
+
+// Devices 枚举 /dev/video* 中的视频采集设备
+// 一个摄像头通常对应多个节点，只保留 index 为0的主采集节点
+func Devices() ([]Device, error) {
+	matches, err := filepath.Glob("/dev/video*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+
+	var devices []Device
+	for _, path := range matches {
+		sys := filepath.Join(sysVideo4Linux, filepath.Base(path))
+		if index := readSysfs(filepath.Join(sys, "index")); index != "" && index != "0" {
+			continue
+		}
+		name := readSysfs(filepath.Join(sys, "name"))
+		if name == "" {
+			name = filepath.Base(path)
+		}
+		devices = append(devices, Device{Path: path, Name: name})
+	}
+	return devices, nil
+}
+
+// cameraInput 通过 v4l2 采集摄像头
+func cameraInput(device Device) []string {
+	return []string{"-f", "v4l2", "-i", device.Path}
+}
+
+func readSysfs(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package camera
+
+// Devices 目前只支持枚举 Linux 的 V4L2 摄像头
+func Devices() ([]Device, error) {
+	return nil, ErrUnsupported
+}
+
+func cameraInput(device Device) []string {
+	return []string{"-i", device.Path}
+}
@@ -0,0 +1,146 @@
+package camera
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// 照片类型
+const (
+	PhotoIdentity = "identity" // 登录时的身份照片
+	PhotoPeriodic = "periodic" // 考试中的定时抓拍
+)
+
+// DefaultSnapshotInterval 默认定时抓拍间隔
+const DefaultSnapshotInterval = 2 * time.Minute
+
+// PhotoCallback 拍照成功回调
+type PhotoCallback func(kind string, data []byte)
+
+// StatusCallback 摄像头可用状态变化回调，err 为不可用的原因
+type StatusCallback func(available bool, err error)
+
+// OpenFunc 打开摄像头
+type OpenFunc func() (Source, error)
+
+// Monitor 登录时拍摄身份照片，之后定时抓拍，并在摄像头不可用或恢复时通知
+type Monitor struct {
+	Source   Source   // 为空时表示没有检测到摄像头，Start 之后只在抓拍协程中读写
+	Open     OpenFunc // 不为空时，没有摄像头或上次拍照失败后在每次抓拍前重新打开
+	Interval time.Duration
+	OnPhoto  PhotoCallback
+	OnStatus StatusCallback
+
+	mu        sync.Mutex
+	stop      chan struct{}
+	available bool
+}
+
+// NewMonitor 创建摄像头监控，source 为空时只会上报摄像头缺失
+func NewMonitor(source Source, interval time.Duration) *Monitor {
+	if interval <= 0 {
+		interval = DefaultSnapshotInterval
+	}
+	return &Monitor{Source: source, Interval: interval, available: true}
+}
+
+// Start 拍摄身份照片并开始定时抓拍
+func (m *Monitor) Start() {
+	m.mu.Lock()
+	if m.stop != nil {
+		m.mu.Unlock()
+		return
+	}
+	m.stop = make(chan struct{})
+	stop := m.stop
+	m.mu.Unlock()
+
+	go m.run(stop)
+}
+
+// Stop 停止定时抓拍
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+// run 身份照片拍摄失败时，之后的第一张成功照片仍作为身份照片
+func (m *Monitor) run(stop chan struct{}) {
+	identityTaken := m.capture(PhotoIdentity)
+
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if identityTaken {
+				m.capture(PhotoPeriodic)
+			} else {
+				identityTaken = m.capture(PhotoIdentity)
+			}
+		}
+	}
+}
+
+// capture 拍照并处理可用状态变化，返回是否拍照成功
+func (m *Monitor) capture(kind string) bool {
+	if err := m.reopen(); m.Source == nil {
+		if err == nil {
+			err = ErrNoCamera
+		}
+		m.setAvailable(false, err)
+		return false
+	}
+
+	data, err := m.Source.Snapshot()
+	if err != nil {
+		log.Printf("摄像头拍照失败: %v", err)
+		m.setAvailable(false, err)
+		return false
+	}
+	m.setAvailable(true, nil)
+
+	if m.OnPhoto != nil {
+		m.OnPhoto(kind, data)
+	}
+	return true
+}
+
+// reopen 没有摄像头或上次拍照失败时重新打开，摄像头重新接入后设备路径可能变化
+// 打开失败时保留原来的摄像头，返回打开失败的原因
+func (m *Monitor) reopen() error {
+	if m.Open == nil || (m.Source != nil && m.isAvailable()) {
+		return nil
+	}
+	source, err := m.Open()
+	if err != nil {
+		return err
+	}
+	m.Source = source
+	return nil
+}
+
+func (m *Monitor) isAvailable() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.available
+}
+
+// setAvailable 只在状态变化时回调，避免每次抓拍失败都重复上报
+func (m *Monitor) setAvailable(available bool, err error) {
+	m.mu.Lock()
+	changed := m.available != available
+	m.available = available
+	m.mu.Unlock()
+
+	if changed && m.OnStatus != nil {
+		m.OnStatus(available, err)
+	}
+}
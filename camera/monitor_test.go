@@ -0,0 +1,221 @@
+package camera
+
+import (
+	"bytes"
+	"errors"
+	"image/jpeg"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testInterval = 20 * time.Millisecond
+
+// recorder 记录监控回调，回调在抓拍协程中执行
+type recorder struct {
+	mu       sync.Mutex
+	photos   []string
+	statuses []bool
+	errs     []error
+}
+
+func newTestMonitor(source Source) (*Monitor, *recorder) {
+	rec := &recorder{}
+	m := NewMonitor(source, testInterval)
+	m.OnPhoto = func(kind string, data []byte) {
+		if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+			panic(err)
+		}
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.photos = append(rec.photos, kind)
+	}
+	m.OnStatus = func(available bool, err error) {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.statuses = append(rec.statuses, available)
+		rec.errs = append(rec.errs, err)
+	}
+	return m, rec
+}
+
+func (r *recorder) snapshot() (photos []string, statuses []bool, errs []error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.photos...), append([]bool(nil), r.statuses...), append([]error(nil), r.errs...)
+}
+
+// waitFor 等待条件成立，超时后测试失败
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待超时: %s", what)
+		}
+		time.Sleep(testInterval / 2)
+	}
+}
+
+func TestMonitorPhotos(t *testing.T) {
+	m, rec := newTestMonitor(NewFakeSource(64, 48))
+	m.Start()
+	defer m.Stop()
+
+	waitFor(t, "定时抓拍", func() bool {
+		photos, _, _ := rec.snapshot()
+		return len(photos) >= 3
+	})
+	photos, statuses, _ := rec.snapshot()
+	if photos[0] != PhotoIdentity {
+		t.Errorf("第一张照片 = %s, want %s", photos[0], PhotoIdentity)
+	}
+	for _, kind := range photos[1:] {
+		if kind != PhotoPeriodic {
+			t.Errorf("之后的照片 = %s, want %s", kind, PhotoPeriodic)
+		}
+	}
+	if len(statuses) != 0 {
+		t.Errorf("摄像头一直可用时不应回调状态: %v", statuses)
+	}
+}
+
+func TestMonitorUnplugged(t *testing.T) {
+	source := NewFakeSource(64, 48)
+	m, rec := newTestMonitor(source)
+	m.Start()
+	defer m.Stop()
+
+	waitFor(t, "身份照片", func() bool {
+		photos, _, _ := rec.snapshot()
+		return len(photos) >= 1
+	})
+	source.SetAvailable(false)
+	waitFor(t, "摄像头不可用", func() bool {
+		_, statuses, _ := rec.snapshot()
+		return len(statuses) >= 1
+	})
+	// 多次失败只上报一次
+	time.Sleep(testInterval * 3)
+	source.SetAvailable(true)
+	waitFor(t, "摄像头恢复", func() bool {
+		_, statuses, _ := rec.snapshot()
+		return len(statuses) >= 2
+	})
+
+	_, statuses, errs := rec.snapshot()
+	if len(statuses) != 2 || statuses[0] || !statuses[1] {
+		t.Errorf("statuses = %v, want [false true]", statuses)
+	}
+	if !errors.Is(errs[0], ErrNoCamera) || errs[1] != nil {
+		t.Errorf("errs = %v", errs)
+	}
+}
+
+func TestMonitorNoCamera(t *testing.T) {
+	m, rec := newTestMonitor(nil)
+	m.Start()
+	defer m.Stop()
+
+	waitFor(t, "上报摄像头缺失", func() bool {
+		_, statuses, _ := rec.snapshot()
+		return len(statuses) >= 1
+	})
+	time.Sleep(testInterval * 3)
+	photos, statuses, errs := rec.snapshot()
+	if len(photos) != 0 || len(statuses) != 1 || statuses[0] || errs[0] != ErrNoCamera {
+		t.Errorf("photos = %v, statuses = %v, errs = %v", photos, statuses, errs)
+	}
+}
+
+// 登录时没有摄像头，之后接入的摄像头应被打开，第一张照片作为身份照片
+func TestMonitorOpenLater(t *testing.T) {
+	openErr := errors.New("摄像头未接入")
+	var (
+		mu      sync.Mutex
+		attempt int
+	)
+	m, rec := newTestMonitor(nil)
+	m.Open = func() (Source, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempt++
+		if attempt < 3 {
+			return nil, openErr
+		}
+		return NewFakeSource(64, 48), nil
+	}
+	m.Start()
+	defer m.Stop()
+
+	waitFor(t, "打开摄像头后抓拍", func() bool {
+		photos, _, _ := rec.snapshot()
+		return len(photos) >= 2
+	})
+	photos, statuses, errs := rec.snapshot()
+	if photos[0] != PhotoIdentity || photos[1] != PhotoPeriodic {
+		t.Errorf("photos = %v", photos)
+	}
+	if len(statuses) != 2 || statuses[0] || !statuses[1] || errs[0] != openErr {
+		t.Errorf("statuses = %v, errs = %v", statuses, errs)
+	}
+
+	// 打开成功后不再重复打开
+	mu.Lock()
+	opened := attempt
+	mu.Unlock()
+	time.Sleep(testInterval * 3)
+	mu.Lock()
+	defer mu.Unlock()
+	if attempt != opened {
+		t.Errorf("摄像头可用时仍在重新打开: %d -> %d", opened, attempt)
+	}
+}
+
+// 摄像头失效后重新打开，换成新的设备继续抓拍
+func TestMonitorReopenFailing(t *testing.T) {
+	broken := NewFakeSource(64, 48)
+	broken.SetAvailable(false)
+	replacement := NewFakeSource(32, 24)
+
+	m, rec := newTestMonitor(broken)
+	var opens int
+	m.Open = func() (Source, error) {
+		opens++
+		if opens < 2 {
+			return nil, ErrNoCamera
+		}
+		return replacement, nil
+	}
+	m.Start()
+	defer m.Stop()
+
+	waitFor(t, "换用新摄像头", func() bool {
+		photos, _, _ := rec.snapshot()
+		return len(photos) >= 1
+	})
+	_, statuses, _ := rec.snapshot()
+	if len(statuses) != 2 || statuses[0] || !statuses[1] {
+		t.Errorf("statuses = %v, want [false true]", statuses)
+	}
+}
+
+func TestMonitorStop(t *testing.T) {
+	m, rec := newTestMonitor(NewFakeSource(64, 48))
+	m.Start()
+	m.Start()
+	waitFor(t, "身份照片", func() bool {
+		photos, _, _ := rec.snapshot()
+		return len(photos) >= 1
+	})
+	m.Stop()
+	m.Stop()
+
+	// 停止前正在进行的抓拍可能还会完成一次
+	time.Sleep(testInterval * 2)
+	photos, _, _ := rec.snapshot()
+	time.Sleep(testInterval * 3)
+	if after, _, _ := rec.snapshot(); len(after) != len(photos) {
+		t.Errorf("停止后仍在抓拍: %d -> %d", len(photos), len(after))
+	}
+}
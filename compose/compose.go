@@ -3,27 +3,35 @@ package compose
 import (
 	"bytes"
 	"fmt"
-	"image/jpeg"
 	"log"
 	"monitor-desktop-client/devices"
 	"monitor-desktop-client/foreground"
 	"monitor-desktop-client/netcap"
 	"monitor-desktop-client/screencap"
 	"monitor-desktop-client/utils"
-	"strings"
-	"syscall"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/google/gopacket/pcap"
 )
 
 // 回调函数类型定义
-type NetworkInfoCallback func(domain string)
-type ScreenCapCallback func(buffer *bytes.Buffer)
+type NetworkInfoCallback func(event netcap.SniEvent)
+type ScreenCapCallback func(buffer *bytes.Buffer, meta utils.ScreenshotMeta)
+type BehaviorCallback func(eventType int, content string, level string)
+type TrafficCallback func(interfaces []netcap.InterfaceTraffic, hosts []netcap.HostTraffic)
 
 // 全局回调函数
 var networkInfoCallback NetworkInfoCallback
 var screenCapCallback ScreenCapCallback
+var behaviorCallback BehaviorCallback
+var trafficCallback TrafficCallback
+
+// 域名分类与降噪过滤器，登录时在 IPC 协程中替换，在各接口的抓包协程中读取
+var domainFilter atomic.Pointer[netcap.DomainFilter]
+
+func init() {
+	domainFilter.Store(netcap.NewDomainFilter(nil, nil))
+}
 
 // SetReportCallbacks 设置回调函数
 func SetReportCallbacks(netCallback NetworkInfoCallback, screenCallback ScreenCapCallback) {
@@ -31,17 +39,48 @@ func SetReportCallbacks(netCallback NetworkInfoCallback, screenCallback ScreenCa
 	screenCapCallback = screenCallback
 }
 
+// SetBehaviorCallback 设置行为事件上报回调
+func SetBehaviorCallback(callback BehaviorCallback) {
+	behaviorCallback = callback
+}
+
+// SetTrafficCallback 设置流量汇总上报回调
+func SetTrafficCallback(callback TrafficCallback) {
+	trafficCallback = callback
+}
+
+// SetDomainPolicy 根据考试配置设置允许和禁止访问的域名名单
+func SetDomainPolicy(allowed, forbidden []string) {
+	if previous := domainFilter.Swap(netcap.NewDomainFilter(allowed, forbidden)); previous != nil {
+		previous.Close()
+	}
+}
+
 // ReportNetworkInfo 上报网络访问信息
-func ReportNetworkInfo(domain string) {
+func ReportNetworkInfo(event netcap.SniEvent) {
 	if networkInfoCallback != nil {
-		networkInfoCallback(domain)
+		networkInfoCallback(event)
 	}
 }
 
 // ReportScreenCap 上报屏幕截图
-func ReportScreenCap(buffer *bytes.Buffer) {
+func ReportScreenCap(buffer *bytes.Buffer, meta utils.ScreenshotMeta) {
 	if screenCapCallback != nil {
-		screenCapCallback(buffer)
+		screenCapCallback(buffer, meta)
+	}
+}
+
+// ReportBehavior 上报行为事件
+func ReportBehavior(eventType int, content string, level string) {
+	if behaviorCallback != nil {
+		behaviorCallback(eventType, content, level)
+	}
+}
+
+// ReportTraffic 上报流量汇总
+func ReportTraffic(interfaces []netcap.InterfaceTraffic, hosts []netcap.HostTraffic) {
+	if trafficCallback != nil {
+		trafficCallback(interfaces, hosts)
 	}
 }
 
@@ -50,79 +89,172 @@ func GetUsbDeviceInfo() ([]devices.USBDevice, error) {
 	return ds, err
 }
 
-func WatchNetworkInfo() {
+// GetHardwareInfo 获取设备硬件信息
+func GetHardwareInfo() {
+	deviceInfo, err := devices.GetDeviceInfo()
+	if err != nil {
+		log.Println("GetHardwareInfo", err)
+		return
+	}
+	// 打印格式化的设备信息到控制台
+	fmt.Println("设备信息:")
+	fmt.Println(devices.FormatDeviceInfo(deviceInfo))
+}
 
-	ifs, err := pcap.FindAllDevs()
+// screenshotConfig 截图配置和状态，登录时在 IPC 协程中设置，在截图协程中读取
+type screenshotConfig struct {
+	mu               sync.Mutex
+	mode             screencap.CaptureMode // 截图模式，默认多显示器拼接为一张图
+	encoder          *screencap.Encoder    // 截图编码器，根据上传状况自适应调整质量和分辨率
+	examID           int                   // 截图水印中的考试信息
+	accountID        int                   // 截图水印中的考生信息
+	lastDisplayCount int                   // 上次截图时的显示器数量
+}
+
+var screenshots = screenshotConfig{
+	mode:    screencap.ModeComposite,
+	encoder: screencap.NewEncoder(screencap.DefaultEncoderConfig),
+}
+
+// snapshot 返回本次截图使用的模式、编码器和水印身份信息
+func (c *screenshotConfig) snapshot() (screencap.CaptureMode, *screencap.Encoder, int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mode, c.encoder, c.examID, c.accountID
+}
+
+// swapDisplayCount 记录本次截图的显示器数量并返回上次的数量
+func (c *screenshotConfig) swapDisplayCount(count int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous := c.lastDisplayCount
+	c.lastDisplayCount = count
+	return previous
+}
+
+// SetScreenCaptureMode 设置截图模式，应在开始截图前调用
+func SetScreenCaptureMode(mode screencap.CaptureMode) {
+	screenshots.mu.Lock()
+	defer screenshots.mu.Unlock()
+	screenshots.mode = mode
+}
+
+// ScreenshotCooldown 截图最小间隔，事件触发和定时截图共用
+const ScreenshotCooldown = 3 * time.Second
+
+// 截图限流器，冷却期内的截图请求会被跳过
+var screenshotThrottler = utils.NewAdvancedThrottler(ScreenshotCooldown)
+
+// UploadStatsFunc 返回截图上传队列积压数量和上传带宽(字节/秒)
+type UploadStatsFunc func() (backlog int, bandwidth float64)
+
+var uploadStats UploadStatsFunc
+
+// SetScreenshotEncoding 根据考试配置设置截图编码参数
+func SetScreenshotEncoding(config screencap.EncoderConfig) {
+	encoder := screencap.NewEncoder(config)
+	screenshots.mu.Lock()
+	defer screenshots.mu.Unlock()
+	screenshots.encoder = encoder
+}
+
+// 本次登录内的截图序号
+var screenshotSequence atomic.Uint64
+
+// SetWatermarkIdentity 设置截图水印中的考试和考生信息，并重置截图序号
+func SetWatermarkIdentity(examID, accountID int) {
+	screenshots.mu.Lock()
+	defer screenshots.mu.Unlock()
+	screenshots.examID = examID
+	screenshots.accountID = accountID
+	screenshotSequence.Store(0)
+}
+
+// SetUploadStatsProvider 设置上传状况来源，用于自适应调整截图编码
+func SetUploadStatsProvider(provider UploadStatsFunc) {
+	uploadStats = provider
+}
+
+// TriggerScreenshot 请求一次截图，受共享限流器约束
+func TriggerScreenshot() {
+	screenshotThrottler.Do(CaptureScreens)
+}
+
+// CaptureScreens 截取所有显示器并上报，显示器数量变化时上报行为事件
+func CaptureScreens() {
+	mode, encoder, examID, accountID := screenshots.snapshot()
+	captures, err := screencap.CaptureDisplays(mode)
 	if err != nil {
-		log.Fatal(err)
+		log.Println(err)
 		return
 	}
-	var ds []string
 
-	log.Println("Watching network info...")
+	checkDisplayCount(captures[0].DisplayCount)
 
-	for _, iface := range ifs {
-		if !strings.Contains(strings.ToLower(iface.Name), "loopback") &&
-			!devices.IsVirtualInterface(iface.Name) &&
-			!devices.IsVirtualInterface(iface.Description) &&
-			len(iface.Addresses) > 0 {
-			log.Println(iface.Description, iface.Name)
-			ds = append(ds, iface.Name)
-		}
+	if uploadStats != nil {
+		encoder.Adapt(uploadStats())
 	}
-	for _, d := range ds {
-		utils.Go(func() {
-			live := netcap.OpenLive(d)
-			if live != nil {
-				log.Printf("开始监控网络设备: %s", d)
-				for domain := range live.Ch {
-					log.Printf("检测到域名访问: %s (设备: %s)", domain, d)
-					ReportNetworkInfo(domain)
-				}
-			}
+
+	for _, c := range captures {
+		// 感知哈希已在截图时计算，水印中的时间和序号不会影响画面去重
+		mark := screencap.Watermark{
+			ExamID:    examID,
+			AccountID: accountID,
+			Time:      time.Now(),
+			Sequence:  screenshotSequence.Add(1),
+		}
+		encoded, err := encoder.Encode(c.Image, &mark)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		ReportScreenCap(encoded.Buffer, utils.ScreenshotMeta{
+			DisplayIndex: c.Index,
+			DisplayCount: c.DisplayCount,
+			Width:        encoded.Width,
+			Height:       encoded.Height,
+			Hash:         c.Hash,
+			Format:       encoded.Format.Extension(),
+			Quality:      encoded.Quality,
+			CaptureTime:  mark.Time,
+			Sequence:     mark.Sequence,
 		})
 	}
-
 }
 
-// GetHardwareInfo 获取设备硬件信息
-func GetHardwareInfo() {
-	deviceInfo, err := devices.GetDeviceInfo()
-	if err != nil {
-		log.Println("GetHardwareInfo", err)
+// checkDisplayCount 显示器数量变化时上报，多显示器按警告级别上报
+func checkDisplayCount(count int) {
+	previous := screenshots.swapDisplayCount(count)
+	if count == previous {
 		return
 	}
-	// 打印格式化的设备信息到控制台
-	fmt.Println("设备信息:")
-	fmt.Println(devices.FormatDeviceInfo(deviceInfo))
+
+	// 首次截图只有一个显示器时无需上报
+	if previous == 0 && count <= 1 {
+		return
+	}
+
+	level := utils.LevelInfo
+	if count > 1 {
+		level = utils.LevelWarning
+	}
+	content := fmt.Sprintf("显示器数量变化: %d -> %d", previous, count)
+	if previous == 0 {
+		content = fmt.Sprintf("检测到 %d 个显示器", count)
+	}
+	log.Println(content)
+	ReportBehavior(utils.BehaviorDisplayChange, content, level)
 }
 
 // MonitorForegroundWindow 监控前台窗口变化
 func MonitorForegroundWindow() {
-	var prev syscall.Handle = 0
+	var prev uintptr
 
-	throttler := utils.NewAdvancedThrottler(time.Second * 3)
 	for {
 		info := foreground.GetWindowInfo()
-		if info == nil {
-			continue
-		}
-		if info.Handle != prev {
+		if info != nil && info.Handle != prev {
 			log.Printf("焦点切换 -> 进程: %-20s PID: %-6d 窗口: %-50s 路径: %s\n", info.ProcessName, info.ProcessID, info.Title, info.ProcessPath)
-			throttler.Do(func() {
-				img, err := screencap.ScreenCap()
-				if err != nil {
-					log.Println(err)
-					return
-				}
-				buffer := bytes.NewBuffer(nil)
-				err = jpeg.Encode(buffer, img, nil)
-				if err != nil {
-					log.Println(err)
-					return
-				}
-				ReportScreenCap(buffer)
-			})
+			TriggerScreenshot()
 			prev = info.Handle
 		}
 
@@ -0,0 +1,202 @@
+package compose
+
+import (
+	"fmt"
+	"log"
+	"monitor-desktop-client/devices"
+	"monitor-desktop-client/netcap"
+	"monitor-desktop-client/utils"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InterfaceRescanInterval 重新枚举网络接口的间隔
+const InterfaceRescanInterval = 5 * time.Second
+
+// interfaceWatcher 单个网络接口的抓包状态
+type interfaceWatcher struct {
+	description string
+	addresses   string // 排序后的地址列表，用于检测地址变化
+	live        *netcap.SniStreamFactory
+}
+
+var (
+	networkWatchMu   sync.Mutex
+	networkWatchStop chan struct{}
+)
+
+// WatchNetworkInfo 监控网络访问，周期性重新枚举网络接口，
+// 接口接入、移除或地址变化时启动或停止对应的抓包，直到调用 StopWatchNetworkInfo
+func WatchNetworkInfo() {
+	networkWatchMu.Lock()
+	if networkWatchStop != nil {
+		networkWatchMu.Unlock()
+		log.Println("网络监控已在运行")
+		return
+	}
+	stop := make(chan struct{})
+	networkWatchStop = stop
+	networkWatchMu.Unlock()
+
+	log.Println("Watching network info...")
+
+	watchers := make(map[string]*interfaceWatcher)
+	known := make(map[string]bool) // 已出现过的接口，避免打开失败或地址变化时重复上报
+	defer func() {
+		for name, w := range watchers {
+			w.live.Close()
+			removeTrafficCounter(name)
+		}
+	}()
+
+	// 流量统计与异常检测随网络监控一起启停
+	policy := currentTrafficPolicy()
+	utils.Go(func() {
+		monitorTraffic(policy, stop)
+	})
+
+	ticker := time.NewTicker(InterfaceRescanInterval)
+	defer ticker.Stop()
+
+	initial := true
+	for {
+		syncInterfaces(watchers, known, initial)
+		initial = false
+
+		select {
+		case <-stop:
+			log.Println("网络监控已停止")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StopWatchNetworkInfo 停止网络监控及所有接口的抓包
+func StopWatchNetworkInfo() {
+	networkWatchMu.Lock()
+	defer networkWatchMu.Unlock()
+	if networkWatchStop != nil {
+		close(networkWatchStop)
+		networkWatchStop = nil
+	}
+}
+
+// syncInterfaces 比较当前接口与正在监控的接口，增删抓包并上报新接入的接口
+func syncInterfaces(watchers map[string]*interfaceWatcher, known map[string]bool, initial bool) {
+	ifs, err := netcap.FindAllDevs()
+	if err != nil {
+		log.Println("枚举网络接口失败:", err)
+		return
+	}
+
+	current := make(map[string]netcap.Interface)
+	for _, iface := range ifs {
+		if !isLoopbackOrVirtual(iface.Name) &&
+			!devices.IsVirtualInterface(iface.Description) &&
+			len(iface.Addresses) > 0 {
+			current[iface.Name] = iface
+		}
+	}
+
+	// 停止已移除、地址变化或抓包已结束的接口
+	for name, w := range watchers {
+		iface, ok := current[name]
+		switch {
+		case !ok:
+			log.Printf("网络接口已移除: %s %s", w.description, name)
+			ReportBehavior(utils.BehaviorNetworkInterface,
+				fmt.Sprintf("网络接口已移除: %s (%s)", w.description, name), utils.LevelInfo)
+		case interfaceAddresses(iface) != w.addresses:
+			log.Printf("网络接口地址变化: %s %s -> %s", name, w.addresses, interfaceAddresses(iface))
+		case isClosed(w.live.Done):
+			log.Printf("网络接口抓包已结束，准备重新打开: %s", name)
+		default:
+			continue
+		}
+		w.live.Close()
+		removeTrafficCounter(name)
+		delete(watchers, name)
+	}
+
+	// 为新接口启动抓包，打开失败的接口在下一轮重试
+	for name, iface := range current {
+		if _, ok := watchers[name]; ok {
+			continue
+		}
+
+		addresses := interfaceAddresses(iface)
+		if !initial && !known[name] {
+			reportNewInterface(iface, addresses)
+		}
+		known[name] = true
+
+		live := netcap.OpenLive(name)
+		if live == nil {
+			continue
+		}
+		log.Printf("开始监控网络设备: %s %s", iface.Description, name)
+
+		// 远端主机流量计数失败不影响域名监控
+		if counter := netcap.OpenCounter(name, iface.Addresses); counter != nil {
+			addTrafficCounter(name, counter)
+		}
+
+		watchers[name] = &interfaceWatcher{
+			description: iface.Description,
+			addresses:   addresses,
+			live:        live,
+		}
+		utils.Go(func() {
+			consumeSniEvents(name, live)
+		})
+	}
+}
+
+// reportNewInterface 上报考试过程中新接入的网络接口，手机共享网络按警告级别上报
+func reportNewInterface(iface netcap.Interface, addresses string) {
+	level := utils.LevelInfo
+	kind := "网络接口"
+	if devices.IsTetheringInterface(iface.Name, iface.Description, iface.Addresses) {
+		level = utils.LevelWarning
+		kind = "手机共享网络/移动热点"
+	}
+
+	content := fmt.Sprintf("检测到新接入的%s: %s (%s) 地址: %s", kind, iface.Description, iface.Name, addresses)
+	log.Println(content)
+	ReportBehavior(utils.BehaviorNetworkInterface, content, level)
+}
+
+// consumeSniEvents 过滤并上报单个接口捕获到的域名访问
+func consumeSniEvents(device string, live *netcap.SniStreamFactory) {
+	for {
+		select {
+		case event := <-live.Ch:
+			if !domainFilter.Load().Accept(&event) {
+				continue
+			}
+			log.Printf("检测到域名访问: %s [%s] (设备: %s, 进程: %s)", event.Domain, event.Category, device, event.ProcessName)
+			ReportNetworkInfo(event)
+		case <-live.Done:
+			return
+		}
+	}
+}
+
+// interfaceAddresses 返回接口排序后的IP地址列表
+func interfaceAddresses(iface netcap.Interface) string {
+	addrs := append([]string(nil), iface.Addresses...)
+	sort.Strings(addrs)
+	return strings.Join(addrs, ",")
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,198 @@
+package compose
+
+import (
+	"fmt"
+	"log"
+	"monitor-desktop-client/devices"
+	"monitor-desktop-client/netcap"
+	"monitor-desktop-client/utils"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	TrafficSampleInterval  = 5 * time.Second // 接口流量采样间隔
+	TrafficSummaryInterval = time.Minute     // 流量汇总上报间隔
+	TrafficTopHosts        = 20              // 汇总中保留的远端主机数量
+)
+
+// TrafficPolicy 上行流量异常判定策略
+type TrafficPolicy struct {
+	UploadRateThreshold float64       // 持续上行速率阈值(字节/秒)
+	SustainDuration     time.Duration // 超过阈值持续多久判定为异常
+}
+
+// DefaultTrafficPolicy 默认策略: 上行持续30秒超过256KB/s，大致相当于一路屏幕共享
+var DefaultTrafficPolicy = TrafficPolicy{
+	UploadRateThreshold: 256 * 1024,
+	SustainDuration:     30 * time.Second,
+}
+
+var (
+	trafficPolicyMu sync.Mutex
+	trafficPolicy   = DefaultTrafficPolicy
+)
+
+// SetTrafficPolicy 设置上行流量异常判定策略，零值字段使用默认值而不是上一场考试的设置
+// 新策略在下次调用 WatchNetworkInfo 时生效
+func SetTrafficPolicy(policy TrafficPolicy) {
+	if policy.UploadRateThreshold <= 0 {
+		policy.UploadRateThreshold = DefaultTrafficPolicy.UploadRateThreshold
+	}
+	if policy.SustainDuration <= 0 {
+		policy.SustainDuration = DefaultTrafficPolicy.SustainDuration
+	}
+
+	trafficPolicyMu.Lock()
+	defer trafficPolicyMu.Unlock()
+	trafficPolicy = policy
+}
+
+// currentTrafficPolicy 返回当前策略的副本
+func currentTrafficPolicy() TrafficPolicy {
+	trafficPolicyMu.Lock()
+	defer trafficPolicyMu.Unlock()
+	return trafficPolicy
+}
+
+// 各接口的远端主机流量计数器，由网络接口监控维护
+var (
+	trafficCountersMu sync.Mutex
+	trafficCounters   = make(map[string]*netcap.TrafficCounter)
+)
+
+func addTrafficCounter(device string, counter *netcap.TrafficCounter) {
+	trafficCountersMu.Lock()
+	defer trafficCountersMu.Unlock()
+	trafficCounters[device] = counter
+}
+
+func removeTrafficCounter(device string) {
+	trafficCountersMu.Lock()
+	defer trafficCountersMu.Unlock()
+	if counter, ok := trafficCounters[device]; ok {
+		counter.Close()
+		delete(trafficCounters, device)
+	}
+}
+
+// snapshotHosts 汇总所有接口的远端主机流量
+func snapshotHosts() []netcap.HostTraffic {
+	trafficCountersMu.Lock()
+	defer trafficCountersMu.Unlock()
+
+	var hosts []netcap.HostTraffic
+	for _, counter := range trafficCounters {
+		hosts = append(hosts, counter.Snapshot()...)
+	}
+	return netcap.TopHosts(hosts, TrafficTopHosts)
+}
+
+// monitorTraffic 周期性采样接口流量，上报汇总并检测持续的大流量上传
+func monitorTraffic(policy TrafficPolicy, stop chan struct{}) {
+	sampler := netcap.NewInterfaceSampler()
+	if _, err := sampler.Sample(); err != nil {
+		log.Println("读取网卡流量计数失败:", err)
+	}
+
+	sampleTicker := time.NewTicker(TrafficSampleInterval)
+	defer sampleTicker.Stop()
+	summaryTicker := time.NewTicker(TrafficSummaryInterval)
+	defer summaryTicker.Stop()
+
+	totals := make(map[string]*netcap.InterfaceTraffic)
+	summaryStart := time.Now()
+
+	var overSince time.Time
+	var reported bool
+
+	for {
+		select {
+		case <-stop:
+			return
+
+		case <-sampleTicker.C:
+			samples, err := sampler.Sample()
+			if err != nil {
+				log.Println("读取网卡流量计数失败:", err)
+				continue
+			}
+
+			var uploadRate float64
+			for _, s := range samples {
+				if isLoopbackOrVirtual(s.Name) {
+					continue
+				}
+				uploadRate += s.UploadRate
+
+				t, ok := totals[s.Name]
+				if !ok {
+					t = &netcap.InterfaceTraffic{Name: s.Name}
+					totals[s.Name] = t
+				}
+				t.BytesSent += s.BytesSent
+				t.BytesRecv += s.BytesRecv
+				t.PacketsSent += s.PacketsSent
+				t.PacketsRecv += s.PacketsRecv
+			}
+
+			// 上行速率需持续超过阈值才判定为异常，恢复正常后可再次上报
+			if uploadRate < policy.UploadRateThreshold {
+				overSince = time.Time{}
+				reported = false
+				continue
+			}
+			if overSince.IsZero() {
+				overSince = time.Now()
+			}
+			if !reported && time.Since(overSince) >= policy.SustainDuration {
+				reported = true
+				content := fmt.Sprintf("检测到持续的大流量上传: %.1f KB/s，已持续 %s", uploadRate/1024, time.Since(overSince).Round(time.Second))
+				if hosts := netcap.TopHosts(peekHosts(), 3); len(hosts) > 0 {
+					var targets []string
+					for _, h := range hosts {
+						targets = append(targets, h.Host)
+					}
+					content += "，主要目标: " + strings.Join(targets, ", ")
+				}
+				log.Println(content)
+				ReportBehavior(utils.BehaviorTrafficAnomaly, content, utils.LevelWarning)
+			}
+
+		case <-summaryTicker.C:
+			seconds := time.Since(summaryStart).Seconds()
+			summaryStart = time.Now()
+
+			interfaces := make([]netcap.InterfaceTraffic, 0, len(totals))
+			for _, t := range totals {
+				if seconds > 0 {
+					t.UploadRate = float64(t.BytesSent) / seconds
+					t.DownloadRate = float64(t.BytesRecv) / seconds
+				}
+				interfaces = append(interfaces, *t)
+			}
+			totals = make(map[string]*netcap.InterfaceTraffic)
+
+			ReportTraffic(interfaces, snapshotHosts())
+		}
+	}
+}
+
+// peekHosts 读取当前统计周期内的远端主机流量但不清零
+func peekHosts() []netcap.HostTraffic {
+	trafficCountersMu.Lock()
+	defer trafficCountersMu.Unlock()
+
+	var hosts []netcap.HostTraffic
+	for _, counter := range trafficCounters {
+		hosts = append(hosts, counter.Peek()...)
+	}
+	return hosts
+}
+
+// isLoopbackOrVirtual 判断系统网卡名称是否为回环或虚拟网卡
+func isLoopbackOrVirtual(name string) bool {
+	lower := strings.ToLower(name)
+	return lower == "lo" || strings.Contains(lower, "loopback") || devices.IsVirtualInterface(name)
+}
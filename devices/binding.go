@@ -0,0 +1,107 @@
+package devices
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// bindingFileName 本地设备绑定缓存，只保存指纹哈希，不保存原始硬件标识
+const bindingFileName = "device-binding.json"
+
+// Binding 考生账号与设备的绑定记录
+type Binding struct {
+	Account     string      `json:"account"`
+	Fingerprint Fingerprint `json:"fingerprint"`
+	BoundAt     time.Time   `json:"boundAt"`
+	Salt        string      `json:"salt"` // 指纹所用盐值的摘要
+}
+
+var bindingMu sync.Mutex
+
+// BindingFile 返回本地绑定缓存路径
+func BindingFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "monitor-desktop-client", bindingFileName), nil
+}
+
+// LoadBinding 读取账号的本地绑定，没有绑定时返回 nil
+func LoadBinding(account string) (*Binding, error) {
+	bindingMu.Lock()
+	defer bindingMu.Unlock()
+
+	bindings, err := readBindings()
+	if err != nil {
+		return nil, err
+	}
+	binding, ok := bindings[account]
+	if !ok {
+		return nil, nil
+	}
+	return &binding, nil
+}
+
+// SaveBinding 保存账号的本地绑定
+func SaveBinding(binding Binding) error {
+	bindingMu.Lock()
+	defer bindingMu.Unlock()
+
+	bindings, err := readBindings()
+	if err != nil {
+		// 缓存损坏时重新生成，不影响登录
+		bindings = make(map[string]Binding)
+	}
+	if binding.Fingerprint.salt != "" {
+		binding.Salt = binding.Fingerprint.salt
+	}
+	bindings[binding.Account] = binding
+
+	path, err := BindingFile()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(bindings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// VerifyBinding 比对本地绑定和当前设备指纹
+// 指纹版本升级或服务器更换盐值后无法逐项比对，此时以旧版本标识判断是否同一台设备
+func VerifyBinding(binding *Binding, current Fingerprint) FingerprintMatch {
+	if binding.Fingerprint.Version != current.Version || binding.Salt != current.salt {
+		same := binding.Fingerprint.LegacyID != "" && binding.Fingerprint.LegacyID == current.LegacyID
+		return FingerprintMatch{Stable: same}
+	}
+	return CompareFingerprints(binding.Fingerprint, current)
+}
+
+func readBindings() (map[string]Binding, error) {
+	path, err := BindingFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]Binding), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bindings := make(map[string]Binding)
+	if err = json.Unmarshal(data, &bindings); err != nil {
+		return nil, err
+	}
+	return bindings, nil
+}
@@ -0,0 +1,115 @@
+package devices
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeConfigDir 将 os.UserConfigDir 指向临时目录
+func fakeConfigDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("AppData", dir)
+	t.Setenv("HOME", dir)
+}
+
+func TestSaveBinding(t *testing.T) {
+	fakeConfigDir(t)
+
+	if binding, err := LoadBinding("student01"); binding != nil || err != nil {
+		t.Fatalf("没有绑定时 LoadBinding() = %+v, %v", binding, err)
+	}
+
+	fp := NewFingerprint(testIdentifiers, testSalt)
+	fp.LegacyID = "5f3a2b1c"
+	boundAt := time.Date(2026, 9, 1, 8, 30, 0, 0, time.UTC)
+	if err := SaveBinding(Binding{Account: "student01", Fingerprint: fp, BoundAt: boundAt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveBinding(Binding{Account: "student02", Fingerprint: fp, BoundAt: boundAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	binding, err := LoadBinding("student01")
+	if err != nil || binding == nil {
+		t.Fatalf("LoadBinding() = %+v, %v", binding, err)
+	}
+	if binding.Fingerprint.ID != fp.ID || binding.Fingerprint.LegacyID != fp.LegacyID || !binding.BoundAt.Equal(boundAt) {
+		t.Errorf("LoadBinding() = %+v", binding)
+	}
+	if binding.Salt != saltDigest(testSalt) {
+		t.Errorf("Salt = %q, want %q", binding.Salt, saltDigest(testSalt))
+	}
+
+	path, _ := BindingFile()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); os.PathSeparator == '/' && perm != 0600 {
+		t.Errorf("绑定文件权限 = %o, want 600", perm)
+	}
+}
+
+func TestSaveBindingCorrupted(t *testing.T) {
+	fakeConfigDir(t)
+	path, _ := BindingFile()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadBinding("student01"); err == nil {
+		t.Error("缓存损坏时 LoadBinding 应返回错误")
+	}
+	// 损坏的缓存被重新生成
+	fp := NewFingerprint(testIdentifiers, testSalt)
+	if err := SaveBinding(Binding{Account: "student01", Fingerprint: fp}); err != nil {
+		t.Fatal(err)
+	}
+	if binding, err := LoadBinding("student01"); err != nil || binding == nil {
+		t.Errorf("LoadBinding() = %+v, %v", binding, err)
+	}
+}
+
+func TestVerifyBinding(t *testing.T) {
+	stored := NewFingerprint(testIdentifiers, testSalt)
+	stored.LegacyID = "5f3a2b1c"
+	binding := &Binding{Account: "student01", Fingerprint: stored, Salt: saltDigest(testSalt)}
+
+	legacy := func(fp Fingerprint, legacyID string) Fingerprint {
+		fp.LegacyID = legacyID
+		return fp
+	}
+	oldVersion := legacy(NewFingerprint(testIdentifiers, testSalt), "5f3a2b1c")
+	oldVersion.Version = 1
+
+	tests := []struct {
+		name    string
+		current Fingerprint
+		stable  bool
+	}{
+		{"same", NewFingerprint(testIdentifiers, testSalt), true},
+		{"changed", NewFingerprint(withIdentifiers(map[string]string{
+			ComponentBIOSUUID: "9d2e1f3a-0000-4a1b-8c2d-3e4f5a6b7c8d",
+			ComponentDisk:     "S5GXNX0T654321",
+		}), testSalt), false},
+		// 版本或盐值不同时无法逐项比对，按旧版本标识判断
+		{"version-legacy-same", oldVersion, true},
+		{"salt-legacy-same", legacy(NewFingerprint(testIdentifiers, "new-salt"), "5f3a2b1c"), true},
+		{"salt-legacy-changed", legacy(NewFingerprint(testIdentifiers, "new-salt"), "0a0b0c0d"), false},
+		{"salt-legacy-missing", NewFingerprint(testIdentifiers, "new-salt"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyBinding(binding, tt.current); got.Stable != tt.stable {
+				t.Errorf("VerifyBinding() = %+v, want Stable %v", got, tt.stable)
+			}
+		})
+	}
+}
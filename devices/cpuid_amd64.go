@@ -0,0 +1,20 @@
+package devices
+
+import "encoding/binary"
+
+func cpuid(leaf, subleaf uint32) (eax, ebx, ecx, edx uint32)
+
+// hypervisorCPUID 读取 CPUID 叶1 ECX 第31位(hypervisor present)和叶 0x40000000 的虚拟化厂商标识
+func hypervisorCPUID() (present bool, vendor string, ok bool) {
+	_, _, ecx, _ := cpuid(1, 0)
+	if ecx&(1<<31) == 0 {
+		return false, "", true
+	}
+
+	_, ebx, ecx, edx := cpuid(0x40000000, 0)
+	id := make([]byte, 12)
+	binary.LittleEndian.PutUint32(id[0:], ebx)
+	binary.LittleEndian.PutUint32(id[4:], ecx)
+	binary.LittleEndian.PutUint32(id[8:], edx)
+	return true, string(id), true
+}
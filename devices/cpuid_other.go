@@ -0,0 +1,8 @@
+//go:build !amd64
+
+package devices
+
+// hypervisorCPUID 非 x86-64 平台无法读取 CPUID
+func hypervisorCPUID() (present bool, vendor string, ok bool) {
+	return false, "", false
+}
@@ -9,8 +9,6 @@ import (
 	"github.com/shirou/gopsutil/v3/disk"
 	"github.com/shirou/gopsutil/v3/host"
 	"github.com/shirou/gopsutil/v3/mem"
-	"golang.org/x/sys/windows"
-	"golang.org/x/sys/windows/registry"
 )
 
 // DeviceInfo 包含设备软硬件信息
@@ -25,11 +23,12 @@ type DeviceInfo struct {
 	KernelArch     string `json:"kernelArch"`
 
 	// 设备标识
-	UniqueID   string `json:"uniqueId"`
-	MachineID  string `json:"machineId"`
-	BIOSUUID   string `json:"biosUuid"`
-	ProductID  string `json:"productId"`
-	HardwareID string `json:"hardwareId"`
+	UniqueID    string      `json:"uniqueId"`
+	MachineID   string      `json:"machineId"`
+	BIOSUUID    string      `json:"biosUuid"`
+	ProductID   string      `json:"productId"`
+	HardwareID  string      `json:"hardwareId"`
+	Fingerprint Fingerprint `json:"fingerprint"`
 
 	// CPU信息
 	CPUModel     string  `json:"cpuModel"`
@@ -67,8 +66,15 @@ type DeviceInfo struct {
 	ProductSerial  string `json:"productSerial"`
 
 	// 安全信息
-	IsVirtualMachine bool     `json:"isVirtualMachine"`
-	SecuritySoftware []string `json:"securitySoftware"`
+	IsVirtualMachine bool       `json:"isVirtualMachine"`
+	VirtualScore     int        `json:"virtualScore"`
+	VirtualEvidence  []Evidence `json:"virtualEvidence"`
+	SecuritySoftware []string   `json:"securitySoftware"`
+
+	// 参与计算指纹的CPU、磁盘和网卡标识，只采集一次
+	cpuID        string
+	diskSerials  []string
+	macAddresses []string
 }
 
 // DiskInfo 磁盘信息
@@ -154,6 +160,9 @@ func GetDeviceInfo() (*DeviceInfo, error) {
 		return nil, fmt.Errorf("获取安全信息失败: %v", err)
 	}
 
+	// 生成设备指纹
+	deviceInfo.generateUniqueID()
+
 	return deviceInfo, nil
 }
 
@@ -184,8 +193,8 @@ func (d *DeviceInfo) collectSystemInfo() error {
 
 // collectDeviceIdentifiers 收集设备唯一标识信息
 func (d *DeviceInfo) collectDeviceIdentifiers() error {
-	// 1. 收集 Windows 产品ID
-	d.collectWindowsProductID()
+	// 1. 收集系统安装标识(Windows 产品ID / Linux machine-id)
+	d.collectProductID()
 
 	// 2. 收集 BIOS UUID
 	d.collectBIOSUUID()
@@ -193,52 +202,12 @@ func (d *DeviceInfo) collectDeviceIdentifiers() error {
 	// 3. 收集硬件ID (使用主板、CPU和硬盘信息组合)
 	d.collectHardwareID()
 
-	// 4. 生成设备唯一ID
-	d.generateUniqueID()
+	// 4. 生成旧版本设备标识，设备指纹在硬件信息收集完成后生成
+	d.generateLegacyID()
 
 	return nil
 }
 
-// collectWindowsProductID 获取Windows产品ID
-func (d *DeviceInfo) collectWindowsProductID() {
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows NT\CurrentVersion`, registry.READ)
-	if err != nil {
-		return
-	}
-	defer key.Close()
-
-	// 尝试读取产品ID
-	productID, _, _ := key.GetStringValue("ProductId")
-	if productID == "" {
-		// 备用方案：尝试读取数字产品ID
-		digitalID, _, _ := key.GetBinaryValue("DigitalProductId")
-		if len(digitalID) > 0 {
-			// 将数字产品ID转换为字符串表示
-			d.ProductID = fmt.Sprintf("%x", digitalID)
-		}
-	} else {
-		d.ProductID = productID
-	}
-}
-
-// collectBIOSUUID 获取BIOS UUID
-func (d *DeviceInfo) collectBIOSUUID() {
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\BIOS`, registry.READ)
-	if err != nil {
-		return
-	}
-	defer key.Close()
-
-	// 读取BIOS UUID
-	uuid, _, _ := key.GetStringValue("SystemUUID")
-	if uuid == "" {
-		// 备用方案：尝试读取系统产品UUID
-		uuid, _, _ = key.GetStringValue("UUID")
-	}
-
-	d.BIOSUUID = uuid
-}
-
 // collectHardwareID 收集硬件ID
 func (d *DeviceInfo) collectHardwareID() {
 	var hardwareComponents []string
@@ -249,15 +218,15 @@ func (d *DeviceInfo) collectHardwareID() {
 	}
 
 	// 2. 使用CPU ID
-	cpuID := getCPUID()
-	if cpuID != "" {
-		hardwareComponents = append(hardwareComponents, "CPU:"+cpuID)
+	d.cpuID = getCPUID()
+	if d.cpuID != "" {
+		hardwareComponents = append(hardwareComponents, "CPU:"+d.cpuID)
 	}
 
 	// 3. 使用主磁盘序列号
-	diskSerials := getPhysicalDiskSerials()
-	if len(diskSerials) > 0 {
-		for i, serial := range diskSerials {
+	d.diskSerials = getPhysicalDiskSerials()
+	if len(d.diskSerials) > 0 {
+		for i, serial := range d.diskSerials {
 			if i > 1 { // 只使用前两个磁盘
 				break
 			}
@@ -266,9 +235,9 @@ func (d *DeviceInfo) collectHardwareID() {
 	}
 
 	// 4. 使用网卡MAC地址
-	macAddresses := getMACAddresses()
-	if len(macAddresses) > 0 {
-		for i, mac := range macAddresses {
+	d.macAddresses = getMACAddresses()
+	if len(d.macAddresses) > 0 {
+		for i, mac := range d.macAddresses {
 			if i > 1 { // 只使用前两个MAC地址
 				break
 			}
@@ -301,112 +270,8 @@ func getMACAddresses() []string {
 	return macAddresses
 }
 
-// IsVirtualInterface 检查是否是虚拟网络接口
-func IsVirtualInterface(name string) bool {
-	// 常见虚拟接口名称标识
-	virtualPrefixes := []string{
-		"vethernet", "veth", "vmnet", "vboxnet",
-		"docker", "virbr", "br-", "vnet", "virtual",
-	}
-
-	nameLower := strings.ToLower(name)
-	for _, prefix := range virtualPrefixes {
-		if strings.Contains(nameLower, prefix) {
-			return true
-		}
-	}
-
-	return false
-}
-
-// getCPUID 获取CPU ID
-func getCPUID() string {
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\CentralProcessor\0`, registry.READ)
-	if err != nil {
-		return ""
-	}
-	defer key.Close()
-
-	// 读取处理器ID
-	processorID, _, _ := key.GetStringValue("ProcessorNameString")
-	if processorID == "" {
-		processorID, _, _ = key.GetStringValue("Identifier")
-	}
-
-	// 读取特征码
-	featureID, _, _ := key.GetIntegerValue("FeatureSet")
-
-	return fmt.Sprintf("%s-%d", processorID, featureID)
-}
-
-// getPhysicalDiskSerials 获取物理磁盘序列号
-func getPhysicalDiskSerials() []string {
-	var serials []string
-
-	// 使用WMI查询物理磁盘序列号
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DEVICEMAP\Scsi`, registry.READ)
-	if err != nil {
-		return serials
-	}
-	defer key.Close()
-
-	// 获取所有SCSI控制器
-	controllers, err := key.ReadSubKeyNames(-1)
-	if err != nil {
-		return serials
-	}
-
-	// 遍历所有控制器和设备
-	for _, controller := range controllers {
-		controllerKey, err := registry.OpenKey(key, controller, registry.READ)
-		if err != nil {
-			continue
-		}
-		defer controllerKey.Close()
-
-		// 获取设备
-		devices, err := controllerKey.ReadSubKeyNames(-1)
-		if err != nil {
-			continue
-		}
-
-		for _, device := range devices {
-			deviceKey, err := registry.OpenKey(controllerKey, device, registry.READ)
-			if err != nil {
-				continue
-			}
-			defer deviceKey.Close()
-
-			// 读取设备标识符和序列号
-			identifier, _, _ := deviceKey.GetStringValue("Identifier")
-			serialNumber, _, _ := deviceKey.GetStringValue("SerialNumber")
-
-			if serialNumber != "" {
-				serials = append(serials, serialNumber)
-			} else if identifier != "" {
-				serials = append(serials, identifier)
-			}
-		}
-	}
-
-	// 如果使用注册表方法没有找到，尝试使用WMIC命令获取
-	if len(serials) == 0 {
-		// 尝试使用固定一些已知信息(由于WMIC命令执行比较复杂，这里简化处理)
-		key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\BIOS`, registry.READ)
-		if err == nil {
-			defer key.Close()
-			diskSerial, _, _ := key.GetStringValue("DiskSerialNumber")
-			if diskSerial != "" {
-				serials = append(serials, diskSerial)
-			}
-		}
-	}
-
-	return serials
-}
-
-// generateUniqueID 生成设备唯一标识
-func (d *DeviceInfo) generateUniqueID() {
+// generateLegacyID 按版本1的算法生成旧标识，此时主板信息尚未收集，与旧版本客户端结果一致
+func (d *DeviceInfo) generateLegacyID() {
 	// 收集所有可用的标识符
 	var identifiers []string
 
@@ -435,30 +300,29 @@ func (d *DeviceInfo) generateUniqueID() {
 		identifiers = append(identifiers, d.MotherboardInfo.SerialNumber)
 	}
 
-	// 生成唯一ID
-	machineIdSource := strings.Join(identifiers, "-")
-
-	// 设置机器ID (简单的哈希算法)
-	d.MachineID = generateHash(machineIdSource)
-
-	// 设置最终的唯一ID
-	// 使用产品ID + 主板信息 + MAC地址的组合作为唯一标识
-	d.UniqueID = d.MachineID
+	d.Fingerprint.LegacyID = legacyHash(strings.Join(identifiers, "-"))
 }
 
-// generateHash 生成字符串的哈希值
-func generateHash(input string) string {
-	if input == "" {
-		return ""
-	}
-
-	// 一种简单的哈希算法
-	var h uint32
-	for i := 0; i < len(input); i++ {
-		h = 31*h + uint32(input[i])
-	}
-
-	return fmt.Sprintf("%x", h)
+// generateUniqueID 在所有硬件信息收集完成后计算设备指纹，作为设备唯一标识
+func (d *DeviceInfo) generateUniqueID() {
+	d.Fingerprint = d.FingerprintWithSalt(FingerprintSalt())
+	d.MachineID = d.Fingerprint.ID
+	d.UniqueID = d.Fingerprint.ID
+}
+
+// FingerprintWithSalt 使用指定盐值重新计算设备指纹，不重新采集硬件信息
+func (d *DeviceInfo) FingerprintWithSalt(salt string) Fingerprint {
+	fp := NewFingerprint(map[string]string{
+		ComponentBIOSUUID:      d.BIOSUUID,
+		ComponentProductID:     d.ProductID,
+		ComponentBoardSerial:   d.MotherboardInfo.SerialNumber,
+		ComponentProductSerial: d.ProductSerial,
+		ComponentCPU:           d.cpuID,
+		ComponentDisk:          strings.Join(d.diskSerials, ","),
+		ComponentMAC:           strings.Join(d.macAddresses, ","),
+	}, salt)
+	fp.LegacyID = d.Fingerprint.LegacyID
+	return fp
 }
 
 // collectCPUInfo 收集CPU信息
@@ -514,16 +378,6 @@ func (d *DeviceInfo) collectDiskInfo() error {
 			continue
 		}
 
-		// 判断是否为可移动设备
-		isRemovable := false
-		if strings.HasPrefix(partition.Device, "\\\\.\\") {
-			drive := partition.Mountpoint
-			if len(drive) >= 2 && drive[1] == ':' {
-				driveType := windows.GetDriveType(windows.StringToUTF16Ptr(drive + "\\"))
-				isRemovable = driveType == windows.DRIVE_REMOVABLE
-			}
-		}
-
 		diskInfo := DiskInfo{
 			Device:      partition.Device,
 			MountPoint:  partition.Mountpoint,
@@ -531,7 +385,7 @@ func (d *DeviceInfo) collectDiskInfo() error {
 			Total:       usage.Total,
 			Used:        usage.Used,
 			UsedPercent: usage.UsedPercent,
-			IsRemovable: isRemovable,
+			IsRemovable: isRemovablePartition(partition),
 		}
 
 		d.DiskInfo = append(d.DiskInfo, diskInfo)
@@ -576,110 +430,6 @@ func (d *DeviceInfo) collectNetworkInfo() error {
 	return nil
 }
 
-// getNetworkInterfaceTypeAndSpeed 获取网络接口类型和速度
-func getNetworkInterfaceTypeAndSpeed(interfaceName string) (string, uint64) {
-	// 尝试从注册表获取接口类型和速度
-	keyPath := fmt.Sprintf(`SYSTEM\CurrentControlSet\Control\Network\{4D36E972-E325-11CE-BFC1-08002BE10318}`)
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.READ)
-	if err != nil {
-		return "未知", 0
-	}
-	defer key.Close()
-
-	// 遍历子键查找匹配的接口
-	subKeys, err := key.ReadSubKeyNames(-1)
-	if err != nil {
-		return "未知", 0
-	}
-
-	for _, subKey := range subKeys {
-		connectionKey, err := registry.OpenKey(key, subKey+`\Connection`, registry.READ)
-		if err != nil {
-			continue
-		}
-		defer connectionKey.Close()
-
-		// 检查接口名称
-		name, _, err := connectionKey.GetStringValue("Name")
-		if err != nil || name != interfaceName {
-			continue
-		}
-
-		// 获取接口类型
-		mediaType := "有线"
-		if strings.Contains(strings.ToLower(name), "wi-fi") || strings.Contains(strings.ToLower(name), "wireless") {
-			mediaType = "无线"
-		} else if strings.Contains(strings.ToLower(name), "bluetooth") {
-			mediaType = "蓝牙"
-		}
-
-		// 获取接口速度
-		var speed uint64 = 0
-		speedValue, _, err := connectionKey.GetIntegerValue("Speed")
-		if err == nil {
-			speed = speedValue
-		}
-
-		return mediaType, speed
-	}
-
-	return "未知", 0
-}
-
-// collectGPUInfo 收集显卡信息
-func (d *DeviceInfo) collectGPUInfo() error {
-	// 从注册表获取显卡信息
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control\Class\{4d36e968-e325-11ce-bfc1-08002be10318}`, registry.READ)
-	if err != nil {
-		return err
-	}
-	defer key.Close()
-
-	// 遍历子键查找显卡
-	subKeys, err := key.ReadSubKeyNames(-1)
-	if err != nil {
-		return err
-	}
-
-	for _, subKey := range subKeys {
-		if subKey == "Properties" {
-			continue
-		}
-
-		gpuKey, err := registry.OpenKey(key, subKey, registry.READ)
-		if err != nil {
-			continue
-		}
-		defer gpuKey.Close()
-
-		// 读取显卡信息
-		driverDesc, _, err := gpuKey.GetStringValue("DriverDesc")
-		if err != nil {
-			continue
-		}
-
-		// 如果能找到驱动描述，说明是显卡
-		gpuInfo := GPUInfo{
-			Name: driverDesc,
-		}
-
-		// 尝试获取其他信息
-		gpuInfo.Vendor, _, _ = gpuKey.GetStringValue("ProviderName")
-		gpuInfo.DriverVer, _, _ = gpuKey.GetStringValue("DriverVersion")
-		gpuInfo.DriverDate, _, _ = gpuKey.GetStringValue("DriverDate")
-
-		// 获取显存
-		memoryValue, _, err := gpuKey.GetIntegerValue("HardwareInformation.MemorySize")
-		if err == nil {
-			gpuInfo.Memory = memoryValue
-		}
-
-		d.GPUInfo = append(d.GPUInfo, gpuInfo)
-	}
-
-	return nil
-}
-
 // collectHardwareInfo 收集BIOS和主板信息
 func (d *DeviceInfo) collectHardwareInfo() error {
 	// 获取BIOS信息
@@ -697,57 +447,6 @@ func (d *DeviceInfo) collectHardwareInfo() error {
 	return nil
 }
 
-// collectBIOSInfo 收集BIOS信息
-func (d *DeviceInfo) collectBIOSInfo() error {
-	// 使用WMI查询BIOS信息
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\BIOS`, registry.READ)
-	if err != nil {
-		return err
-	}
-	defer key.Close()
-
-	// 读取BIOS信息
-	d.BIOSVendor, _, _ = key.GetStringValue("BIOSVendor")
-	d.BIOSVersion, _, _ = key.GetStringValue("BIOSVersion")
-	d.BIOSDate, _, _ = key.GetStringValue("BIOSReleaseDate")
-
-	return nil
-}
-
-// collectMotherboardInfo 收集主板信息
-func (d *DeviceInfo) collectMotherboardInfo() error {
-	// 从注册表获取主板信息
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\BIOS`, registry.READ)
-	if err != nil {
-		return err
-	}
-	defer key.Close()
-
-	d.MotherboardInfo.Manufacturer, _, _ = key.GetStringValue("BaseBoardManufacturer")
-	d.MotherboardInfo.Product, _, _ = key.GetStringValue("BaseBoardProduct")
-	d.MotherboardInfo.SerialNumber, _, _ = key.GetStringValue("BaseBoardSerialNumber")
-	d.MotherboardInfo.Version, _, _ = key.GetStringValue("BaseBoardVersion")
-
-	return nil
-}
-
-// collectProductInfo 收集产品信息
-func (d *DeviceInfo) collectProductInfo() error {
-	// 从注册表获取产品信息
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\BIOS`, registry.READ)
-	if err != nil {
-		return err
-	}
-	defer key.Close()
-
-	d.ProductName, _, _ = key.GetStringValue("SystemProductName")
-	d.ProductVendor, _, _ = key.GetStringValue("SystemManufacturer")
-	d.ProductVersion, _, _ = key.GetStringValue("SystemVersion")
-	d.ProductSerial, _, _ = key.GetStringValue("SystemSerialNumber")
-
-	return nil
-}
-
 // collectSecurityInfo 收集安全相关信息
 func (d *DeviceInfo) collectSecurityInfo() error {
 	// 检测是否为虚拟机
@@ -756,42 +455,12 @@ func (d *DeviceInfo) collectSecurityInfo() error {
 	return nil
 }
 
-// detectVirtualMachine 检测是否为虚拟机
+// detectVirtualMachine 检测是否为虚拟机、模拟器、容器或 WSL，保留各项证据供服务器复核
 func (d *DeviceInfo) detectVirtualMachine() {
-	// 检查常见虚拟机特征
-
-	// 1. 检查制造商信息
-	vmManufacturers := []string{"VMware", "VirtualBox", "KVM", "QEMU", "Xen", "Parallels", "Virtual Machine"}
-	for _, vm := range vmManufacturers {
-		if strings.Contains(strings.ToLower(d.MotherboardInfo.Manufacturer), strings.ToLower(vm)) ||
-			strings.Contains(strings.ToLower(d.ProductVendor), strings.ToLower(vm)) {
-			d.IsVirtualMachine = true
-			return
-		}
-	}
-
-	// 2. 检查产品名称
-	if strings.Contains(strings.ToLower(d.ProductName), "virtual") {
-		d.IsVirtualMachine = true
-		return
-	}
-
-	// 3. 通过特定设备检测
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Enum\PCI`, registry.READ)
-	if err == nil {
-		defer key.Close()
-		subKeys, _ := key.ReadSubKeyNames(-1)
-		for _, subKey := range subKeys {
-			if strings.Contains(strings.ToLower(subKey), "vmware") ||
-				strings.Contains(strings.ToLower(subKey), "virtualbox") {
-				d.IsVirtualMachine = true
-				return
-			}
-		}
-	}
-
-	// 默认为物理机
-	d.IsVirtualMachine = false
+	report := DetectVirtualization()
+	d.IsVirtualMachine = report.IsVirtual
+	d.VirtualScore = report.Score
+	d.VirtualEvidence = report.Evidence
 }
 
 // FormatDeviceInfo 格式化设备信息为可读字符串
@@ -807,6 +476,7 @@ func FormatDeviceInfo(info *DeviceInfo) string {
 	// 设备唯一标识
 	sb.WriteString(fmt.Sprintf("设备唯一标识: %s\n", info.UniqueID))
 	sb.WriteString(fmt.Sprintf("机器ID: %s\n", info.MachineID))
+	sb.WriteString(fmt.Sprintf("指纹版本: %d (%d 个组成部分)\n", info.Fingerprint.Version, len(info.Fingerprint.Components)))
 	if info.BIOSUUID != "" {
 		sb.WriteString(fmt.Sprintf("BIOS UUID: %s\n", info.BIOSUUID))
 	}
@@ -884,7 +554,10 @@ func FormatDeviceInfo(info *DeviceInfo) string {
 
 	// 安全信息
 	sb.WriteString("安全信息:\n")
-	sb.WriteString(fmt.Sprintf("  设备类型: %s\n", formatVirtualStatus(info.IsVirtualMachine)))
+	sb.WriteString(fmt.Sprintf("  设备类型: %s (虚拟化评分 %d)\n", formatVirtualStatus(info.IsVirtualMachine), info.VirtualScore))
+	for _, evidence := range info.VirtualEvidence {
+		sb.WriteString(fmt.Sprintf("    [%s] %s (+%d)\n", evidence.Signal, evidence.Detail, evidence.Weight))
+	}
 
 	return sb.String()
 }
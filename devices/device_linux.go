@@ -0,0 +1,302 @@
+package devices
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// hostRoot 读取 /sys、/proc、/etc 时的根目录，便于指向伪造的目录树
+var hostRoot = "/"
+
+// hostPath 拼接根目录下的路径
+func hostPath(path ...string) string {
+	return filepath.Join(append([]string{hostRoot}, path...)...)
+}
+
+// dmiDir BIOS、主板和整机信息所在目录，序列号和 UUID 等字段通常只有 root 可读
+// ARM 开发板和部分容器没有 DMI，此时相关字段留空而不视为错误
+const dmiDir = "sys/class/dmi/id"
+
+// pciIDFiles lspci 使用的 PCI 厂商和设备名称数据库，按发行版常见位置依次查找
+var pciIDFiles = []string{
+	"usr/share/hwdata/pci.ids",
+	"usr/share/misc/pci.ids",
+	"usr/share/pci.ids",
+}
+
+// knownPCIVendors 没有 pci.ids 时使用的常见显卡和虚拟化厂商
+var knownPCIVendors = map[string]string{
+	"1002": "Advanced Micro Devices, Inc. [AMD/ATI]",
+	"10de": "NVIDIA Corporation",
+	"8086": "Intel Corporation",
+	"15ad": "VMware",
+	"80ee": "InnoTek Systemberatung GmbH",
+	"1af4": "Red Hat, Inc.",
+	"1234": "QEMU",
+	"1414": "Microsoft Corporation",
+}
+
+// collectProductID 读取 systemd/dbus 生成的 machine-id，作为系统安装标识
+func (d *DeviceInfo) collectProductID() {
+	for _, path := range []string{"etc/machine-id", "var/lib/dbus/machine-id"} {
+		if id := readSysfs(hostRoot, path); id != "" {
+			d.ProductID = id
+			return
+		}
+	}
+}
+
+// collectBIOSUUID 获取BIOS UUID，非 root 用户通常无权读取
+func (d *DeviceInfo) collectBIOSUUID() {
+	d.BIOSUUID = readSysfs(hostPath(dmiDir), "product_uuid")
+}
+
+// getCPUID 获取CPU型号和 family/model/stepping
+func getCPUID() string {
+	file, err := os.Open(hostPath("proc/cpuinfo"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// 只读取第一个处理器
+		if line == "" && len(fields) > 0 {
+			break
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	name := fields["model name"]
+	if name == "" {
+		name = fields["Hardware"] // ARM
+	}
+	family, model, stepping := fields["cpu family"], fields["model"], fields["stepping"]
+	// 无法读取或没有型号时返回空，避免不同设备得到相同的标识
+	if name == "" {
+		return ""
+	}
+	// ARM 等架构没有 family/model/stepping，只使用型号名称
+	if family == "" || model == "" || stepping == "" {
+		return name
+	}
+	return fmt.Sprintf("%s-%s.%s.%s", name, family, model, stepping)
+}
+
+// getPhysicalDiskSerials 获取固定磁盘序列号，忽略可移动设备和虚拟块设备
+func getPhysicalDiskSerials() []string {
+	entries, err := os.ReadDir(hostPath("sys/block"))
+	if err != nil {
+		return nil
+	}
+
+	var serials []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") ||
+			strings.HasPrefix(name, "dm-") || strings.HasPrefix(name, "zram") {
+			continue
+		}
+		dir := hostPath("sys/block", name)
+		if readSysfs(dir, "removable") == "1" {
+			continue
+		}
+
+		serial := readSysfs(dir, "device/serial")
+		if serial == "" {
+			serial = readSysfs(dir, "device/wwid")
+		}
+		if serial != "" {
+			serials = append(serials, serial)
+		}
+	}
+	sort.Strings(serials)
+	return serials
+}
+
+// getNetworkInterfaceTypeAndSpeed 获取网络接口类型和速度(bps)
+func getNetworkInterfaceTypeAndSpeed(interfaceName string) (string, uint64) {
+	dir := hostPath("sys/class/net", interfaceName)
+	if _, err := os.Stat(dir); err != nil {
+		return "未知", 0
+	}
+
+	mediaType := "有线"
+	if _, err := os.Stat(filepath.Join(dir, "wireless")); err == nil {
+		mediaType = "无线"
+	} else if _, err := os.Stat(filepath.Join(dir, "phy80211")); err == nil {
+		mediaType = "无线"
+	} else if strings.HasPrefix(interfaceName, "bnep") {
+		mediaType = "蓝牙"
+	} else if _, err := os.Stat(filepath.Join(dir, "device")); err != nil {
+		mediaType = "虚拟"
+	}
+
+	// speed 单位为 Mbps，接口未连接时读取失败或为 -1
+	var speed uint64
+	if mbps, err := strconv.ParseInt(readSysfs(dir, "speed"), 10, 64); err == nil && mbps > 0 {
+		speed = uint64(mbps) * 1000 * 1000
+	}
+	return mediaType, speed
+}
+
+// collectGPUInfo 从 /sys/class/drm 读取显卡，名称通过 pci.ids 解析
+func (d *DeviceInfo) collectGPUInfo() error {
+	cards, err := filepath.Glob(hostPath("sys/class/drm", "card*"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(cards)
+
+	for _, card := range cards {
+		// card0-HDMI-A-1 等为显示接口，不是显卡
+		if strings.Contains(filepath.Base(card), "-") {
+			continue
+		}
+		device := filepath.Join(card, "device")
+		vendorID := strings.TrimPrefix(readSysfs(device, "vendor"), "0x")
+		deviceID := strings.TrimPrefix(readSysfs(device, "device"), "0x")
+		if vendorID == "" {
+			continue
+		}
+
+		vendor, name := lookupPCIName(vendorID, deviceID)
+		gpu := GPUInfo{Name: name, Vendor: vendor}
+
+		if driver, err := os.Readlink(filepath.Join(device, "driver")); err == nil {
+			driverName := filepath.Base(driver)
+			gpu.DriverVer = driverName
+			if version := readSysfs(hostPath("sys/module", driverName), "version"); version != "" {
+				gpu.DriverVer = driverName + " " + version
+			}
+		}
+		// amdgpu 提供显存大小，其他驱动没有统一的接口
+		if vram, err := strconv.ParseUint(readSysfs(device, "mem_info_vram_total"), 10, 64); err == nil {
+			gpu.Memory = vram
+		}
+
+		d.GPUInfo = append(d.GPUInfo, gpu)
+	}
+	return nil
+}
+
+// collectBIOSInfo 收集BIOS信息
+func (d *DeviceInfo) collectBIOSInfo() error {
+	dir := hostPath(dmiDir)
+	d.BIOSVendor = readSysfs(dir, "bios_vendor")
+	d.BIOSVersion = readSysfs(dir, "bios_version")
+	d.BIOSDate = readSysfs(dir, "bios_date")
+	return nil
+}
+
+// collectMotherboardInfo 收集主板信息
+func (d *DeviceInfo) collectMotherboardInfo() error {
+	dir := hostPath(dmiDir)
+	d.MotherboardInfo.Manufacturer = readSysfs(dir, "board_vendor")
+	d.MotherboardInfo.Product = readSysfs(dir, "board_name")
+	d.MotherboardInfo.SerialNumber = readSysfs(dir, "board_serial")
+	d.MotherboardInfo.Version = readSysfs(dir, "board_version")
+	return nil
+}
+
+// collectProductInfo 收集产品信息
+func (d *DeviceInfo) collectProductInfo() error {
+	dir := hostPath(dmiDir)
+	d.ProductName = readSysfs(dir, "product_name")
+	d.ProductVendor = readSysfs(dir, "sys_vendor")
+	d.ProductVersion = readSysfs(dir, "product_version")
+	d.ProductSerial = readSysfs(dir, "product_serial")
+	return nil
+}
+
+// isRemovablePartition 判断分区所在磁盘是否可移动，USB 接入的移动硬盘 removable 可能为0，也视为可移动
+func isRemovablePartition(partition disk.PartitionStat) bool {
+	if !strings.HasPrefix(partition.Device, "/dev/") {
+		return false
+	}
+	dir, err := filepath.EvalSymlinks(hostPath("sys/class/block", filepath.Base(partition.Device)))
+	if err != nil {
+		return false
+	}
+	if _, err = os.Stat(filepath.Join(dir, "partition")); err == nil {
+		dir = filepath.Dir(dir)
+	}
+	return readSysfs(dir, "removable") == "1" || strings.Contains(dir, "/usb")
+}
+
+var (
+	pciNamesOnce sync.Once
+	pciVendors   map[string]string
+	pciDevices   map[string]string // 键为 "vendor:device"
+)
+
+// lookupPCIName 按 lspci 的方式将PCI ID解析为厂商和设备名称
+func lookupPCIName(vendorID, deviceID string) (vendor, device string) {
+	pciNamesOnce.Do(loadPCINames)
+
+	vendor = pciVendors[vendorID]
+	if vendor == "" {
+		vendor = knownPCIVendors[vendorID]
+	}
+	device = pciDevices[vendorID+":"+deviceID]
+	if device == "" {
+		device = fmt.Sprintf("PCI %s:%s", vendorID, deviceID)
+	}
+	if vendor == "" {
+		vendor = vendorID
+	}
+	return vendor, device
+}
+
+// loadPCINames 解析 pci.ids，厂商行无缩进，设备行以一个制表符缩进，子系统行以两个制表符缩进
+func loadPCINames() {
+	pciVendors = make(map[string]string)
+	pciDevices = make(map[string]string)
+
+	for _, path := range pciIDFiles {
+		file, err := os.Open(hostPath(path))
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+
+		var vendor string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" || line[0] == '#' {
+				continue
+			}
+			// 设备类别定义在文件末尾，之后不再有厂商
+			if strings.HasPrefix(line, "C ") {
+				break
+			}
+			switch {
+			case strings.HasPrefix(line, "\t\t"):
+			case line[0] == '\t':
+				if id, name, ok := strings.Cut(line[1:], "  "); ok && vendor != "" {
+					pciDevices[vendor+":"+id] = name
+				}
+			default:
+				if id, name, ok := strings.Cut(line, "  "); ok {
+					vendor = id
+					pciVendors[id] = name
+				}
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,206 @@
+package devices
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// fakeHostRoot 将 hostRoot 指向伪造的目录树，测试结束后恢复，并清空已加载的 pci.ids
+func fakeHostRoot(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	writeTree(t, root, files)
+
+	previous := hostRoot
+	hostRoot = root
+	pciNamesOnce = sync.Once{}
+	t.Cleanup(func() {
+		hostRoot = previous
+		pciNamesOnce = sync.Once{}
+	})
+	return root
+}
+
+func TestCollectDMI(t *testing.T) {
+	fakeHostRoot(t, map[string]string{
+		dmiDir + "/bios_vendor":     "LENOVO",
+		dmiDir + "/bios_version":    "N2HET70W (1.53 )",
+		dmiDir + "/bios_date":       "02/08/2023",
+		dmiDir + "/board_vendor":    "LENOVO",
+		dmiDir + "/board_name":      "20QDCTO1WW",
+		dmiDir + "/board_serial":    "L1HF0AB00CD",
+		dmiDir + "/board_version":   "SDK0J40697 WIN",
+		dmiDir + "/product_name":    "20QDCTO1WW",
+		dmiDir + "/sys_vendor":      "LENOVO",
+		dmiDir + "/product_version": "ThinkPad X1 Carbon 7th",
+		dmiDir + "/product_serial":  "PF1ABCDE",
+		dmiDir + "/product_uuid":    "3b1f2c4e-5d6a-11b2-a85c-c3d4e5f60718",
+	})
+
+	var d DeviceInfo
+	_ = d.collectBIOSInfo()
+	_ = d.collectMotherboardInfo()
+	_ = d.collectProductInfo()
+	d.collectBIOSUUID()
+
+	if d.BIOSVendor != "LENOVO" || d.BIOSVersion != "N2HET70W (1.53 )" || d.BIOSDate != "02/08/2023" {
+		t.Errorf("BIOS = %q %q %q", d.BIOSVendor, d.BIOSVersion, d.BIOSDate)
+	}
+	want := MotherboardInfo{Manufacturer: "LENOVO", Product: "20QDCTO1WW", SerialNumber: "L1HF0AB00CD", Version: "SDK0J40697 WIN"}
+	if d.MotherboardInfo != want {
+		t.Errorf("MotherboardInfo = %+v, want %+v", d.MotherboardInfo, want)
+	}
+	if d.ProductName != "20QDCTO1WW" || d.ProductVendor != "LENOVO" || d.ProductVersion != "ThinkPad X1 Carbon 7th" || d.ProductSerial != "PF1ABCDE" {
+		t.Errorf("Product = %q %q %q %q", d.ProductName, d.ProductVendor, d.ProductVersion, d.ProductSerial)
+	}
+	if d.BIOSUUID != "3b1f2c4e-5d6a-11b2-a85c-c3d4e5f60718" {
+		t.Errorf("BIOSUUID = %q", d.BIOSUUID)
+	}
+}
+
+// ARM 开发板和容器没有 DMI，相关字段留空
+func TestCollectDMIMissing(t *testing.T) {
+	fakeHostRoot(t, nil)
+
+	var d DeviceInfo
+	if err := d.collectBIOSInfo(); err != nil {
+		t.Errorf("collectBIOSInfo() = %v", err)
+	}
+	_ = d.collectMotherboardInfo()
+	d.collectBIOSUUID()
+	if d.BIOSVendor != "" || d.MotherboardInfo != (MotherboardInfo{}) || d.BIOSUUID != "" {
+		t.Errorf("没有 DMI 时字段应为空: %+v", d)
+	}
+}
+
+// pciIDs 截取自 pci.ids 的片段，包含子系统行和文件末尾的设备类别
+const pciIDs = `# List of PCI ID's
+#
+8086  Intel Corporation
+	3e9b  CoffeeLake-H GT2 [UHD Graphics 630]
+		17aa 2280  ThinkPad X1 Extreme
+	9a49  TigerLake-LP GT2 [Iris Xe Graphics]
+1002  Advanced Micro Devices, Inc. [AMD/ATI]
+	73bf  Navi 21 [Radeon RX 6800/6800 XT / 6900 XT]
+C 03  Display controller
+	00  VGA compatible controller
+`
+
+func TestCollectGPUInfo(t *testing.T) {
+	root := fakeHostRoot(t, map[string]string{
+		"usr/share/misc/pci.ids": pciIDs,
+
+		"sys/class/drm/card0/device/vendor":              "0x8086",
+		"sys/class/drm/card0/device/device":              "0x9a49",
+		"sys/class/drm/card0-eDP-1/status":               "connected",
+		"sys/class/drm/card1/device/vendor":              "0x1002",
+		"sys/class/drm/card1/device/device":              "0x73bf",
+		"sys/class/drm/card1/device/mem_info_vram_total": "17163091968",
+		"sys/class/drm/card1-HDMI-A-1/status":            "disconnected",
+		"sys/class/drm/card2/device/vendor":              "0x10de",
+		"sys/class/drm/card2/device/device":              "0x2684",
+		"sys/class/drm/renderD128/dev":                   "226:128",
+		"sys/module/amdgpu/version":                      "6.1.0",
+		"sys/bus/pci/drivers/i915":                       "",
+		"sys/bus/pci/drivers/amdgpu":                     "",
+	})
+	for card, driver := range map[string]string{"card0": "i915", "card1": "amdgpu"} {
+		link := filepath.Join(root, "sys/class/drm", card, "device", "driver")
+		if err := os.Symlink(filepath.Join("../../../../bus/pci/drivers", driver), link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var d DeviceInfo
+	if err := d.collectGPUInfo(); err != nil {
+		t.Fatal(err)
+	}
+	want := []GPUInfo{
+		{Name: "TigerLake-LP GT2 [Iris Xe Graphics]", Vendor: "Intel Corporation", DriverVer: "i915"},
+		{Name: "Navi 21 [Radeon RX 6800/6800 XT / 6900 XT]", Vendor: "Advanced Micro Devices, Inc. [AMD/ATI]", DriverVer: "amdgpu 6.1.0", Memory: 17163091968},
+		// 不在 pci.ids 中的设备使用内置厂商名称和原始ID
+		{Name: "PCI 10de:2684", Vendor: "NVIDIA Corporation"},
+	}
+	if !reflect.DeepEqual(d.GPUInfo, want) {
+		t.Errorf("GPUInfo =\n%+v\nwant\n%+v", d.GPUInfo, want)
+	}
+}
+
+func TestLookupPCINameWithoutDatabase(t *testing.T) {
+	fakeHostRoot(t, nil)
+
+	tests := []struct {
+		vendorID, deviceID string
+		vendor, device     string
+	}{
+		{"15ad", "0405", "VMware", "PCI 15ad:0405"},
+		{"1234", "1111", "QEMU", "PCI 1234:1111"},
+		{"abcd", "0001", "abcd", "PCI abcd:0001"},
+	}
+	for _, tt := range tests {
+		vendor, device := lookupPCIName(tt.vendorID, tt.deviceID)
+		if vendor != tt.vendor || device != tt.device {
+			t.Errorf("lookupPCIName(%s, %s) = %q, %q, want %q, %q", tt.vendorID, tt.deviceID, vendor, device, tt.vendor, tt.device)
+		}
+	}
+}
+
+func TestCollectProductID(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"systemd", map[string]string{
+			"etc/machine-id":          "4b3f0c1d2e5a4f6b8c9d0e1f2a3b4c5d",
+			"var/lib/dbus/machine-id": "00000000000000000000000000000000",
+		}, "4b3f0c1d2e5a4f6b8c9d0e1f2a3b4c5d"},
+		{"dbus", map[string]string{
+			"var/lib/dbus/machine-id": "9f8e7d6c5b4a39281706f5e4d3c2b1a0",
+		}, "9f8e7d6c5b4a39281706f5e4d3c2b1a0"},
+		{"missing", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeHostRoot(t, tt.files)
+			var d DeviceInfo
+			d.collectProductID()
+			if d.ProductID != tt.want {
+				t.Errorf("ProductID = %q, want %q", d.ProductID, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCPUID(t *testing.T) {
+	tests := []struct {
+		name    string
+		cpuinfo string
+		want    string
+	}{
+		{"x86", "processor\t: 0\nvendor_id\t: GenuineIntel\ncpu family\t: 6\nmodel\t\t: 142\n" +
+			"model name\t: Intel(R) Core(TM) i7-8565U CPU @ 1.80GHz\nstepping\t: 12\n\n" +
+			"processor\t: 1\nmodel name\t: other\n",
+			"Intel(R) Core(TM) i7-8565U CPU @ 1.80GHz-6.142.12"},
+		{"arm", "processor\t: 0\nmodel name\t: ARMv7 Processor rev 4 (v7l)\nBogoMIPS\t: 38.40\n",
+			"ARMv7 Processor rev 4 (v7l)"},
+		{"no-model", "processor\t: 0\nBogoMIPS\t: 108.00\nCPU implementer\t: 0x41\n", ""},
+		{"processor-only", "processor\t: 0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeHostRoot(t, map[string]string{"proc/cpuinfo": tt.cpuinfo})
+			if got := getCPUID(); got != tt.want {
+				t.Errorf("getCPUID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	fakeHostRoot(t, nil)
+	if got := getCPUID(); got != "" {
+		t.Errorf("没有 /proc/cpuinfo 时 getCPUID() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,103 @@
+package devices
+
+import (
+	"runtime"
+	"testing"
+)
+
+// GetDeviceInfo 在任何平台上都应返回基本信息和可用的设备指纹
+func TestGetDeviceInfo(t *testing.T) {
+	fakeConfigDir(t)
+	reset := func() {
+		saltMu.Lock()
+		fingerprintSalt = ""
+		saltMu.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+
+	d, err := GetDeviceInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Hostname == "" || d.OS != runtime.GOOS || d.KernelArch == "" {
+		t.Errorf("系统信息 = %q %q %q", d.Hostname, d.OS, d.KernelArch)
+	}
+	if d.CPUCores <= 0 || d.MemTotal == 0 {
+		t.Errorf("CPUCores = %d, MemTotal = %d", d.CPUCores, d.MemTotal)
+	}
+
+	fp := d.Fingerprint
+	if fp.Version != FingerprintVersion || fp.ID == "" || len(fp.Components) == 0 {
+		t.Fatalf("Fingerprint = %+v", fp)
+	}
+	if d.UniqueID != fp.ID || d.MachineID != fp.ID {
+		t.Errorf("UniqueID = %q, MachineID = %q, want %q", d.UniqueID, d.MachineID, fp.ID)
+	}
+	if fp.salt != saltDigest(defaultFingerprintSalt) {
+		t.Errorf("未下发盐值时应使用默认盐值")
+	}
+	for name := range fp.Components {
+		found := false
+		for _, component := range fingerprintComponents {
+			found = found || component == name
+		}
+		if !found {
+			t.Errorf("未知的指纹组成部分 %s", name)
+		}
+	}
+
+	// 重新计算不重新采集硬件信息，结果不变
+	again := d.FingerprintWithSalt(FingerprintSalt())
+	if again.ID != fp.ID || again.LegacyID != fp.LegacyID {
+		t.Errorf("FingerprintWithSalt() = %+v, want %+v", again, fp)
+	}
+	if other := d.FingerprintWithSalt("server-salt"); other.ID == fp.ID {
+		t.Error("不同盐值的指纹应不同")
+	}
+}
+
+func TestIsPlaceholderIdentifier(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", true},
+		{"0", true},
+		{"none", true},
+		{"to be filled by o.e.m.", true},
+		{"default string", true},
+		{"system serial number", true},
+		{"00000000-0000-0000-0000-000000000000", true},
+		{"ffffffff-ffff-ffff-ffff-ffffffffffff", true},
+		{"03000200-0400-0500-0006-000700080009", true},
+		{"pf1abcde", false},
+		{"3b1f2c4e-5d6a-11b2-a85c-c3d4e5f60718", false},
+		// 只识别规范化后的值
+		{"To be filled by O.E.M.", false},
+	}
+	for _, tt := range tests {
+		if got := isPlaceholderIdentifier(tt.value); got != tt.want {
+			t.Errorf("isPlaceholderIdentifier(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeIdentifier(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"  PF1ABCDE ", "pf1abcde"},
+		{"To be filled by O.E.M.", ""},
+		{" None , N/A ", ""},
+		// 多值标识去掉占位值并排序
+		{"WD-WX11A12B3456, Default String, S4EWNX0N123456", "s4ewnx0n123456,wd-wx11a12b3456"},
+		{"Intel(R)  Core(TM)\ti7", "intel(r) core(tm) i7"},
+	}
+	for _, tt := range tests {
+		if got := normalizeIdentifier(tt.value); got != tt.want {
+			t.Errorf("normalizeIdentifier(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
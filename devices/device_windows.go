@@ -0,0 +1,304 @@
+package devices
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// collectProductID 获取Windows产品ID
+func (d *DeviceInfo) collectProductID() {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows NT\CurrentVersion`, registry.READ)
+	if err != nil {
+		return
+	}
+	defer key.Close()
+
+	// 尝试读取产品ID
+	productID, _, _ := key.GetStringValue("ProductId")
+	if productID == "" {
+		// 备用方案：尝试读取数字产品ID
+		digitalID, _, _ := key.GetBinaryValue("DigitalProductId")
+		if len(digitalID) > 0 {
+			// 将数字产品ID转换为字符串表示
+			d.ProductID = fmt.Sprintf("%x", digitalID)
+		}
+	} else {
+		d.ProductID = productID
+	}
+}
+
+// collectBIOSUUID 获取BIOS UUID
+func (d *DeviceInfo) collectBIOSUUID() {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\BIOS`, registry.READ)
+	if err != nil {
+		return
+	}
+	defer key.Close()
+
+	// 读取BIOS UUID
+	uuid, _, _ := key.GetStringValue("SystemUUID")
+	if uuid == "" {
+		// 备用方案：尝试读取系统产品UUID
+		uuid, _, _ = key.GetStringValue("UUID")
+	}
+
+	d.BIOSUUID = uuid
+}
+
+// getCPUID 获取CPU ID
+func getCPUID() string {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\CentralProcessor\0`, registry.READ)
+	if err != nil {
+		return ""
+	}
+	defer key.Close()
+
+	// 读取处理器ID
+	processorID, _, _ := key.GetStringValue("ProcessorNameString")
+	if processorID == "" {
+		processorID, _, _ = key.GetStringValue("Identifier")
+	}
+
+	// 读取特征码
+	featureID, _, _ := key.GetIntegerValue("FeatureSet")
+
+	return fmt.Sprintf("%s-%d", processorID, featureID)
+}
+
+// getPhysicalDiskSerials 获取物理磁盘序列号
+func getPhysicalDiskSerials() []string {
+	var serials []string
+
+	// 使用WMI查询物理磁盘序列号
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DEVICEMAP\Scsi`, registry.READ)
+	if err != nil {
+		return serials
+	}
+	defer key.Close()
+
+	// 获取所有SCSI控制器
+	controllers, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return serials
+	}
+
+	// 遍历所有控制器和设备
+	for _, controller := range controllers {
+		controllerKey, err := registry.OpenKey(key, controller, registry.READ)
+		if err != nil {
+			continue
+		}
+		defer controllerKey.Close()
+
+		// 获取设备
+		devices, err := controllerKey.ReadSubKeyNames(-1)
+		if err != nil {
+			continue
+		}
+
+		for _, device := range devices {
+			deviceKey, err := registry.OpenKey(controllerKey, device, registry.READ)
+			if err != nil {
+				continue
+			}
+			defer deviceKey.Close()
+
+			// 读取设备标识符和序列号
+			identifier, _, _ := deviceKey.GetStringValue("Identifier")
+			serialNumber, _, _ := deviceKey.GetStringValue("SerialNumber")
+
+			if serialNumber != "" {
+				serials = append(serials, serialNumber)
+			} else if identifier != "" {
+				serials = append(serials, identifier)
+			}
+		}
+	}
+
+	// 如果使用注册表方法没有找到，尝试使用WMIC命令获取
+	if len(serials) == 0 {
+		// 尝试使用固定一些已知信息(由于WMIC命令执行比较复杂，这里简化处理)
+		key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\BIOS`, registry.READ)
+		if err == nil {
+			defer key.Close()
+			diskSerial, _, _ := key.GetStringValue("DiskSerialNumber")
+			if diskSerial != "" {
+				serials = append(serials, diskSerial)
+			}
+		}
+	}
+
+	return serials
+}
+
+// getNetworkInterfaceTypeAndSpeed 获取网络接口类型和速度
+func getNetworkInterfaceTypeAndSpeed(interfaceName string) (string, uint64) {
+	// 尝试从注册表获取接口类型和速度
+	keyPath := fmt.Sprintf(`SYSTEM\CurrentControlSet\Control\Network\{4D36E972-E325-11CE-BFC1-08002BE10318}`)
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.READ)
+	if err != nil {
+		return "未知", 0
+	}
+	defer key.Close()
+
+	// 遍历子键查找匹配的接口
+	subKeys, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return "未知", 0
+	}
+
+	for _, subKey := range subKeys {
+		connectionKey, err := registry.OpenKey(key, subKey+`\Connection`, registry.READ)
+		if err != nil {
+			continue
+		}
+		defer connectionKey.Close()
+
+		// 检查接口名称
+		name, _, err := connectionKey.GetStringValue("Name")
+		if err != nil || name != interfaceName {
+			continue
+		}
+
+		// 获取接口类型
+		mediaType := "有线"
+		if strings.Contains(strings.ToLower(name), "wi-fi") || strings.Contains(strings.ToLower(name), "wireless") {
+			mediaType = "无线"
+		} else if strings.Contains(strings.ToLower(name), "bluetooth") {
+			mediaType = "蓝牙"
+		}
+
+		// 获取接口速度
+		var speed uint64 = 0
+		speedValue, _, err := connectionKey.GetIntegerValue("Speed")
+		if err == nil {
+			speed = speedValue
+		}
+
+		return mediaType, speed
+	}
+
+	return "未知", 0
+}
+
+// collectGPUInfo 收集显卡信息
+func (d *DeviceInfo) collectGPUInfo() error {
+	// 从注册表获取显卡信息
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control\Class\{4d36e968-e325-11ce-bfc1-08002be10318}`, registry.READ)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	// 遍历子键查找显卡
+	subKeys, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return err
+	}
+
+	for _, subKey := range subKeys {
+		if subKey == "Properties" {
+			continue
+		}
+
+		gpuKey, err := registry.OpenKey(key, subKey, registry.READ)
+		if err != nil {
+			continue
+		}
+		defer gpuKey.Close()
+
+		// 读取显卡信息
+		driverDesc, _, err := gpuKey.GetStringValue("DriverDesc")
+		if err != nil {
+			continue
+		}
+
+		// 如果能找到驱动描述，说明是显卡
+		gpuInfo := GPUInfo{
+			Name: driverDesc,
+		}
+
+		// 尝试获取其他信息
+		gpuInfo.Vendor, _, _ = gpuKey.GetStringValue("ProviderName")
+		gpuInfo.DriverVer, _, _ = gpuKey.GetStringValue("DriverVersion")
+		gpuInfo.DriverDate, _, _ = gpuKey.GetStringValue("DriverDate")
+
+		// 获取显存
+		memoryValue, _, err := gpuKey.GetIntegerValue("HardwareInformation.MemorySize")
+		if err == nil {
+			gpuInfo.Memory = memoryValue
+		}
+
+		d.GPUInfo = append(d.GPUInfo, gpuInfo)
+	}
+
+	return nil
+}
+
+// collectBIOSInfo 收集BIOS信息
+func (d *DeviceInfo) collectBIOSInfo() error {
+	// 使用WMI查询BIOS信息
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\BIOS`, registry.READ)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	// 读取BIOS信息
+	d.BIOSVendor, _, _ = key.GetStringValue("BIOSVendor")
+	d.BIOSVersion, _, _ = key.GetStringValue("BIOSVersion")
+	d.BIOSDate, _, _ = key.GetStringValue("BIOSReleaseDate")
+
+	return nil
+}
+
+// collectMotherboardInfo 收集主板信息
+func (d *DeviceInfo) collectMotherboardInfo() error {
+	// 从注册表获取主板信息
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\BIOS`, registry.READ)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	d.MotherboardInfo.Manufacturer, _, _ = key.GetStringValue("BaseBoardManufacturer")
+	d.MotherboardInfo.Product, _, _ = key.GetStringValue("BaseBoardProduct")
+	d.MotherboardInfo.SerialNumber, _, _ = key.GetStringValue("BaseBoardSerialNumber")
+	d.MotherboardInfo.Version, _, _ = key.GetStringValue("BaseBoardVersion")
+
+	return nil
+}
+
+// collectProductInfo 收集产品信息
+func (d *DeviceInfo) collectProductInfo() error {
+	// 从注册表获取产品信息
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\BIOS`, registry.READ)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	d.ProductName, _, _ = key.GetStringValue("SystemProductName")
+	d.ProductVendor, _, _ = key.GetStringValue("SystemManufacturer")
+	d.ProductVersion, _, _ = key.GetStringValue("SystemVersion")
+	d.ProductSerial, _, _ = key.GetStringValue("SystemSerialNumber")
+
+	return nil
+}
+
+// isRemovablePartition 判断分区所在驱动器是否为可移动设备
+func isRemovablePartition(partition disk.PartitionStat) bool {
+	if !strings.HasPrefix(partition.Device, "\\\\.\\") {
+		return false
+	}
+	drive := partition.Mountpoint
+	if len(drive) >= 2 && drive[1] == ':' {
+		driveType := windows.GetDriveType(windows.StringToUTF16Ptr(drive + "\\"))
+		return driveType == windows.DRIVE_REMOVABLE
+	}
+	return false
+}
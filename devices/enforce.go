@@ -0,0 +1,224 @@
+package devices
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// EnforceMode USB存储设备管控方式
+type EnforceMode string
+
+const (
+	EnforceOff         EnforceMode = ""            // 只检测不管控
+	EnforceUnmount     EnforceMode = "unmount"     // 卸载新接入存储设备的所有挂载点
+	EnforceReadOnly    EnforceMode = "readonly"    // 将挂载点重新挂载为只读
+	EnforceDeauthorize EnforceMode = "deauthorize" // 通过 sysfs authorized 禁用整个USB设备
+)
+
+// 管控动作，均会通过审计回调上报
+const (
+	ActionUnmount     = "unmount"
+	ActionReadOnly    = "remount-readonly"
+	ActionDeauthorize = "deauthorize"
+	ActionRestoreRW   = "restore-readwrite"
+	ActionReauthorize = "reauthorize"
+)
+
+// ErrEnforceUnsupported 当前平台不支持USB存储管控
+var ErrEnforceUnsupported = errors.New("当前平台不支持USB存储设备管控")
+
+// EnforceAction 一次管控操作的审计记录，Err 为空表示成功
+type EnforceAction struct {
+	Action  string    `json:"action"`
+	Device  USBDevice `json:"device"`
+	Target  string    `json:"target"`            // 挂载点或 sysfs 设备目录
+	Options string    `json:"options,omitempty"` // 设为只读前的挂载选项，恢复时按原选项重新挂载
+	Time    time.Time `json:"time"`
+	Err     error     `json:"-"`
+}
+
+// enforceStateFileName 尚未恢复的管控操作记录，程序异常退出后在下次启动时恢复
+const enforceStateFileName = "usb-enforce.json"
+
+// AuditCallback 管控操作审计回调
+type AuditCallback func(action EnforceAction)
+
+// Enforcer 考试期间阻止新接入的USB存储设备被使用，考试结束后恢复
+// 只处理 Activate 之后接入的设备，考试开始前已接入的设备由检测结果上报
+type Enforcer struct {
+	Mode    EnforceMode
+	OnAudit AuditCallback
+
+	mu     sync.Mutex
+	active bool
+	done   []EnforceAction // 成功执行且需要恢复的操作
+}
+
+// NewEnforcer 创建USB存储管控器
+func NewEnforcer(mode EnforceMode, onAudit AuditCallback) *Enforcer {
+	return &Enforcer{Mode: mode, OnAudit: onAudit}
+}
+
+// Activate 考试开始，之后接入的存储设备会被管控
+func (e *Enforcer) Activate() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.active = e.Mode != EnforceOff
+}
+
+// Handle 处理设备监听器的事件，应在 Watcher 的回调中调用
+// 挂载点可能晚于设备接入出现，因此挂载点变化时也会再次执行卸载或只读
+func (e *Enforcer) Handle(event DeviceEvent) {
+	device := event.Device
+	if !device.IsStorageType || event.Type == DeviceRemoved {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.active {
+		return
+	}
+
+	switch e.Mode {
+	case EnforceDeauthorize:
+		if event.Type == DeviceAdded {
+			action := EnforceAction{Action: ActionDeauthorize, Device: device, Target: device.SysPath}
+			e.apply(action, setAuthorized(device.SysPath, false))
+		}
+	case EnforceUnmount:
+		for _, mountPoint := range device.DriveLetters {
+			action := EnforceAction{Action: ActionUnmount, Device: device, Target: mountPoint}
+			e.apply(action, unmount(mountPoint))
+		}
+	case EnforceReadOnly:
+		for _, mountPoint := range device.DriveLetters {
+			if e.applied(ActionReadOnly, mountPoint) {
+				continue
+			}
+			options, err := mountOptions(mountPoint)
+			if err == nil {
+				err = remount(mountPoint, options, true)
+			}
+			action := EnforceAction{Action: ActionReadOnly, Device: device, Target: mountPoint, Options: options}
+			e.apply(action, err)
+		}
+	}
+}
+
+// Restore 考试结束，恢复被设为只读或被禁用的设备，被卸载的设备由用户重新挂载
+func (e *Enforcer) Restore() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.active = false
+
+	done := e.done
+	e.done = nil
+	for i := len(done) - 1; i >= 0; i-- {
+		action := done[i]
+		switch action.Action {
+		case ActionReadOnly:
+			e.audit(ActionRestoreRW, action.Device, action.Target, remount(action.Target, action.Options, false))
+		case ActionDeauthorize:
+			e.audit(ActionReauthorize, action.Device, action.Target, setAuthorized(action.Target, true))
+		}
+	}
+	e.saveState()
+}
+
+// RestorePending 恢复上次运行中未恢复的管控操作，程序在考试中崩溃或被结束时只读和禁用状态会一直保留
+// 应在程序启动、开始监听设备之前调用
+func RestorePending(onAudit AuditCallback) {
+	path, err := EnforceStateFile()
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		log.Printf("读取USB存储管控记录失败: %v", err)
+		return
+	}
+
+	var done []EnforceAction
+	if err = json.Unmarshal(data, &done); err != nil {
+		log.Printf("解析USB存储管控记录失败: %v", err)
+		_ = os.Remove(path)
+		return
+	}
+	log.Printf("恢复上次未恢复的USB存储管控操作 %d 项", len(done))
+	e := &Enforcer{OnAudit: onAudit, done: done}
+	e.Restore()
+}
+
+// EnforceStateFile 返回未恢复的管控操作记录路径
+func EnforceStateFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "monitor-desktop-client", enforceStateFileName), nil
+}
+
+// saveState 每次管控成功后写入需要恢复的操作，全部恢复后删除记录
+func (e *Enforcer) saveState() {
+	path, err := EnforceStateFile()
+	if err != nil {
+		log.Printf("保存USB存储管控记录失败: %v", err)
+		return
+	}
+	if len(e.done) == 0 {
+		if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("删除USB存储管控记录失败: %v", err)
+		}
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+		var data []byte
+		if data, err = json.MarshalIndent(e.done, "", "  "); err == nil {
+			err = os.WriteFile(path, data, 0600)
+		}
+	}
+	if err != nil {
+		log.Printf("保存USB存储管控记录失败: %v", err)
+	}
+}
+
+// apply 记录成功的操作以便恢复，并写审计记录
+func (e *Enforcer) apply(action EnforceAction, err error) {
+	action.Time = time.Now()
+	if err == nil {
+		e.done = append(e.done, action)
+		e.saveState()
+	}
+	e.audit(action.Action, action.Device, action.Target, err)
+}
+
+// applied 挂载点已经设为只读时，挂载点变化事件不再重复操作
+func (e *Enforcer) applied(action, target string) bool {
+	for _, done := range e.done {
+		if done.Action == action && done.Target == target {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Enforcer) audit(action string, device USBDevice, target string, err error) {
+	if err != nil {
+		log.Printf("USB存储管控 %s %s 失败: %v", action, target, err)
+	} else {
+		log.Printf("USB存储管控 %s %s 成功", action, target)
+	}
+	if e.OnAudit != nil {
+		e.OnAudit(EnforceAction{Action: action, Device: device, Target: target, Time: time.Now(), Err: err})
+	}
+}
@@ -0,0 +1,117 @@
+package devices
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// unmount 卸载挂载点，文件被占用时延迟卸载，
+// 没有 root 权限时通过 udisksctl 以当前用户身份卸载自动挂载的设备
+func unmount(mountPoint string) error {
+	err := unix.Unmount(mountPoint, 0)
+	if errors.Is(err, unix.EBUSY) {
+		err = unix.Unmount(mountPoint, unix.MNT_DETACH)
+	}
+	if err == nil || !errors.Is(err, unix.EPERM) {
+		return err
+	}
+
+	source := mountSource(mountPoint)
+	if source == "" {
+		return err
+	}
+	output, cmdErr := exec.Command("udisksctl", "unmount", "--no-user-interaction", "-b", source).CombinedOutput()
+	if cmdErr != nil {
+		return fmt.Errorf("%v; udisksctl: %v %s", err, cmdErr, output)
+	}
+	return nil
+}
+
+// remount 将挂载点重新挂载为只读或按原来的选项恢复，需要 root 权限
+// 重新挂载时未传入的 nosuid/noexec 等标志会被清除，因此 options 为只读前挂载表中的选项
+func remount(mountPoint, options string, readOnly bool) error {
+	flags := uintptr(unix.MS_REMOUNT) | mountFlags(options)
+	if readOnly {
+		flags |= unix.MS_RDONLY
+	}
+	return unix.Mount("", mountPoint, "", flags, "")
+}
+
+// mountOptionFlags 挂载表中的通用挂载选项与挂载标志的对应关系，文件系统自身的选项(uid= 等)重新挂载时保持不变
+var mountOptionFlags = map[string]uintptr{
+	"ro":          unix.MS_RDONLY,
+	"nosuid":      unix.MS_NOSUID,
+	"nodev":       unix.MS_NODEV,
+	"noexec":      unix.MS_NOEXEC,
+	"sync":        unix.MS_SYNCHRONOUS,
+	"dirsync":     unix.MS_DIRSYNC,
+	"mand":        unix.MS_MANDLOCK,
+	"noatime":     unix.MS_NOATIME,
+	"nodiratime":  unix.MS_NODIRATIME,
+	"relatime":    unix.MS_RELATIME,
+	"strictatime": unix.MS_STRICTATIME,
+	"lazytime":    unix.MS_LAZYTIME,
+}
+
+// mountFlags 将挂载选项转换为挂载标志，例如 rw,nosuid,nodev,noexec
+func mountFlags(options string) uintptr {
+	var flags uintptr
+	for _, option := range strings.Split(options, ",") {
+		flags |= mountOptionFlags[option]
+	}
+	return flags
+}
+
+// mountOptions 从挂载表读取挂载点当前的挂载选项，同一挂载点挂载多次时以最后一次为准
+func mountOptions(mountPoint string) (string, error) {
+	return readMountOptions(MountsFile, mountPoint)
+}
+
+func readMountOptions(mountsFile, mountPoint string) (string, error) {
+	data, err := os.ReadFile(mountsFile)
+	if err != nil {
+		return "", err
+	}
+	options, found := "", false
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 4 && unescapeMount(fields[1]) == mountPoint {
+			options, found = fields[3], true
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("挂载表中没有挂载点 %s", mountPoint)
+	}
+	return options, nil
+}
+
+// setAuthorized 写入设备的 authorized 文件，0 使内核解绑该设备的所有驱动，1 重新启用
+func setAuthorized(sysPath string, authorized bool) error {
+	if sysPath == "" {
+		return errors.New("缺少设备 sysfs 路径")
+	}
+	value := "0"
+	if authorized {
+		value = "1"
+	}
+	return os.WriteFile(filepath.Join(sysPath, "authorized"), []byte(value), 0644)
+}
+
+// mountSource 从挂载表查找挂载点对应的块设备
+func mountSource(mountPoint string) string {
+	mounts, _ := readMounts(MountsFile)
+	for name, points := range mounts {
+		for _, point := range points {
+			if point == mountPoint {
+				return "/dev/" + name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package devices
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestMountFlags(t *testing.T) {
+	tests := map[string]uintptr{
+		"rw":                                 0,
+		"rw,nosuid,nodev,relatime,uid=1000":  unix.MS_NOSUID | unix.MS_NODEV | unix.MS_RELATIME,
+		"ro,nosuid,nodev,noexec,relatime":    unix.MS_RDONLY | unix.MS_NOSUID | unix.MS_NODEV | unix.MS_NOEXEC | unix.MS_RELATIME,
+		"rw,sync,dirsync,noatime,nodiratime": unix.MS_SYNCHRONOUS | unix.MS_DIRSYNC | unix.MS_NOATIME | unix.MS_NODIRATIME,
+		"":                                   0,
+	}
+	for options, want := range tests {
+		if got := mountFlags(options); got != want {
+			t.Errorf("mountFlags(%q) = %#x, want %#x", options, got, want)
+		}
+	}
+}
+
+func TestReadMountOptions(t *testing.T) {
+	mounts := filepath.Join("testdata", "mounts")
+	tests := map[string]string{
+		"/media/student/My Disk": "rw,nosuid,nodev,relatime,uid=1000",
+		"/mnt/backup\tcopy":      "ro,relatime",
+		"/":                      "rw,relatime",
+	}
+	for mountPoint, want := range tests {
+		got, err := readMountOptions(mounts, mountPoint)
+		if err != nil || got != want {
+			t.Errorf("readMountOptions(%q) = %q, %v, want %q", mountPoint, got, err, want)
+		}
+	}
+	if _, err := readMountOptions(mounts, "/media/missing"); err == nil {
+		t.Error("挂载点不存在时应返回错误")
+	}
+}
+
+// 禁用设备后程序退出而未调用 Restore，下次启动时 RestorePending 应重新启用设备并删除记录
+func TestRestorePending(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	sysPath := t.TempDir()
+	authorized := filepath.Join(sysPath, "authorized")
+
+	var audits []EnforceAction
+	e := NewEnforcer(EnforceDeauthorize, func(action EnforceAction) {
+		audits = append(audits, action)
+	})
+	e.Activate()
+	device := USBDevice{DeviceID: `USB\VID_0781&PID_5581\4C530001`, IsStorageType: true, SysPath: sysPath}
+	e.Handle(DeviceEvent{Type: DeviceAdded, Device: device})
+
+	if data, _ := os.ReadFile(authorized); string(data) != "0" {
+		t.Fatalf("authorized = %q, want 0", data)
+	}
+	state, err := EnforceStateFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(state)
+	if err != nil {
+		t.Fatalf("管控成功后应保存记录: %v", err)
+	}
+	var saved []EnforceAction
+	if err = json.Unmarshal(data, &saved); err != nil || len(saved) != 1 || saved[0].Time.IsZero() {
+		t.Errorf("保存的记录 = %s, %v", data, err)
+	}
+
+	RestorePending(func(action EnforceAction) {
+		audits = append(audits, action)
+	})
+	if data, _ := os.ReadFile(authorized); string(data) != "1" {
+		t.Errorf("authorized = %q, want 1", data)
+	}
+	if _, err = os.Stat(state); !os.IsNotExist(err) {
+		t.Errorf("恢复后应删除记录: %v", err)
+	}
+	if len(audits) != 2 || audits[1].Action != ActionReauthorize || audits[1].Device.DeviceID != device.DeviceID || audits[1].Err != nil {
+		t.Errorf("audits = %+v", audits)
+	}
+
+	// 没有记录时不做任何操作
+	RestorePending(func(action EnforceAction) {
+		t.Errorf("没有记录时不应恢复: %+v", action)
+	})
+}
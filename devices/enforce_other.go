@@ -0,0 +1,19 @@
+//go:build !linux
+
+package devices
+
+func unmount(mountPoint string) error {
+	return ErrEnforceUnsupported
+}
+
+func remount(mountPoint, options string, readOnly bool) error {
+	return ErrEnforceUnsupported
+}
+
+func mountOptions(mountPoint string) (string, error) {
+	return "", ErrEnforceUnsupported
+}
+
+func setAuthorized(sysPath string, authorized bool) error {
+	return ErrEnforceUnsupported
+}
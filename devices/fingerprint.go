@@ -0,0 +1,221 @@
+package devices
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FingerprintVersion 设备指纹算法版本，版本1为旧的32位字符串哈希
+const FingerprintVersion = 2
+
+// 指纹组成部分，按此顺序参与计算
+const (
+	ComponentBIOSUUID      = "bios_uuid"
+	ComponentProductID     = "product_id" // Windows 产品ID / Linux machine-id
+	ComponentBoardSerial   = "board_serial"
+	ComponentProductSerial = "product_serial"
+	ComponentCPU           = "cpu"
+	ComponentDisk          = "disk"
+	ComponentMAC           = "mac"
+)
+
+var fingerprintComponents = []string{
+	ComponentBIOSUUID,
+	ComponentProductID,
+	ComponentBoardSerial,
+	ComponentProductSerial,
+	ComponentCPU,
+	ComponentDisk,
+	ComponentMAC,
+}
+
+// 指纹比对参数: 最多允许一个组成部分变化(例如更换网卡)，且至少有两个部分相同
+const (
+	MaxChangedComponents  = 1
+	MinMatchingComponents = 2
+)
+
+// defaultFingerprintSalt 服务器尚未下发盐值时使用的默认盐值，随程序公开，只用于区分本程序与其他软件对相同标识的哈希
+const defaultFingerprintSalt = "monitor-desktop-client/device-fingerprint/v2"
+
+// saltFileName 保存服务器在登录响应中下发的盐值，之后的登录使用该盐值计算指纹
+const saltFileName = "fingerprint-salt"
+
+var (
+	saltMu          sync.Mutex
+	fingerprintSalt string
+)
+
+// FingerprintSalt 返回当前使用的盐值，首次调用时读取本地保存的服务器盐值
+func FingerprintSalt() string {
+	saltMu.Lock()
+	defer saltMu.Unlock()
+	if fingerprintSalt == "" {
+		fingerprintSalt = defaultFingerprintSalt
+		if path, err := saltFile(); err == nil {
+			if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+				fingerprintSalt = string(data)
+			}
+		}
+	}
+	return fingerprintSalt
+}
+
+// SetFingerprintSalt 设置服务器在登录响应中下发的盐值并保存到本地，为空时不做修改
+func SetFingerprintSalt(salt string) error {
+	if salt == "" {
+		return nil
+	}
+	saltMu.Lock()
+	defer saltMu.Unlock()
+	fingerprintSalt = salt
+
+	path, err := saltFile()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(salt), 0600)
+}
+
+func saltFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return "", errors.New("无法确定配置目录")
+	}
+	return filepath.Join(dir, "monitor-desktop-client", saltFileName), nil
+}
+
+// saltDigest 盐值的摘要，本地绑定只记录摘要，用于判断指纹是否使用相同盐值计算
+func saltDigest(salt string) string {
+	sum := sha256.Sum256([]byte(salt))
+	return hex.EncodeToString(sum[:8])
+}
+
+// placeholderIdentifiers 厂商未填写时 DMI 中常见的占位值，视为缺失
+var placeholderIdentifiers = []string{
+	"", "0", "none", "null", "n/a", "not applicable", "not specified", "default string",
+	"to be filled by o.e.m.", "system serial number", "system product name", "chassis serial number",
+	"00000000-0000-0000-0000-000000000000", "ffffffff-ffff-ffff-ffff-ffffffffffff",
+	"03000200-0400-0500-0006-000700080009",
+}
+
+// Fingerprint 设备指纹
+// ID 覆盖全部组成部分，任一部分变化都会改变；Components 为各部分单独的哈希，用于容忍部分硬件变化的比对
+type Fingerprint struct {
+	Version    int               `json:"version"`
+	ID         string            `json:"id"`
+	Components map[string]string `json:"components"`
+	LegacyID   string            `json:"legacyId"` // 版本1的标识，供服务器迁移旧记录
+
+	salt string // 计算所用盐值的摘要，不上传
+}
+
+// FingerprintMatch 两个指纹的比对结果
+type FingerprintMatch struct {
+	Score   float64  // 相同部分占双方都有的部分的比例
+	Matched []string // 相同的部分
+	Changed []string // 双方都有但不同的部分
+	Stable  bool     // 是否视为同一台设备
+}
+
+// NewFingerprint 使用盐值根据原始标识计算指纹，原始标识先规范化，缺失或占位值不参与计算
+func NewFingerprint(identifiers map[string]string, salt string) Fingerprint {
+	fp := Fingerprint{Version: FingerprintVersion, Components: make(map[string]string), salt: saltDigest(salt)}
+	whole := sha256.New()
+	whole.Write([]byte(salt))
+	fmt.Fprintf(whole, "\nv%d\n", FingerprintVersion)
+	for _, name := range fingerprintComponents {
+		value := normalizeIdentifier(identifiers[name])
+		if value == "" {
+			continue
+		}
+		fmt.Fprintf(whole, "%s=%s\n", name, value)
+
+		mac := hmac.New(sha256.New, []byte(salt))
+		fmt.Fprintf(mac, "%s=%s", name, value)
+		fp.Components[name] = hex.EncodeToString(mac.Sum(nil))
+	}
+	if len(fp.Components) > 0 {
+		fp.ID = hex.EncodeToString(whole.Sum(nil))
+	}
+	return fp
+}
+
+// CompareFingerprints 比对指纹各组成部分，版本不同时不视为同一台设备
+func CompareFingerprints(stored, current Fingerprint) FingerprintMatch {
+	var match FingerprintMatch
+	if stored.Version != current.Version {
+		return match
+	}
+
+	for _, name := range fingerprintComponents {
+		a, okA := stored.Components[name]
+		b, okB := current.Components[name]
+		if !okA || !okB {
+			continue
+		}
+		if hmac.Equal([]byte(a), []byte(b)) {
+			match.Matched = append(match.Matched, name)
+		} else {
+			match.Changed = append(match.Changed, name)
+		}
+	}
+
+	total := len(match.Matched) + len(match.Changed)
+	if total > 0 {
+		match.Score = float64(len(match.Matched)) / float64(total)
+	}
+	match.Stable = len(match.Changed) <= MaxChangedComponents && len(match.Matched) >= MinMatchingComponents
+	return match
+}
+
+// normalizeIdentifier 去除空白和大小写差异，多值标识(如多块网卡)排序后合并
+func normalizeIdentifier(value string) string {
+	var parts []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ToLower(strings.Join(strings.Fields(part), " "))
+		if isPlaceholderIdentifier(part) {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func isPlaceholderIdentifier(value string) bool {
+	for _, placeholder := range placeholderIdentifiers {
+		if value == placeholder {
+			return true
+		}
+	}
+	return false
+}
+
+// legacyHash 版本1使用的32位字符串哈希，只用于生成 LegacyID
+func legacyHash(input string) string {
+	if input == "" {
+		return ""
+	}
+
+	var h uint32
+	for i := 0; i < len(input); i++ {
+		h = 31*h + uint32(input[i])
+	}
+
+	return fmt.Sprintf("%x", h)
+}
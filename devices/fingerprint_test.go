@@ -0,0 +1,150 @@
+package devices
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testSalt = "test-salt"
+
+var testIdentifiers = map[string]string{
+	ComponentBIOSUUID:      "3B1F2C4E-5D6A-11B2-A85C-C3D4E5F60718",
+	ComponentProductID:     "4b3f0c1d2e5a4f6b8c9d0e1f2a3b4c5d",
+	ComponentBoardSerial:   "L1HF0AB00CD",
+	ComponentProductSerial: "PF1ABCDE",
+	ComponentCPU:           "Intel(R) Core(TM) i7-8565U CPU @ 1.80GHz-6.142.12",
+	ComponentDisk:          "S4EWNX0N123456,WD-WX11A12B3456",
+	ComponentMAC:           "a4:4c:c8:01:02:03,9c:b6:d0:04:05:06",
+}
+
+// withIdentifiers 复制测试标识并修改部分值
+func withIdentifiers(changes map[string]string) map[string]string {
+	identifiers := make(map[string]string, len(testIdentifiers))
+	for name, value := range testIdentifiers {
+		identifiers[name] = value
+	}
+	for name, value := range changes {
+		identifiers[name] = value
+	}
+	return identifiers
+}
+
+func TestNewFingerprint(t *testing.T) {
+	base := NewFingerprint(testIdentifiers, testSalt)
+	if base.Version != FingerprintVersion || len(base.ID) != 64 || len(base.Components) != len(fingerprintComponents) {
+		t.Fatalf("NewFingerprint() = %+v", base)
+	}
+	for name, value := range base.Components {
+		if value == testIdentifiers[name] {
+			t.Errorf("%s 不应保存原始标识", name)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		identifiers map[string]string
+		salt        string
+		sameID      bool
+	}{
+		{"same", testIdentifiers, testSalt, true},
+		// 大小写、空白和多值顺序不影响指纹
+		{"normalized", withIdentifiers(map[string]string{
+			ComponentBIOSUUID: " 3b1f2c4e-5d6a-11b2-a85c-c3d4e5f60718 ",
+			ComponentCPU:      "intel(r)  core(tm) i7-8565u cpu @ 1.80ghz-6.142.12",
+			ComponentMAC:      "9C:B6:D0:04:05:06, A4:4C:C8:01:02:03",
+		}), testSalt, true},
+		{"changed", withIdentifiers(map[string]string{ComponentMAC: "00:11:22:33:44:55"}), testSalt, false},
+		{"other-salt", testIdentifiers, "other-salt", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := NewFingerprint(tt.identifiers, tt.salt)
+			if (fp.ID == base.ID) != tt.sameID {
+				t.Errorf("ID 相同 = %v, want %v", fp.ID == base.ID, tt.sameID)
+			}
+		})
+	}
+}
+
+func TestNewFingerprintPlaceholders(t *testing.T) {
+	fp := NewFingerprint(map[string]string{
+		ComponentBIOSUUID:      "00000000-0000-0000-0000-000000000000",
+		ComponentBoardSerial:   "To be filled by O.E.M.",
+		ComponentProductSerial: "Default string",
+		ComponentCPU:           "Intel(R) Core(TM) i7-8565U CPU @ 1.80GHz-6.142.12",
+	}, testSalt)
+	if want := []string{ComponentCPU}; !reflect.DeepEqual(componentNames(fp), want) {
+		t.Errorf("Components = %q, want %q", componentNames(fp), want)
+	}
+
+	if fp = NewFingerprint(map[string]string{ComponentProductSerial: "None"}, testSalt); fp.ID != "" || len(fp.Components) != 0 {
+		t.Errorf("只有占位值时 ID 应为空: %+v", fp)
+	}
+}
+
+func componentNames(fp Fingerprint) []string {
+	var names []string
+	for _, name := range fingerprintComponents {
+		if _, ok := fp.Components[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func TestCompareFingerprints(t *testing.T) {
+	stored := NewFingerprint(testIdentifiers, testSalt)
+	oldVersion := NewFingerprint(testIdentifiers, testSalt)
+	oldVersion.Version = 1
+
+	tests := []struct {
+		name    string
+		current Fingerprint
+		stable  bool
+		changed []string
+	}{
+		{"same", NewFingerprint(testIdentifiers, testSalt), true, nil},
+		{"one-changed", NewFingerprint(withIdentifiers(map[string]string{ComponentMAC: "00:11:22:33:44:55"}), testSalt), true, []string{ComponentMAC}},
+		{"two-changed", NewFingerprint(withIdentifiers(map[string]string{
+			ComponentDisk: "S5GXNX0T654321",
+			ComponentMAC:  "00:11:22:33:44:55",
+		}), testSalt), false, []string{ComponentDisk, ComponentMAC}},
+		// 缺失的部分不计入变化，但相同部分不足时不视为同一台设备
+		{"too-few", NewFingerprint(map[string]string{ComponentCPU: testIdentifiers[ComponentCPU]}, testSalt), false, nil},
+		{"version", oldVersion, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := CompareFingerprints(stored, tt.current)
+			if match.Stable != tt.stable || !reflect.DeepEqual(match.Changed, tt.changed) {
+				t.Errorf("CompareFingerprints() = %+v, want Stable %v, Changed %q", match, tt.stable, tt.changed)
+			}
+		})
+	}
+}
+
+func TestFingerprintSalt(t *testing.T) {
+	fakeConfigDir(t)
+	reset := func() {
+		saltMu.Lock()
+		fingerprintSalt = ""
+		saltMu.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+
+	if got := FingerprintSalt(); got != defaultFingerprintSalt {
+		t.Errorf("未下发盐值时 FingerprintSalt() = %q, want default", got)
+	}
+	if err := SetFingerprintSalt("server-salt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetFingerprintSalt(""); err != nil {
+		t.Fatal(err)
+	}
+	// 重新启动后读取本地保存的盐值
+	reset()
+	if got := FingerprintSalt(); got != "server-salt" {
+		t.Errorf("FingerprintSalt() = %q, want server-salt", got)
+	}
+}
@@ -0,0 +1,54 @@
+package devices
+
+import "strings"
+
+// IsVirtualInterface 检查是否是虚拟网络接口
+func IsVirtualInterface(name string) bool {
+	// 常见虚拟接口名称标识
+	virtualPrefixes := []string{
+		"vethernet", "veth", "vmnet", "vboxnet",
+		"docker", "virbr", "br-", "vnet", "virtual",
+	}
+
+	nameLower := strings.ToLower(name)
+	for _, prefix := range virtualPrefixes {
+		if strings.Contains(nameLower, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsTetheringInterface 检查是否是手机USB共享网络或移动热点接口
+func IsTetheringInterface(name string, description string, addresses []string) bool {
+	// 常见USB网络共享驱动及接口名称标识
+	tetheringKeywords := []string{
+		"rndis", "tether", "hotspot", "android", "iphone",
+		"apple mobile device", "mobile broadband", "wwan", "usb0", "enx",
+	}
+
+	text := strings.ToLower(name + " " + description)
+	for _, keyword := range tetheringKeywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+
+	// 手机共享网络默认分配的网段
+	tetheringSubnets := []string{
+		"192.168.42.",  // Android USB共享
+		"192.168.43.",  // Android WLAN热点
+		"172.20.10.",   // iPhone个人热点
+		"192.168.137.", // Windows移动热点
+	}
+	for _, addr := range addresses {
+		for _, subnet := range tetheringSubnets {
+			if strings.HasPrefix(addr, subnet) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+package devices
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// ueventReadTimeout 读取超时，用于定期检查监听器是否已停止
+const ueventReadTimeout = time.Second
+
+// netlinkUevent 通过 NETLINK_KOBJECT_UEVENT 接收内核发出的设备事件
+type netlinkUevent struct {
+	fd  int
+	buf []byte
+}
+
+func openUeventSource() (ueventSource, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return nil, err
+	}
+	// 组1为内核广播的原始事件，不依赖 udevd
+	if err = unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: 1}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	timeout := unix.NsecToTimeval(ueventReadTimeout.Nanoseconds())
+	if err = unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return &netlinkUevent{fd: fd, buf: make([]byte, 64*1024)}, nil
+}
+
+// Receive 读取一条事件，超时返回 nil
+func (n *netlinkUevent) Receive() (map[string]string, error) {
+	size, _, err := unix.Recvfrom(n.fd, n.buf, 0)
+	if err != nil {
+		if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseUevent(n.buf[:size]), nil
+}
+
+func (n *netlinkUevent) Close() error {
+	return unix.Close(n.fd)
+}
+
+// parseUevent 解析 "add@/devices/...\0ACTION=add\0SUBSYSTEM=usb\0..." 格式的事件
+func parseUevent(data []byte) map[string]string {
+	event := make(map[string]string)
+	for _, field := range bytes.Split(data, []byte{0}) {
+		if key, value, ok := bytes.Cut(field, []byte("=")); ok {
+			event[string(key)] = string(value)
+		}
+	}
+	return event
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package devices
+
+import "errors"
+
+// openUeventSource 其他平台没有内核 uevent，由监听器回退到轮询
+func openUeventSource() (ueventSource, error) {
+	return nil, errors.New("当前平台不支持内核设备事件")
+}
@@ -0,0 +1,259 @@
+package devices
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 默认的 sysfs 根目录和挂载表，测试时可用 ScanUSBDevices 指向伪造的目录树
+const (
+	SysfsRoot  = "/sys"
+	MountsFile = "/proc/self/mounts"
+)
+
+// USB 类代码
+const (
+	usbClassPerInterface = "00" // 由各接口声明类型
+	usbClassAudio        = "01"
+	usbClassComm         = "02"
+	usbClassHID          = "03"
+	usbClassImage        = "06"
+	usbClassPrinter      = "07"
+	usbClassMassStorage  = "08"
+	usbClassHub          = "09"
+	usbClassVideo        = "0e"
+	usbClassWireless     = "e0"
+)
+
+// GetUSBDevices 获取所有当前连接的外接USB设备信息
+func GetUSBDevices() ([]USBDevice, error) {
+	return ScanUSBDevices(SysfsRoot, MountsFile)
+}
+
+// ScanUSBDevices 遍历 sysRoot/bus/usb/devices 枚举USB设备，
+// 大容量存储设备通过其下的块设备在 mountsFile 中查找挂载点
+func ScanUSBDevices(sysRoot, mountsFile string) ([]USBDevice, error) {
+	usbRoot := filepath.Join(sysRoot, "bus", "usb", "devices")
+	entries, err := os.ReadDir(usbRoot)
+	if err != nil {
+		return nil, fmt.Errorf("读取USB设备目录失败: %v", err)
+	}
+
+	mounts, err := readMounts(mountsFile)
+	if err != nil {
+		// 没有挂载表时仍然返回设备列表，只是缺少挂载点
+		log.Printf("读取挂载表失败: %v", err)
+	}
+
+	var devices []USBDevice
+	for _, entry := range entries {
+		name := entry.Name()
+		// 根集线器(usb1)和接口(1-1:1.0)不是独立设备
+		if strings.HasPrefix(name, "usb") || strings.Contains(name, ":") {
+			continue
+		}
+
+		dir := filepath.Join(usbRoot, name)
+		if readSysfs(dir, "idVendor") == "" {
+			continue
+		}
+		device, ok := readUSBDevice(usbRoot, dir, name, mounts)
+		if ok {
+			devices = append(devices, device)
+		}
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].DeviceID < devices[j].DeviceID
+	})
+	return devices, nil
+}
+
+// readUSBDevice 读取单个USB设备，集线器返回 false
+func readUSBDevice(usbRoot, dir, busID string, mounts map[string][]string) (USBDevice, bool) {
+	vendor := readSysfs(dir, "idVendor")
+	product := readSysfs(dir, "idProduct")
+	serial := readSysfs(dir, "serial")
+
+	deviceClass := readSysfs(dir, "bDeviceClass")
+	if deviceClass == usbClassHub {
+		return USBDevice{}, false
+	}
+	classes := []string{}
+	if deviceClass != "" && deviceClass != usbClassPerInterface {
+		classes = append(classes, deviceClass)
+	}
+	for _, class := range interfaceClasses(usbRoot, busID) {
+		if !containsString(classes, class) {
+			classes = append(classes, class)
+		}
+	}
+
+	name := readSysfs(dir, "product")
+	if name == "" {
+		name = fmt.Sprintf("USB设备 %s:%s", vendor, product)
+	}
+
+	// 与 Windows 设备实例ID格式保持一致，便于服务器比对
+	instance := serial
+	if instance == "" {
+		instance = busID
+	}
+
+	deviceType := usbDeviceType(classes)
+	device := USBDevice{
+		DeviceID:      fmt.Sprintf(`USB\VID_%s&PID_%s\%s`, strings.ToUpper(vendor), strings.ToUpper(product), instance),
+		DeviceName:    name,
+		Description:   name,
+		Manufacturer:  readSysfs(dir, "manufacturer"),
+		DeviceType:    deviceType,
+		IsStorageType: deviceType == "存储设备",
+		IsExternal:    readSysfs(dir, "removable") != "fixed",
+		VendorID:      vendor,
+		ProductID:     product,
+		Serial:        serial,
+		Classes:       classes,
+		SysPath:       dir,
+	}
+	if device.IsStorageType {
+		for _, block := range blockDevices(dir) {
+			device.DriveLetters = append(device.DriveLetters, mounts[block]...)
+		}
+	}
+	return device, true
+}
+
+// interfaceClasses 读取设备各接口(1-1:1.0 等)的类代码
+func interfaceClasses(usbRoot, busID string) []string {
+	matches, _ := filepath.Glob(filepath.Join(usbRoot, busID+":*"))
+	sort.Strings(matches)
+
+	var classes []string
+	for _, path := range matches {
+		if class := readSysfs(path, "bInterfaceClass"); class != "" {
+			classes = append(classes, class)
+		}
+	}
+	return classes
+}
+
+// usbDeviceType 按类代码判断设备类型，与 Windows 实现使用相同的类型名称
+func usbDeviceType(classes []string) string {
+	for _, class := range classes {
+		if class == usbClassMassStorage {
+			return "存储设备"
+		}
+	}
+	for _, class := range classes {
+		switch class {
+		case usbClassVideo:
+			return "摄像头"
+		case usbClassHID:
+			return "输入设备"
+		case usbClassAudio:
+			return "音频设备"
+		case usbClassComm, usbClassWireless:
+			return "网络设备"
+		case usbClassPrinter:
+			return "打印机"
+		case usbClassImage:
+			return "图像设备"
+		}
+	}
+	return "外设"
+}
+
+// blockDevices 在设备目录下查找块设备及其分区，例如 sdb、sdb1
+// 不跟随符号链接，避免沿 subsystem、driver 等链接遍历整个 sysfs
+func blockDevices(dir string) []string {
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil
+	}
+
+	var blocks []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == root {
+			return nil
+		}
+		parent := filepath.Base(filepath.Dir(path))
+		grandparent := filepath.Base(filepath.Dir(filepath.Dir(path)))
+		if parent == "block" {
+			blocks = append(blocks, d.Name())
+		} else if grandparent == "block" && strings.HasPrefix(d.Name(), parent) {
+			blocks = append(blocks, d.Name()) // 分区
+		}
+		return nil
+	})
+	sort.Strings(blocks)
+	return blocks
+}
+
+// readMounts 读取挂载表，返回块设备名到挂载点的映射
+func readMounts(path string) (map[string][]string, error) {
+	mounts := make(map[string][]string)
+	file, err := os.Open(path)
+	if err != nil {
+		return mounts, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+		device := unescapeMount(fields[0])
+		// /dev/disk/by-uuid 等链接需要解析为实际设备
+		if resolved, err := filepath.EvalSymlinks(device); err == nil {
+			device = resolved
+		}
+		name := filepath.Base(device)
+		mounts[name] = append(mounts[name], unescapeMount(fields[1]))
+	}
+	return mounts, scanner.Err()
+}
+
+// unescapeMount 还原挂载表中以八进制转义的空格、制表符等字符
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func readSysfs(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
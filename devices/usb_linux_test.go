@@ -0,0 +1,248 @@
+package devices
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTree 按相对路径写入伪造的 sysfs 文件，值为空的路径创建为目录
+// sysfs 的接口目录名含冒号(1-1:1.0)，无法随仓库在 Windows 上检出，因此在临时目录中生成
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if content == "" {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// usbSysfs 生成与真实系统结构一致的 sysfs:
+// bus/usb/devices 下是指向 devices/pci... 的符号链接，接口和块设备位于设备目录之下
+func usbSysfs(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	const bus = "devices/pci0000:00/0000:00:14.0/usb1"
+	writeTree(t, root, map[string]string{
+		// 根集线器
+		bus + "/idVendor":     "1d6b",
+		bus + "/idProduct":    "0002",
+		bus + "/bDeviceClass": "09",
+
+		// 键盘，两个 HID 接口
+		bus + "/1-1/idVendor":                "046d",
+		bus + "/1-1/idProduct":               "c31c",
+		bus + "/1-1/product":                 "USB Keyboard",
+		bus + "/1-1/manufacturer":            "Logitech",
+		bus + "/1-1/bDeviceClass":            "00",
+		bus + "/1-1/removable":               "removable",
+		bus + "/1-1/1-1:1.0/bInterfaceClass": "03",
+		bus + "/1-1/1-1:1.1/bInterfaceClass": "03",
+
+		// U盘，两个分区
+		bus + "/1-2/idVendor":                "0781",
+		bus + "/1-2/idProduct":               "5581",
+		bus + "/1-2/serial":                  "4C530001",
+		bus + "/1-2/product":                 "Ultra",
+		bus + "/1-2/manufacturer":            "SanDisk",
+		bus + "/1-2/bDeviceClass":            "00",
+		bus + "/1-2/removable":               "removable",
+		bus + "/1-2/1-2:1.0/bInterfaceClass": "08",
+		bus + "/1-2/1-2:1.0/host6/target6:0:0/6:0:0:0/block/sdb/sdb1":  "",
+		bus + "/1-2/1-2:1.0/host6/target6:0:0/6:0:0:0/block/sdb/sdb2":  "",
+		bus + "/1-2/1-2:1.0/host6/target6:0:0/6:0:0:0/block/sdb/queue": "",
+
+		// 外接集线器及其下的内置摄像头，摄像头接口声明视频和音频类
+		bus + "/1-3/idVendor":                        "05e3",
+		bus + "/1-3/idProduct":                       "0610",
+		bus + "/1-3/product":                         "USB2.0 Hub",
+		bus + "/1-3/bDeviceClass":                    "09",
+		bus + "/1-3/1-3:1.0/bInterfaceClass":         "09",
+		bus + "/1-3/1-3.1/idVendor":                  "046d",
+		bus + "/1-3/1-3.1/idProduct":                 "0825",
+		bus + "/1-3/1-3.1/bDeviceClass":              "ef",
+		bus + "/1-3/1-3.1/removable":                 "fixed",
+		bus + "/1-3/1-3.1/1-3.1:1.0/bInterfaceClass": "0e",
+		bus + "/1-3/1-3.1/1-3.1:1.1/bInterfaceClass": "0e",
+		bus + "/1-3/1-3.1/1-3.1:1.2/bInterfaceClass": "01",
+	})
+
+	links := map[string]string{
+		"usb1":      bus,
+		"1-1":       bus + "/1-1",
+		"1-1:1.0":   bus + "/1-1/1-1:1.0",
+		"1-1:1.1":   bus + "/1-1/1-1:1.1",
+		"1-2":       bus + "/1-2",
+		"1-2:1.0":   bus + "/1-2/1-2:1.0",
+		"1-3":       bus + "/1-3",
+		"1-3:1.0":   bus + "/1-3/1-3:1.0",
+		"1-3.1":     bus + "/1-3/1-3.1",
+		"1-3.1:1.0": bus + "/1-3/1-3.1/1-3.1:1.0",
+		"1-3.1:1.1": bus + "/1-3/1-3.1/1-3.1:1.1",
+		"1-3.1:1.2": bus + "/1-3/1-3.1/1-3.1:1.2",
+	}
+	usbRoot := filepath.Join(root, "bus", "usb", "devices")
+	if err := os.MkdirAll(usbRoot, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, target := range links {
+		if err := os.Symlink(filepath.Join("..", "..", "..", target), filepath.Join(usbRoot, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestScanUSBDevices(t *testing.T) {
+	root := usbSysfs(t)
+	usbRoot := filepath.Join(root, "bus", "usb", "devices")
+
+	got, err := ScanUSBDevices(root, filepath.Join("testdata", "mounts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []USBDevice{
+		{
+			DeviceID:    `USB\VID_046D&PID_0825\1-3.1`,
+			DeviceName:  "USB设备 046d:0825",
+			Description: "USB设备 046d:0825",
+			DeviceType:  "摄像头",
+			IsExternal:  false,
+			VendorID:    "046d",
+			ProductID:   "0825",
+			Classes:     []string{"ef", "0e", "01"},
+			SysPath:     filepath.Join(usbRoot, "1-3.1"),
+		},
+		{
+			DeviceID:     `USB\VID_046D&PID_C31C\1-1`,
+			DeviceName:   "USB Keyboard",
+			Description:  "USB Keyboard",
+			Manufacturer: "Logitech",
+			DeviceType:   "输入设备",
+			IsExternal:   true,
+			VendorID:     "046d",
+			ProductID:    "c31c",
+			Classes:      []string{"03"},
+			SysPath:      filepath.Join(usbRoot, "1-1"),
+		},
+		{
+			DeviceID:      `USB\VID_0781&PID_5581\4C530001`,
+			DeviceName:    "Ultra",
+			Description:   "Ultra",
+			Manufacturer:  "SanDisk",
+			DeviceType:    "存储设备",
+			IsStorageType: true,
+			IsExternal:    true,
+			DriveLetters:  []string{"/media/student/My Disk", "/mnt/backup\tcopy", "/media/student/DATA"},
+			VendorID:      "0781",
+			ProductID:     "5581",
+			Serial:        "4C530001",
+			Classes:       []string{"08"},
+			SysPath:       filepath.Join(usbRoot, "1-2"),
+		},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ScanUSBDevices() 返回 %d 个设备, want %d (集线器和接口应被跳过): %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("设备 %d =\n%+v\nwant\n%+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestScanUSBDevicesWithoutMounts(t *testing.T) {
+	root := usbSysfs(t)
+	got, err := ScanUSBDevices(root, filepath.Join(root, "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("缺少挂载表时仍应返回设备列表, got %d", len(got))
+	}
+	for _, d := range got {
+		if len(d.DriveLetters) != 0 {
+			t.Errorf("%s 挂载点 = %q, want none", d.DeviceID, d.DriveLetters)
+		}
+	}
+
+	if _, err := ScanUSBDevices(filepath.Join(root, "missing"), filepath.Join("testdata", "mounts")); err == nil {
+		t.Error("USB设备目录不存在时应返回错误")
+	}
+}
+
+func TestBlockDevices(t *testing.T) {
+	root := usbSysfs(t)
+	got := blockDevices(filepath.Join(root, "bus", "usb", "devices", "1-2"))
+	want := []string{"sdb", "sdb1", "sdb2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("blockDevices() = %q, want %q", got, want)
+	}
+}
+
+func TestReadMounts(t *testing.T) {
+	got, err := readMounts(filepath.Join("testdata", "mounts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"sda2": {"/"},
+		"sdb1": {"/media/student/My Disk", "/mnt/backup\tcopy"},
+		"sdb2": {"/media/student/DATA"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readMounts() = %q, want %q", got, want)
+	}
+}
+
+func TestUnescapeMount(t *testing.T) {
+	tests := map[string]string{
+		"/media/usb":            "/media/usb",
+		`/media/My\040Disk`:     "/media/My Disk",
+		`/media/a\011b\012c`:    "/media/a\tb\nc",
+		`/media/back\134slash`:  `/media/back\slash`,
+		`/media/trailing\040`:   "/media/trailing ",
+		`/media/short\04`:       `/media/short\04`,
+		`/media/not\octal\999x`: `/media/not\octal\999x`,
+	}
+	for input, want := range tests {
+		if got := unescapeMount(input); got != want {
+			t.Errorf("unescapeMount(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestUSBDeviceType(t *testing.T) {
+	tests := []struct {
+		classes []string
+		want    string
+	}{
+		{[]string{"0e", "01"}, "摄像头"},
+		{[]string{"03", "08"}, "存储设备"},
+		{[]string{"03"}, "输入设备"},
+		{[]string{"01"}, "音频设备"},
+		{[]string{"e0"}, "网络设备"},
+		{[]string{"02", "0a"}, "网络设备"},
+		{[]string{"07"}, "打印机"},
+		{[]string{"06"}, "图像设备"},
+		{[]string{"ff"}, "外设"},
+		{nil, "外设"},
+	}
+	for _, tt := range tests {
+		if got := usbDeviceType(tt.classes); got != tt.want {
+			t.Errorf("usbDeviceType(%q) = %s, want %s", tt.classes, got, tt.want)
+		}
+	}
+}
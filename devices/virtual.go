@@ -0,0 +1,170 @@
+package devices
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+)
+
+// 虚拟化类型
+const (
+	KindVM        = "vm"        // 虚拟机
+	KindEmulator  = "emulator"  // 纯软件模拟(如 QEMU TCG)
+	KindContainer = "container" // 容器
+	KindWSL       = "wsl"       // Windows Subsystem for Linux
+)
+
+// VirtualScoreThreshold 证据分数之和达到该值判定为虚拟环境
+const VirtualScoreThreshold = 50
+
+// Evidence 一条虚拟化证据
+type Evidence struct {
+	Signal string `json:"signal"` // 检测项，例如 dmi、cpuid、mac_oui
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+	Weight int    `json:"weight"`
+}
+
+// VirtualizationReport 虚拟机和沙箱检测结果
+type VirtualizationReport struct {
+	Score     int        `json:"score"` // 0-100
+	IsVirtual bool       `json:"isVirtual"`
+	Kinds     []string   `json:"kinds"`
+	Evidence  []Evidence `json:"evidence"`
+}
+
+// virtualVendors 虚拟化厂商在 DMI、CPUID 等处的常见标识
+var virtualVendors = []string{
+	"vmware", "virtualbox", "vbox", "innotek", "kvm", "qemu", "xen", "parallels",
+	"virtual machine", "hyper-v", "bochs", "bhyve", "openstack", "google compute engine",
+	"amazon ec2", "alibaba cloud ecs", "cloud hypervisor", "firecracker",
+}
+
+// hypervisorVendors CPUID 叶 0x40000000 返回的厂商标识
+var hypervisorVendors = map[string]string{
+	"KVMKVMKVM":    "KVM",
+	"VMwareVMware": "VMware",
+	"VBoxVBoxVBox": "VirtualBox",
+	"Microsoft Hv": "Hyper-V",
+	"XenVMMXenVMM": "Xen",
+	"TCGTCGTCGTCG": "QEMU TCG",
+	" lrpepyh  vr": "Parallels",
+	"bhyve bhyve ": "bhyve",
+	"ACRNACRNACRN": "ACRN",
+	"QNXQVMBSQG":   "QNX",
+}
+
+// virtualMACPrefixes 虚拟网卡的 MAC 地址 OUI
+var virtualMACPrefixes = map[string]string{
+	"00:05:69": "VMware",
+	"00:0c:29": "VMware",
+	"00:1c:14": "VMware",
+	"00:50:56": "VMware",
+	"08:00:27": "VirtualBox",
+	"0a:00:27": "VirtualBox",
+	"52:54:00": "QEMU/KVM",
+	"00:16:3e": "Xen",
+	"00:1c:42": "Parallels",
+	"00:15:5d": "Hyper-V",
+}
+
+// DetectVirtualization 汇总各项证据判断是否运行在虚拟机、模拟器、容器或 WSL 中
+func DetectVirtualization() VirtualizationReport {
+	var evidence []Evidence
+	evidence = append(evidence, platformVirtualEvidence()...)
+	evidence = append(evidence, cpuidEvidence()...)
+	evidence = append(evidence, macEvidence()...)
+	return newVirtualizationReport(evidence)
+}
+
+// newVirtualizationReport 同一检测项只计最高分，总分封顶100，Kinds 只包含计分的证据的类型
+func newVirtualizationReport(evidence []Evidence) VirtualizationReport {
+	report := VirtualizationReport{Evidence: evidence}
+	best := make(map[string]int) // 检测项 -> 计分证据的下标
+	for i, e := range evidence {
+		if j, ok := best[e.Signal]; !ok || e.Weight > evidence[j].Weight {
+			best[e.Signal] = i
+		}
+	}
+	for i, e := range evidence {
+		if best[e.Signal] != i || e.Weight <= 0 {
+			continue
+		}
+		report.Score += e.Weight
+		if !slices.Contains(report.Kinds, e.Kind) {
+			report.Kinds = append(report.Kinds, e.Kind)
+		}
+	}
+	report.Score = min(report.Score, 100)
+	report.IsVirtual = report.Score >= VirtualScoreThreshold
+	return report
+}
+
+// dmiEvidence 检查 BIOS、主板和整机厂商及型号中的虚拟化标识
+func dmiEvidence(fields map[string]string) []Evidence {
+	var evidence []Evidence
+	for name, value := range fields {
+		lower := strings.ToLower(value)
+		for _, vendor := range virtualVendors {
+			if strings.Contains(lower, vendor) {
+				evidence = append(evidence, Evidence{
+					Signal: "dmi",
+					Kind:   KindVM,
+					Detail: fmt.Sprintf("%s: %s", name, value),
+					Weight: 40,
+				})
+				break
+			}
+		}
+	}
+	return evidence
+}
+
+// cpuidEvidence 检查 CPUID 的 hypervisor 位和虚拟化厂商
+// Windows 开启 VBS 或 WSL2 时物理机也运行在 Hyper-V 之上，因此 Hyper-V 只计低分
+func cpuidEvidence() []Evidence {
+	present, vendor, ok := hypervisorCPUID()
+	if !ok || !present {
+		return nil
+	}
+
+	vendor = strings.TrimRight(vendor, "\x00")
+	name, known := hypervisorVendors[vendor]
+	switch {
+	case !known:
+		return []Evidence{{Signal: "cpuid", Kind: KindVM, Detail: fmt.Sprintf("hypervisor 位已置位，厂商 %q", vendor), Weight: 30}}
+	case name == "Hyper-V":
+		return []Evidence{{Signal: "cpuid", Kind: KindVM, Detail: "Hyper-V(可能为启用 VBS 的物理机)", Weight: 10}}
+	case name == "QEMU TCG":
+		return []Evidence{{Signal: "cpuid", Kind: KindEmulator, Detail: "QEMU TCG 软件模拟", Weight: 50}}
+	default:
+		return []Evidence{{Signal: "cpuid", Kind: KindVM, Detail: "hypervisor: " + name, Weight: 40}}
+	}
+}
+
+// macEvidence 检查物理网卡的 MAC 地址是否属于虚拟化厂商
+func macEvidence() []Evidence {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+
+	var evidence []Evidence
+	for _, iface := range interfaces {
+		// 宿主机上的虚拟网桥等接口不能说明本机是虚拟机
+		if iface.Flags&net.FlagLoopback != 0 || IsVirtualInterface(iface.Name) || len(iface.HardwareAddr) < 3 {
+			continue
+		}
+		mac := iface.HardwareAddr.String()
+		if vendor, ok := virtualMACPrefixes[mac[:8]]; ok {
+			evidence = append(evidence, Evidence{
+				Signal: "mac_oui",
+				Kind:   KindVM,
+				Detail: fmt.Sprintf("%s %s (%s)", iface.Name, mac, vendor),
+				Weight: 25,
+			})
+		}
+	}
+	return evidence
+}
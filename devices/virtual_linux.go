@@ -0,0 +1,133 @@
+package devices
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// virtioPCIDevices QEMU/virtio 常见设备，键为 "vendor:device"
+var virtioPCIDevices = map[string]string{
+	"1af4:1000": "virtio-net",
+	"1af4:1001": "virtio-blk",
+	"1af4:1041": "virtio-net",
+	"1af4:1042": "virtio-blk",
+	"1af4:1050": "virtio-gpu",
+	"1234:1111": "QEMU VGA",
+	"1b36:0100": "QXL",
+	"15ad:0405": "VMware SVGA II",
+	"80ee:beef": "VirtualBox Graphics Adapter",
+	"80ee:cafe": "VirtualBox Guest Service",
+}
+
+// cgroupContainerMarkers 容器运行时在 cgroup 路径中留下的标识
+var cgroupContainerMarkers = []string{"docker", "kubepods", "containerd", "lxc", "libpod", "crio"}
+
+// platformVirtualEvidence Linux 下的虚拟化证据
+func platformVirtualEvidence() []Evidence {
+	var evidence []Evidence
+	evidence = append(evidence, linuxDMIEvidence()...)
+	evidence = append(evidence, cpuinfoEvidence()...)
+	evidence = append(evidence, pciEvidence()...)
+	evidence = append(evidence, containerEvidence()...)
+	evidence = append(evidence, wslEvidence()...)
+	return evidence
+}
+
+func linuxDMIEvidence() []Evidence {
+	dir := hostPath(dmiDir)
+	fields := make(map[string]string)
+	for _, name := range []string{"sys_vendor", "product_name", "board_vendor", "bios_vendor", "chassis_vendor"} {
+		if value := readSysfs(dir, name); value != "" {
+			fields[name] = value
+		}
+	}
+	return dmiEvidence(fields)
+}
+
+// cpuinfoEvidence 内核检测到运行在 hypervisor 之上时会在 flags 中加入 hypervisor
+// 该标志来自 CPUID 的 hypervisor 位，与 cpuidEvidence 是同一证据，只在无法直接执行 CPUID 的架构上使用
+func cpuinfoEvidence() []Evidence {
+	if _, _, ok := hypervisorCPUID(); ok {
+		return nil
+	}
+	data, err := os.ReadFile(hostPath("proc/cpuinfo"))
+	if err != nil {
+		return nil
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) != "flags" {
+			continue
+		}
+		if slices.Contains(strings.Fields(value), "hypervisor") {
+			return []Evidence{{Signal: "cpuid", Kind: KindVM, Detail: "/proc/cpuinfo 包含 hypervisor 标志", Weight: 30}}
+		}
+		return nil
+	}
+	return nil
+}
+
+// pciEvidence 检查 virtio、QEMU 等虚拟设备，以及 virtio 总线上的设备
+func pciEvidence() []Evidence {
+	var evidence []Evidence
+	devices, _ := filepath.Glob(hostPath("sys/bus/pci/devices/*"))
+	for _, dir := range devices {
+		vendor := strings.TrimPrefix(readSysfs(dir, "vendor"), "0x")
+		device := strings.TrimPrefix(readSysfs(dir, "device"), "0x")
+		if name, ok := virtioPCIDevices[vendor+":"+device]; ok {
+			evidence = append(evidence, Evidence{
+				Signal: "pci",
+				Kind:   KindVM,
+				Detail: fmt.Sprintf("%s %s:%s (%s)", filepath.Base(dir), vendor, device, name),
+				Weight: 35,
+			})
+		}
+	}
+
+	// Firecracker 等使用 virtio-mmio 的虚拟机没有PCI总线
+	if virtio, _ := filepath.Glob(hostPath("sys/bus/virtio/devices/*")); len(virtio) > 0 {
+		evidence = append(evidence, Evidence{
+			Signal: "pci",
+			Kind:   KindVM,
+			Detail: fmt.Sprintf("virtio 总线上有 %d 个设备", len(virtio)),
+			Weight: 35,
+		})
+	}
+	return evidence
+}
+
+// containerEvidence 检查 Docker/Podman 标记文件和 cgroup 路径
+func containerEvidence() []Evidence {
+	var evidence []Evidence
+	for _, marker := range []string{".dockerenv", "run/.containerenv"} {
+		if _, err := os.Stat(hostPath(marker)); err == nil {
+			evidence = append(evidence, Evidence{Signal: "container", Kind: KindContainer, Detail: "/" + marker, Weight: 50})
+		}
+	}
+
+	if data, err := os.ReadFile(hostPath("proc/1/cgroup")); err == nil {
+		cgroup := strings.ToLower(string(data))
+		for _, marker := range cgroupContainerMarkers {
+			if strings.Contains(cgroup, marker) {
+				evidence = append(evidence, Evidence{Signal: "cgroup", Kind: KindContainer, Detail: "cgroup 包含 " + marker, Weight: 40})
+				break
+			}
+		}
+	}
+	return evidence
+}
+
+// wslEvidence WSL 的内核版本包含 microsoft，并提供 WSLInterop
+func wslEvidence() []Evidence {
+	var evidence []Evidence
+	release := strings.ToLower(readSysfs(hostPath("proc/sys/kernel"), "osrelease"))
+	if strings.Contains(release, "microsoft") || strings.Contains(release, "wsl") {
+		evidence = append(evidence, Evidence{Signal: "wsl", Kind: KindWSL, Detail: "内核版本 " + release, Weight: 50})
+	} else if _, err := os.Stat(hostPath("proc/sys/fs/binfmt_misc/WSLInterop")); err == nil {
+		evidence = append(evidence, Evidence{Signal: "wsl", Kind: KindWSL, Detail: "存在 WSLInterop", Weight: 50})
+	}
+	return evidence
+}
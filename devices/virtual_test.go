@@ -0,0 +1,69 @@
+package devices
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewVirtualizationReport(t *testing.T) {
+	tests := []struct {
+		name      string
+		evidence  []Evidence
+		score     int
+		isVirtual bool
+		kinds     []string
+	}{
+		{"none", nil, 0, false, nil},
+		{
+			// 同一检测项只计最高分，/proc/cpuinfo 与 CPUID 的 hypervisor 位是同一证据
+			"same-signal",
+			[]Evidence{
+				{Signal: "cpuid", Kind: KindVM, Detail: "/proc/cpuinfo 包含 hypervisor 标志", Weight: 30},
+				{Signal: "cpuid", Kind: KindVM, Detail: "hypervisor: KVM", Weight: 40},
+			},
+			40, false, []string{KindVM},
+		},
+		{
+			"sum",
+			[]Evidence{
+				{Signal: "dmi", Kind: KindVM, Detail: "sys_vendor: QEMU", Weight: 40},
+				{Signal: "dmi", Kind: KindVM, Detail: "product_name: Standard PC", Weight: 40},
+				{Signal: "mac_oui", Kind: KindVM, Detail: "eth0 52:54:00:12:34:56 (QEMU/KVM)", Weight: 25},
+			},
+			65, true, []string{KindVM},
+		},
+		{
+			// 低分的同项证据不计入类型
+			"kinds-counted-only",
+			[]Evidence{
+				{Signal: "cpuid", Kind: KindVM, Detail: "Hyper-V(可能为启用 VBS 的物理机)", Weight: 10},
+				{Signal: "cpuid", Kind: KindEmulator, Detail: "QEMU TCG 软件模拟", Weight: 50},
+				{Signal: "wsl", Kind: KindWSL, Detail: "存在 WSLInterop", Weight: 50},
+			},
+			100, true, []string{KindEmulator, KindWSL},
+		},
+		{
+			"capped",
+			[]Evidence{
+				{Signal: "container", Kind: KindContainer, Detail: "/.dockerenv", Weight: 50},
+				{Signal: "cgroup", Kind: KindContainer, Detail: "cgroup 包含 docker", Weight: 40},
+				{Signal: "dmi", Kind: KindVM, Detail: "sys_vendor: VMware, Inc.", Weight: 40},
+			},
+			100, true, []string{KindContainer, KindVM},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := newVirtualizationReport(tt.evidence)
+			if report.Score != tt.score || report.IsVirtual != tt.isVirtual {
+				t.Errorf("Score = %d, IsVirtual = %v, want %d, %v", report.Score, report.IsVirtual, tt.score, tt.isVirtual)
+			}
+			if !reflect.DeepEqual(report.Kinds, tt.kinds) {
+				t.Errorf("Kinds = %q, want %q", report.Kinds, tt.kinds)
+			}
+			if len(report.Evidence) != len(tt.evidence) {
+				t.Errorf("Evidence 应保留全部证据, got %d", len(report.Evidence))
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package devices
+
+import (
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// platformVirtualEvidence Windows 下的虚拟化证据
+func platformVirtualEvidence() []Evidence {
+	var evidence []Evidence
+	evidence = append(evidence, registryDMIEvidence()...)
+	evidence = append(evidence, registryPCIEvidence()...)
+	return evidence
+}
+
+// registryDMIEvidence 从注册表读取 SMBIOS 中的厂商和型号
+func registryDMIEvidence() []Evidence {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DESCRIPTION\System\BIOS`, registry.READ)
+	if err != nil {
+		return nil
+	}
+	defer key.Close()
+
+	fields := make(map[string]string)
+	for _, name := range []string{"SystemManufacturer", "SystemProductName", "BaseBoardManufacturer", "BIOSVendor"} {
+		if value, _, err := key.GetStringValue(name); err == nil && value != "" {
+			fields[name] = value
+		}
+	}
+	return dmiEvidence(fields)
+}
+
+// registryPCIEvidence 检查注册表中枚举过的虚拟机厂商PCI设备
+func registryPCIEvidence() []Evidence {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Enum\PCI`, registry.READ)
+	if err != nil {
+		return nil
+	}
+	defer key.Close()
+
+	// VEN_15AD VMware, VEN_80EE VirtualBox, VEN_1AF4 virtio, VEN_1234 QEMU
+	vendors := []string{"ven_15ad", "ven_80ee", "ven_1af4", "ven_1234"}
+	subKeys, _ := key.ReadSubKeyNames(-1)
+	var evidence []Evidence
+	for _, subKey := range subKeys {
+		lower := strings.ToLower(subKey)
+		for _, vendor := range vendors {
+			if strings.Contains(lower, vendor) {
+				evidence = append(evidence, Evidence{Signal: "pci", Kind: KindVM, Detail: subKey, Weight: 35})
+				break
+			}
+		}
+	}
+	return evidence
+}
@@ -0,0 +1,266 @@
+package devices
+
+import (
+	"log"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EventType USB设备事件类型
+type EventType string
+
+const (
+	DeviceAdded   EventType = "added"   // 设备接入
+	DeviceRemoved EventType = "removed" // 设备移除
+	DeviceChanged EventType = "changed" // 存储设备挂载点变化
+)
+
+// DeviceEvent USB设备接入、移除或挂载点变化事件
+type DeviceEvent struct {
+	Type   EventType
+	Device USBDevice
+	Time   time.Time
+}
+
+// EventCallback 设备事件回调
+type EventCallback func(event DeviceEvent)
+
+const (
+	DefaultPollInterval = 3 * time.Second        // 无法订阅内核事件时的轮询间隔
+	ueventSettleDelay   = 500 * time.Millisecond // 收到内核事件后等待设备节点就绪再枚举
+)
+
+// 存储设备接入后自动挂载的时间不确定(桌面环境、加密分区需要输入密码等)，
+// 每隔 storageMountPoll 重新枚举，直到挂载点稳定或超过 storageMountTimeout
+var (
+	storageMountPoll    = time.Second
+	storageMountTimeout = 30 * time.Second
+)
+
+// ueventSource 内核设备事件来源，Receive 超时返回 nil 事件以便检查是否已停止
+type ueventSource interface {
+	Receive() (map[string]string, error)
+	Close() error
+}
+
+// Watcher 监听USB设备热插拔，Linux 下订阅内核 uevent，其他平台或订阅失败时定时轮询
+// 每次事件后重新枚举设备并与上次结果比较，按设备ID发出事件
+type Watcher struct {
+	PollInterval time.Duration
+	OnEvent      EventCallback
+
+	mu      sync.Mutex
+	scanMu  sync.Mutex // 轮询和补充枚举可能同时进行，串行化比较过程
+	stop    chan struct{}
+	trigger chan struct{}
+	known   map[string]USBDevice
+	scan    func() ([]USBDevice, error)
+}
+
+// NewWatcher 创建设备监听器
+func NewWatcher(onEvent EventCallback) *Watcher {
+	return &Watcher{PollInterval: DefaultPollInterval, OnEvent: onEvent, scan: GetUSBDevices}
+}
+
+// Start 记录当前已接入的设备并开始监听，已接入的设备不会产生事件
+func (w *Watcher) Start() {
+	w.mu.Lock()
+	if w.stop != nil {
+		w.mu.Unlock()
+		return
+	}
+	w.stop = make(chan struct{})
+	w.trigger = make(chan struct{}, 1)
+	stop, trigger := w.stop, w.trigger
+	w.mu.Unlock()
+
+	current, err := w.scan()
+	if err != nil {
+		log.Printf("枚举USB设备失败: %v", err)
+	}
+	w.mu.Lock()
+	w.known = indexDevices(current)
+	w.mu.Unlock()
+
+	source, err := openUeventSource()
+	if err != nil {
+		log.Printf("订阅内核设备事件失败，改为每 %v 轮询: %v", w.PollInterval, err)
+		go w.poll(stop)
+	} else {
+		go w.receive(source, stop, trigger)
+	}
+	go w.run(stop, trigger)
+}
+
+// Stop 停止监听
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+}
+
+// Devices 返回最近一次枚举到的设备
+func (w *Watcher) Devices() []USBDevice {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	devices := make([]USBDevice, 0, len(w.known))
+	for _, device := range w.known {
+		devices = append(devices, device)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].DeviceID < devices[j].DeviceID
+	})
+	return devices
+}
+
+// receive 读取内核事件，只关心 USB 和块设备子系统
+func (w *Watcher) receive(source ueventSource, stop, trigger chan struct{}) {
+	defer source.Close()
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		event, err := source.Receive()
+		if err != nil {
+			log.Printf("读取内核设备事件失败，改为轮询: %v", err)
+			w.poll(stop)
+			return
+		}
+		if event == nil {
+			continue
+		}
+		switch event["SUBSYSTEM"] {
+		case "usb", "block":
+			notify(trigger)
+		}
+	}
+}
+
+// poll 定时触发重新枚举
+func (w *Watcher) poll(stop chan struct{}) {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			w.rescan()
+		}
+	}
+}
+
+// run 合并短时间内的多个内核事件后重新枚举
+// 存储设备接入时挂载点通常稍后才出现，接入后持续补充枚举，直到挂载点稳定或超时
+func (w *Watcher) run(stop, trigger chan struct{}) {
+	settle := time.NewTimer(ueventSettleDelay)
+	settle.Stop()
+	defer settle.Stop()
+	mountPoll := time.NewTicker(storageMountPoll)
+	mountPoll.Stop()
+	defer mountPoll.Stop()
+	var mountDeadline time.Time
+
+	for {
+		var storageAdded, mountsChanged bool
+		select {
+		case <-stop:
+			return
+		case <-trigger:
+			settle.Reset(ueventSettleDelay)
+			continue
+		case <-settle.C:
+			storageAdded, _ = w.rescan()
+		case <-mountPoll.C:
+			storageAdded, mountsChanged = w.rescan()
+			// 所有存储设备都已挂载且本次没有变化，或等待超时
+			settled := !mountsChanged && !w.mountsPending()
+			if settled || time.Now().After(mountDeadline) {
+				mountPoll.Stop()
+			}
+		}
+		if storageAdded {
+			mountDeadline = time.Now().Add(storageMountTimeout)
+			mountPoll.Reset(storageMountPoll)
+		}
+	}
+}
+
+// mountsPending 是否有存储设备还没有挂载点
+func (w *Watcher) mountsPending() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, device := range w.known {
+		if device.IsStorageType && len(device.DriveLetters) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rescan 重新枚举并发出变化事件，返回是否有存储设备接入以及是否有挂载点变化
+func (w *Watcher) rescan() (storageAdded, mountsChanged bool) {
+	w.scanMu.Lock()
+	defer w.scanMu.Unlock()
+
+	current, err := w.scan()
+	if err != nil {
+		log.Printf("枚举USB设备失败: %v", err)
+		return false, false
+	}
+	next := indexDevices(current)
+
+	w.mu.Lock()
+	previous := w.known
+	w.known = next
+	w.mu.Unlock()
+
+	now := time.Now()
+	var events []DeviceEvent
+	for id, device := range next {
+		old, ok := previous[id]
+		if !ok {
+			events = append(events, DeviceEvent{Type: DeviceAdded, Device: device, Time: now})
+			storageAdded = storageAdded || device.IsStorageType
+		} else if !slices.Equal(old.DriveLetters, device.DriveLetters) {
+			events = append(events, DeviceEvent{Type: DeviceChanged, Device: device, Time: now})
+			mountsChanged = true
+		}
+	}
+	for id, device := range previous {
+		if _, ok := next[id]; !ok {
+			events = append(events, DeviceEvent{Type: DeviceRemoved, Device: device, Time: now})
+		}
+	}
+
+	if w.OnEvent != nil {
+		for _, event := range events {
+			w.OnEvent(event)
+		}
+	}
+	return storageAdded, mountsChanged
+}
+
+func indexDevices(devices []USBDevice) map[string]USBDevice {
+	index := make(map[string]USBDevice, len(devices))
+	for _, device := range devices {
+		index[device.DeviceID] = device
+	}
+	return index
+}
+
+// notify 非阻塞地发出触发信号，已有待处理的信号时忽略
+func notify(trigger chan struct{}) {
+	select {
+	case trigger <- struct{}{}:
+	default:
+	}
+}
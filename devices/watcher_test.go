@@ -0,0 +1,131 @@
+package devices
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeScanner 按顺序返回预设的枚举结果，用完后一直返回最后一个
+type fakeScanner struct {
+	mu      sync.Mutex
+	results [][]USBDevice
+	calls   int
+}
+
+func (f *fakeScanner) scan() ([]USBDevice, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i := min(f.calls, len(f.results)-1)
+	f.calls++
+	return f.results[i], nil
+}
+
+func (f *fakeScanner) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// runWatcher 在协程中运行事件循环并返回触发通道，测试结束时停止并等待退出
+func runWatcher(t *testing.T, w *Watcher) chan struct{} {
+	t.Helper()
+	stop, trigger := make(chan struct{}), make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.run(stop, trigger)
+	}()
+	t.Cleanup(func() {
+		close(stop)
+		<-done
+	})
+	return trigger
+}
+
+func fastMountPoll(t *testing.T, timeout time.Duration) {
+	t.Helper()
+	poll, previous := storageMountPoll, storageMountTimeout
+	storageMountPoll, storageMountTimeout = 10*time.Millisecond, timeout
+	t.Cleanup(func() {
+		storageMountPoll, storageMountTimeout = poll, previous
+	})
+}
+
+// 挂载点在接入几秒后才出现，补充枚举应持续到挂载点出现并稳定
+func TestWatcherLateMount(t *testing.T) {
+	fastMountPoll(t, 5*time.Second)
+	disk := USBDevice{DeviceID: `USB\VID_0781&PID_5581\4C530001`, IsStorageType: true}
+	mounted := disk
+	mounted.DriveLetters = []string{"/media/student/DATA"}
+
+	scanner := &fakeScanner{results: [][]USBDevice{
+		{disk}, {disk}, {disk}, {disk}, {mounted},
+	}}
+	var (
+		mu     sync.Mutex
+		events []DeviceEvent
+	)
+	w := NewWatcher(func(event DeviceEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	})
+	w.scan = scanner.scan
+	w.known = map[string]USBDevice{}
+
+	trigger := runWatcher(t, w)
+	notify(trigger)
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		mu.Lock()
+		n := len(events)
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("等待挂载点变化超时, %d 个事件", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 挂载点稳定后停止补充枚举
+	time.Sleep(100 * time.Millisecond)
+	calls := scanner.count()
+	time.Sleep(100 * time.Millisecond)
+	if scanner.count() != calls {
+		t.Errorf("挂载点稳定后仍在枚举: %d -> %d", calls, scanner.count())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 || events[0].Type != DeviceAdded || events[1].Type != DeviceChanged ||
+		len(events[1].Device.DriveLetters) != 1 {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+// 一直没有挂载的存储设备，超时后停止补充枚举
+func TestWatcherMountTimeout(t *testing.T) {
+	fastMountPoll(t, 100*time.Millisecond)
+	disk := USBDevice{DeviceID: `USB\VID_0781&PID_5581\4C530001`, IsStorageType: true}
+	scanner := &fakeScanner{results: [][]USBDevice{{disk}}}
+	w := NewWatcher(nil)
+	w.scan = scanner.scan
+	w.known = map[string]USBDevice{}
+
+	trigger := runWatcher(t, w)
+	notify(trigger)
+
+	time.Sleep(ueventSettleDelay + 300*time.Millisecond)
+	calls := scanner.count()
+	if calls < 3 {
+		t.Errorf("存储设备未挂载时应补充枚举, calls = %d", calls)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if scanner.count() != calls {
+		t.Errorf("超时后仍在枚举: %d -> %d", calls, scanner.count())
+	}
+}
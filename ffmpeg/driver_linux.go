@@ -0,0 +1,48 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// Ffmpeg Linux 上使用系统安装的 ffmpeg，可通过 MONITOR_FFMPEG 指定路径
+const Ffmpeg = "ffmpeg"
+
+// UnPack Linux 不内嵌 ffmpeg，只检查系统中是否已安装
+func UnPack() error {
+	if _, err := exec.LookPath(Path()); err != nil {
+		return fmt.Errorf("未找到 ffmpeg，请先安装: %w", err)
+	}
+	return nil
+}
+
+// Path 返回 ffmpeg 可执行文件路径
+func Path() string {
+	if p := os.Getenv("MONITOR_FFMPEG"); p != "" {
+		return p
+	}
+	return Ffmpeg
+}
+
+// screenInput 通过 x11grab 采集当前 X11 显示
+func screenInput(framerate int) []string {
+	display := os.Getenv("DISPLAY")
+	if display == "" {
+		display = ":0"
+	}
+	return []string{"-f", "x11grab", "-framerate", strconv.Itoa(framerate), "-draw_mouse", "1", "-i", display}
+}
+
+// audioInput 通过 PulseAudio(含 PipeWire 兼容层)采集默认麦克风
+func audioInput() []string {
+	return []string{"-f", "pulse", "-i", "default"}
+}
+
+func Version() error {
+	cmd := exec.Command(Path(), "-version")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 )
 
 const Ffmpeg = "lib/ffmpeg/ffmpeg.exe"
@@ -76,6 +77,22 @@ func UnPack() error {
 
 }
 
+// Path 返回解压后的 ffmpeg 绝对路径
+func Path() string {
+	f, _ := filepath.Abs(Ffmpeg)
+	return f
+}
+
+// screenInput 屏幕采集输入参数
+func screenInput(framerate int) []string {
+	return []string{"-f", "gdigrab", "-framerate", strconv.Itoa(framerate), "-i", "desktop"}
+}
+
+// audioInput Windows 上 dshow 需要具体的设备名称，暂不支持采集音频
+func audioInput() []string {
+	return nil
+}
+
 func Version() error {
 	f, _ := filepath.Abs(Ffmpeg)
 	cmd := exec.Command(f, "-version")
@@ -83,40 +100,3 @@ func Version() error {
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
-
-const scale = "scale=1280:720"
-
-func RtmpPushScreen(url string) error {
-	f, _ := filepath.Abs(Ffmpeg)
-	cmd := exec.Command(f,
-		"-f", "gdigrab",
-		"-framerate", "30",
-		"-i", "desktop",
-		"-vf", scale,
-		"-c:v", "libx264",
-		"-tune", "zerolatency",
-		"-crf", "23",
-		"-b:v", "2000k",
-		"-g", "60",
-		"-pix_fmt",
-		"yuv420p", "-an",
-		"-f", "flv",
-		"-vsync", "passthrough",
-		url,
-	)
-
-	cmd.Stdout = io.Discard
-	cmd.Stderr = io.Discard
-
-	// DEBUG
-	//cmd.Stderr = os.Stderr
-	//cmd.Stdout = os.Stdout
-
-	err := cmd.Start()
-	if err != nil {
-		log.Println("Start Process Error", err)
-		return err
-	}
-	log.Println("Start Process")
-	return cmd.Wait()
-}
@@ -0,0 +1,219 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Profile 编码参数预设，只使用软件编码(libx264)，不依赖显卡
+type Profile struct {
+	Name         string
+	Framerate    int
+	Width        int    // 输出宽度，0表示不缩放
+	Height       int    // 输出高度，0表示不缩放
+	Preset       string // x264 预设: ultrafast / veryfast / medium ...
+	Tune         string // x264 调优，例如 zerolatency，为空时不设置
+	CRF          int
+	Bitrate      string  // 目标码率，例如 2000k，为空时只使用 CRF
+	KeyframeSecs float64 // 关键帧间隔(秒)
+	Audio        bool    // 是否同时采集麦克风
+	AudioBitrate string
+}
+
+// 内置编码预设名称
+const (
+	ProfileLowBandwidth    = "low-bandwidth"
+	ProfileStandard        = "standard"
+	ProfileEvidenceQuality = "evidence-quality"
+	ProfileRecording       = "recording"
+)
+
+// Profiles 内置编码预设
+// standard 沿用原先推流的分辨率、帧率、CRF 和关键帧间隔，但有以下不同:
+// 码率改为 maxrate/bufsize 峰值限制(CRF 模式下 -b:v 不生效)，使用 veryfast 预设，不再设置 -vsync passthrough
+// recording 为本地分段录屏使用的低帧率预设
+var Profiles = map[string]Profile{
+	ProfileLowBandwidth: {
+		Name:         ProfileLowBandwidth,
+		Framerate:    10,
+		Width:        854,
+		Height:       480,
+		Preset:       "ultrafast",
+		Tune:         "zerolatency",
+		CRF:          30,
+		Bitrate:      "500k",
+		KeyframeSecs: 4,
+	},
+	ProfileStandard: {
+		Name:         ProfileStandard,
+		Framerate:    30,
+		Width:        1280,
+		Height:       720,
+		Preset:       "veryfast",
+		Tune:         "zerolatency",
+		CRF:          23,
+		Bitrate:      "2000k",
+		KeyframeSecs: 2,
+	},
+	ProfileEvidenceQuality: {
+		Name:         ProfileEvidenceQuality,
+		Framerate:    15,
+		Preset:       "medium",
+		CRF:          18,
+		KeyframeSecs: 2,
+		Audio:        true,
+		AudioBitrate: "96k",
+	},
+	ProfileRecording: {
+		Name:         ProfileRecording,
+		Framerate:    5,
+		Width:        1280,
+		Height:       720,
+		Preset:       "veryfast",
+		CRF:          28,
+		KeyframeSecs: 2,
+	},
+}
+
+// LookupProfile 按名称查找编码预设，未知名称返回 standard
+func LookupProfile(name string) Profile {
+	if p, ok := Profiles[name]; ok {
+		return p
+	}
+	return Profiles[ProfileStandard]
+}
+
+// Protocol 输出协议
+type Protocol string
+
+const (
+	ProtocolRTMP Protocol = "rtmp"
+	ProtocolSRT  Protocol = "srt"
+	ProtocolFile Protocol = "file"
+	ProtocolHLS  Protocol = "hls"
+)
+
+// Output 输出目标
+type Output struct {
+	Protocol Protocol
+	Target   string // 推流地址或文件路径，HLS 为 .m3u8 播放列表路径
+	HLSTime  int    // HLS 分片时长(秒)，默认4秒
+}
+
+// OutputFor 根据地址推断输出协议: rtmp(s)://、srt://、.m3u8 文件，其余视为普通文件
+func OutputFor(target string) Output {
+	lower := strings.ToLower(target)
+	switch {
+	case strings.HasPrefix(lower, "rtmp://"), strings.HasPrefix(lower, "rtmps://"):
+		return Output{Protocol: ProtocolRTMP, Target: target}
+	case strings.HasPrefix(lower, "srt://"):
+		return Output{Protocol: ProtocolSRT, Target: target}
+	case strings.HasSuffix(lower, ".m3u8"):
+		return Output{Protocol: ProtocolHLS, Target: target}
+	default:
+		return Output{Protocol: ProtocolFile, Target: target}
+	}
+}
+
+// BuildArgs 按预设生成从屏幕采集到输出的完整 ffmpeg 参数
+func (p Profile) BuildArgs(output Output) ([]string, error) {
+	outputArgs, err := output.args()
+	if err != nil {
+		return nil, err
+	}
+	return append(p.encodeArgs(), outputArgs...), nil
+}
+
+// encodeArgs 采集输入和编码参数，不含输出
+func (p Profile) encodeArgs() []string {
+	return p.withAudioInput(audioInput())
+}
+
+// withAudioInput 使用给定的音频输入生成采集和编码参数
+// 当前平台无法采集音频时去掉音频并记录警告，只推送画面
+func (p Profile) withAudioInput(audio []string) []string {
+	if p.Audio && audio == nil {
+		log.Printf("当前平台不支持采集音频，编码预设 %s 只采集画面", p.Name)
+		p.Audio = false
+	}
+	args := screenInput(p.Framerate)
+	if p.Audio {
+		args = append(args, audio...)
+	}
+	return append(args, p.codecArgs()...)
+}
+
+// codecArgs 视频和音频编码参数
+func (p Profile) codecArgs() []string {
+	var args []string
+	if p.Width > 0 && p.Height > 0 {
+		args = append(args, "-vf", fmt.Sprintf("scale=%d:%d", p.Width, p.Height))
+	}
+	args = append(args, "-c:v", "libx264")
+	if p.Preset != "" {
+		args = append(args, "-preset", p.Preset)
+	}
+	if p.Tune != "" {
+		args = append(args, "-tune", p.Tune)
+	}
+	if p.CRF > 0 {
+		args = append(args, "-crf", strconv.Itoa(p.CRF))
+	}
+	if p.Bitrate != "" {
+		// CRF 模式下 -b:v 不生效，用 maxrate/bufsize 限制峰值码率
+		args = append(args, "-maxrate", p.Bitrate, "-bufsize", p.Bitrate)
+	}
+	if p.KeyframeSecs > 0 && p.Framerate > 0 {
+		gop := max(int(p.KeyframeSecs*float64(p.Framerate)), 1)
+		args = append(args, "-g", strconv.Itoa(gop), "-keyint_min", strconv.Itoa(gop))
+	}
+	args = append(args, "-pix_fmt", "yuv420p")
+
+	if p.Audio {
+		args = append(args, "-c:a", "aac")
+		if p.AudioBitrate != "" {
+			args = append(args, "-b:a", p.AudioBitrate)
+		}
+	} else {
+		args = append(args, "-an")
+	}
+	return args
+}
+
+// args 输出参数
+func (o Output) args() ([]string, error) {
+	if o.Target == "" {
+		return nil, fmt.Errorf("未指定输出地址")
+	}
+
+	switch o.Protocol {
+	case ProtocolRTMP:
+		return []string{"-f", "flv", o.Target}, nil
+	case ProtocolSRT:
+		return []string{"-f", "mpegts", o.Target}, nil
+	case ProtocolHLS:
+		hlsTime := o.HLSTime
+		if hlsTime <= 0 {
+			hlsTime = 4
+		}
+		segments := strings.TrimSuffix(o.Target, filepath.Ext(o.Target)) + "_%05d.ts"
+		return []string{
+			"-f", "hls",
+			"-hls_time", strconv.Itoa(hlsTime),
+			"-hls_list_size", "0",
+			"-hls_segment_filename", segments,
+			o.Target,
+		}, nil
+	case ProtocolFile:
+		// 由扩展名决定封装格式，mp4 使用分片写入，异常退出时已写入的部分仍可播放
+		if strings.EqualFold(filepath.Ext(o.Target), ".mp4") {
+			return []string{"-movflags", "+frag_keyframe+empty_moov", "-y", o.Target}, nil
+		}
+		return []string{"-y", o.Target}, nil
+	default:
+		return nil, fmt.Errorf("不支持的输出协议: %s", o.Protocol)
+	}
+}
@@ -0,0 +1,195 @@
+package ffmpeg
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCodecArgs(t *testing.T) {
+	tests := []struct {
+		profile string
+		want    []string
+	}{
+		{ProfileLowBandwidth, []string{
+			"-vf", "scale=854:480", "-c:v", "libx264", "-preset", "ultrafast", "-tune", "zerolatency",
+			"-crf", "30", "-maxrate", "500k", "-bufsize", "500k", "-g", "40", "-keyint_min", "40",
+			"-pix_fmt", "yuv420p", "-an",
+		}},
+		{ProfileStandard, []string{
+			"-vf", "scale=1280:720", "-c:v", "libx264", "-preset", "veryfast", "-tune", "zerolatency",
+			"-crf", "23", "-maxrate", "2000k", "-bufsize", "2000k", "-g", "60", "-keyint_min", "60",
+			"-pix_fmt", "yuv420p", "-an",
+		}},
+		{ProfileEvidenceQuality, []string{
+			"-c:v", "libx264", "-preset", "medium", "-crf", "18", "-g", "30", "-keyint_min", "30",
+			"-pix_fmt", "yuv420p", "-c:a", "aac", "-b:a", "96k",
+		}},
+		{ProfileRecording, []string{
+			"-vf", "scale=1280:720", "-c:v", "libx264", "-preset", "veryfast", "-crf", "28",
+			"-g", "10", "-keyint_min", "10", "-pix_fmt", "yuv420p", "-an",
+		}},
+	}
+	if len(tests) != len(Profiles) {
+		t.Fatalf("内置预设 %d 个，测试覆盖 %d 个", len(Profiles), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			p, ok := Profiles[tt.profile]
+			if !ok {
+				t.Fatalf("缺少预设 %s", tt.profile)
+			}
+			if got := p.codecArgs(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("codecArgs() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupProfile(t *testing.T) {
+	if p := LookupProfile(ProfileLowBandwidth); p.Name != ProfileLowBandwidth {
+		t.Errorf("LookupProfile(%q).Name = %q", ProfileLowBandwidth, p.Name)
+	}
+	if p := LookupProfile("unknown"); p.Name != ProfileStandard {
+		t.Errorf("未知预设应返回 standard, got %q", p.Name)
+	}
+}
+
+func TestOutputFor(t *testing.T) {
+	tests := []struct {
+		target string
+		want   Protocol
+	}{
+		{"rtmp://live.example.com/app/key", ProtocolRTMP},
+		{"RTMPS://live.example.com/app/key", ProtocolRTMP},
+		{"srt://live.example.com:9000?streamid=key", ProtocolSRT},
+		{"/var/spool/live/index.m3u8", ProtocolHLS},
+		{"/var/spool/live/INDEX.M3U8", ProtocolHLS},
+		{"/tmp/evidence.mp4", ProtocolFile},
+		{"evidence.mkv", ProtocolFile},
+	}
+	for _, tt := range tests {
+		got := OutputFor(tt.target)
+		if got.Protocol != tt.want || got.Target != tt.target {
+			t.Errorf("OutputFor(%q) = %+v, want protocol %s", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestOutputArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  Output
+		want    []string
+		wantErr bool
+	}{
+		{"rtmp", OutputFor("rtmp://live/app/key"), []string{"-f", "flv", "rtmp://live/app/key"}, false},
+		{"srt", OutputFor("srt://live:9000"), []string{"-f", "mpegts", "srt://live:9000"}, false},
+		{"hls", OutputFor("/spool/index.m3u8"), []string{
+			"-f", "hls", "-hls_time", "4", "-hls_list_size", "0",
+			"-hls_segment_filename", "/spool/index_%05d.ts", "/spool/index.m3u8",
+		}, false},
+		{"hls-time", Output{Protocol: ProtocolHLS, Target: "/spool/index.m3u8", HLSTime: 6}, []string{
+			"-f", "hls", "-hls_time", "6", "-hls_list_size", "0",
+			"-hls_segment_filename", "/spool/index_%05d.ts", "/spool/index.m3u8",
+		}, false},
+		{"mp4", OutputFor("/tmp/a.MP4"), []string{"-movflags", "+frag_keyframe+empty_moov", "-y", "/tmp/a.MP4"}, false},
+		{"mkv", OutputFor("/tmp/a.mkv"), []string{"-y", "/tmp/a.mkv"}, false},
+		{"empty", Output{Protocol: ProtocolRTMP}, nil, true},
+		{"unknown", Output{Protocol: "udp", Target: "udp://live"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.output.args()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("args() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("args() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildArgs(t *testing.T) {
+	targets := []string{"rtmp://live/app/key", "srt://live:9000", "/spool/index.m3u8", "/tmp/evidence.mp4"}
+	for name, p := range Profiles {
+		for _, target := range targets {
+			output := OutputFor(target)
+			t.Run(name+"/"+string(output.Protocol), func(t *testing.T) {
+				got, err := p.BuildArgs(output)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := p.withAudioInput(audioInput())
+				outputArgs, _ := output.args()
+				want = append(want, outputArgs...)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("BuildArgs() =\n%q\nwant\n%q", got, want)
+				}
+			})
+		}
+	}
+}
+
+// 无法采集音频的平台去掉音频，只推送画面
+func TestWithAudioInput(t *testing.T) {
+	p := Profiles[ProfileEvidenceQuality]
+	microphone := []string{"-f", "pulse", "-i", "default"}
+	tests := []struct {
+		name  string
+		audio []string
+		want  []string
+	}{
+		{"audio", microphone, append(append(screenInput(p.Framerate), microphone...), p.codecArgs()...)},
+		{"no-input", nil, append(screenInput(p.Framerate), Profile{Name: p.Name, Framerate: p.Framerate, Preset: p.Preset, CRF: p.CRF, KeyframeSecs: p.KeyframeSecs}.codecArgs()...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.withAudioInput(tt.audio)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withAudioInput() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+	if got := strings.Join(p.withAudioInput(nil), " "); !strings.HasSuffix(got, "-an") || strings.Contains(got, "-c:a") {
+		t.Errorf("没有音频输入时应使用 -an: %s", got)
+	}
+}
+
+func TestRecorderArgs(t *testing.T) {
+	spool := filepath.Join("spool", "recording")
+	r := NewRecorder(RecorderConfig{SpoolDir: spool, SegmentDuration: 30 * time.Second, Container: "mkv"}, nil)
+	if r.Config().Profile.Name != ProfileRecording {
+		t.Errorf("默认录屏预设 = %q, want %q", r.Config().Profile.Name, ProfileRecording)
+	}
+
+	got := r.Args()
+	want := append(screenInput(5), Profiles[ProfileRecording].codecArgs()...)
+	want = append(want,
+		"-f", "segment",
+		"-segment_time", "30",
+		"-segment_format", "mkv",
+		"-reset_timestamps", "1",
+		"-strftime", "1",
+		"-segment_list", "pipe:1",
+		"-segment_list_type", "flat",
+		filepath.Join(spool, "recording_%Y%m%d%H%M%S.mkv"),
+	)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Args() =\n%q\nwant\n%q", got, want)
+	}
+
+	// 录屏同样使用预设中的编码参数
+	r = NewRecorder(RecorderConfig{SpoolDir: spool, Profile: Profiles[ProfileLowBandwidth]}, nil)
+	got = r.Args()
+	if args := strings.Join(got, " "); !strings.Contains(args, "-preset ultrafast") || !strings.Contains(args, "-crf 30") {
+		t.Errorf("Args() 未使用 low-bandwidth 预设: %s", args)
+	}
+	if !reflect.DeepEqual(got[:len(screenInput(10))], screenInput(10)) {
+		t.Errorf("Args() 采集帧率未使用预设: %q", got)
+	}
+}
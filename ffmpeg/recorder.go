@@ -0,0 +1,249 @@
+package ffmpeg
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RecorderConfig 本地录屏配置
+type RecorderConfig struct {
+	SpoolDir        string        // 分段文件存放目录
+	SegmentDuration time.Duration // 每个分段的时长
+	Container       string        // 分段容器: mp4 / mkv
+	MaxDiskUsage    int64         // 目录内分段文件总大小上限(字节)，超过时删除最旧的分段，0表示不限制
+	Profile         Profile       // 编码预设，未设置时使用 recording 预设
+	Prefix          string        // 分段文件名前缀
+}
+
+// DefaultRecorderConfig 默认录屏配置: recording 预设(5fps、720p)、每段1分钟、最多占用1GB
+var DefaultRecorderConfig = RecorderConfig{
+	SpoolDir:        filepath.Join(os.TempDir(), "monitor-recording"),
+	SegmentDuration: time.Minute,
+	Container:       "mp4",
+	MaxDiskUsage:    1 << 30,
+	Profile:         Profiles[ProfileRecording],
+	Prefix:          "recording",
+}
+
+// StopTimeout 停止录制时等待 ffmpeg 写完当前分段的时间
+const StopTimeout = 10 * time.Second
+
+// RecorderName 录屏进程在 ffmpeg 状态中的名称
+const RecorderName = "recording"
+
+// RecorderMaxRestarts 录屏 ffmpeg 连续异常退出的最大重启次数，超过后状态为 failed
+const RecorderMaxRestarts = 10
+
+// ErrRecorderRunning 录制已在进行中
+var ErrRecorderRunning = errors.New("录屏已在进行中")
+
+// SegmentCallback 分段写完后的回调，参数为分段文件的完整路径
+type SegmentCallback func(path string)
+
+// Recorder 使用 ffmpeg 分段录屏，分段写完后交给回调上传
+// ffmpeg 由 Supervisor 托管，考试中途异常退出时按退避间隔重启，状态通过 SetStatusCallback 上报
+type Recorder struct {
+	config    RecorderConfig
+	onSegment SegmentCallback
+
+	mu         sync.Mutex
+	supervisor *Supervisor
+}
+
+// NewRecorder 创建录屏器，未设置的配置项使用默认值
+func NewRecorder(config RecorderConfig, onSegment SegmentCallback) *Recorder {
+	if config.SpoolDir == "" {
+		config.SpoolDir = DefaultRecorderConfig.SpoolDir
+	}
+	if config.SegmentDuration <= 0 {
+		config.SegmentDuration = DefaultRecorderConfig.SegmentDuration
+	}
+	if config.Container != "mkv" {
+		config.Container = "mp4"
+	}
+	if config.Profile.Framerate <= 0 {
+		config.Profile = DefaultRecorderConfig.Profile
+	}
+	if config.Prefix == "" {
+		config.Prefix = DefaultRecorderConfig.Prefix
+	}
+	return &Recorder{config: config, onSegment: onSegment}
+}
+
+// Config 返回录屏配置
+func (r *Recorder) Config() RecorderConfig {
+	return r.config
+}
+
+// Args 按编码预设返回录屏的 ffmpeg 参数，完成的分段文件名通过 segment_list 写到标准输出
+func (r *Recorder) Args() []string {
+	return append(r.config.Profile.encodeArgs(),
+		"-f", "segment",
+		"-segment_time", strconv.Itoa(int(r.config.SegmentDuration.Seconds())),
+		"-segment_format", r.config.Container,
+		"-reset_timestamps", "1",
+		"-strftime", "1",
+		"-segment_list", "pipe:1",
+		"-segment_list_type", "flat",
+		filepath.Join(r.config.SpoolDir, r.config.Prefix+"_%Y%m%d%H%M%S."+r.config.Container),
+	)
+}
+
+// Start 启动录制，同时把上次异常退出遗留的分段交给回调
+func (r *Recorder) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.supervisor != nil {
+		return ErrRecorderRunning
+	}
+
+	args := r.Args()
+	if err := os.MkdirAll(r.config.SpoolDir, 0755); err != nil {
+		return fmt.Errorf("创建录屏目录失败: %w", err)
+	}
+	leftovers := r.segments()
+
+	supervisor := NewSupervisor(RecorderName, args)
+	supervisor.MaxRestarts = RecorderMaxRestarts
+	supervisor.OnOutput = r.segmentListed
+	if err := supervisor.Start(); err != nil {
+		return fmt.Errorf("启动 ffmpeg 失败: %w", err)
+	}
+	log.Printf("开始录屏，分段目录: %s", r.config.SpoolDir)
+	r.supervisor = supervisor
+
+	go func() {
+		for _, s := range leftovers {
+			r.completeSegment(s.path)
+		}
+	}()
+	go r.wait(supervisor)
+	return nil
+}
+
+// segmentListed 处理 segment_list 输出的已写完分段
+func (r *Recorder) segmentListed(name string) {
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(r.config.SpoolDir, filepath.Base(name))
+	}
+	r.completeSegment(name)
+}
+
+// wait 托管结束(主动停止或重启次数过多)后清理状态
+func (r *Recorder) wait(supervisor *Supervisor) {
+	<-supervisor.Done()
+	status := supervisor.Status()
+	if status.State == StateFailed {
+		log.Printf("录屏已停止，ffmpeg 多次异常退出: %s", status.LastError)
+	} else {
+		log.Println("录屏进程已退出")
+	}
+
+	r.mu.Lock()
+	if r.supervisor == supervisor {
+		r.supervisor = nil
+	}
+	r.mu.Unlock()
+}
+
+// completeSegment 清理超出磁盘上限的旧分段，再把新分段交给回调
+func (r *Recorder) completeSegment(path string) {
+	r.enforceDiskLimit(path)
+	if r.onSegment != nil {
+		r.onSegment(path)
+	}
+}
+
+// Stop 通知 ffmpeg 正常结束以写完当前分段，超时后强制结束
+// ffmpeg 收到 q 后会正常收尾，mp4 需要写入索引才能播放
+func (r *Recorder) Stop() {
+	r.mu.Lock()
+	supervisor := r.supervisor
+	r.mu.Unlock()
+	if supervisor == nil {
+		return
+	}
+	supervisor.Stop()
+}
+
+// Running 是否正在录制
+func (r *Recorder) Running() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.supervisor != nil
+}
+
+// Status 返回录屏 ffmpeg 进程状态，未在录制时为 stopped
+func (r *Recorder) Status() Status {
+	r.mu.Lock()
+	supervisor := r.supervisor
+	r.mu.Unlock()
+	if supervisor == nil {
+		return Status{Name: RecorderName, State: StateStopped}
+	}
+	return supervisor.Status()
+}
+
+// segmentFile 录屏目录中的分段文件
+type segmentFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// segments 按修改时间从旧到新列出录屏目录中的分段文件
+func (r *Recorder) segments() []segmentFile {
+	pattern := filepath.Join(r.config.SpoolDir, r.config.Prefix+"_*."+r.config.Container)
+	matches, _ := filepath.Glob(pattern)
+
+	files := make([]segmentFile, 0, len(matches))
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil {
+			continue
+		}
+		files = append(files, segmentFile{path: m, size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	return files
+}
+
+// enforceDiskLimit 分段总大小超过上限时从最旧的开始删除
+// keep 为刚写完的分段，最新的分段可能正在写入，这两个都不会被删除
+func (r *Recorder) enforceDiskLimit(keep string) {
+	if r.config.MaxDiskUsage <= 0 {
+		return
+	}
+
+	files := r.segments()
+	if len(files) < 2 {
+		return
+	}
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	for _, f := range files[:len(files)-1] {
+		if total <= r.config.MaxDiskUsage {
+			return
+		}
+		if f.path == keep {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil {
+			log.Printf("删除旧录屏分段失败: %v", err)
+			continue
+		}
+		log.Printf("录屏目录超过上限，删除旧分段: %s", f.path)
+		total -= f.size
+	}
+}
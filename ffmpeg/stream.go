@@ -0,0 +1,11 @@
+package ffmpeg
+
+// NewScreenPush 按编码预设创建托管的屏幕推流进程，断流后自动重启
+// 输出协议由地址推断，支持 RTMP、SRT、HLS 和本地文件
+func NewScreenPush(name string, target string, profile string) (*Supervisor, error) {
+	args, err := LookupProfile(profile).BuildArgs(OutputFor(target))
+	if err != nil {
+		return nil, err
+	}
+	return NewSupervisor(name, args), nil
+}
@@ -0,0 +1,400 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jpillora/backoff"
+)
+
+// State ffmpeg 进程状态
+type State string
+
+const (
+	StateStarting   State = "starting"   // 进程已启动，尚未收到进度
+	StateRunning    State = "running"    // 正常输出进度
+	StateRestarting State = "restarting" // 异常退出，等待重启
+	StateStopped    State = "stopped"    // 已主动停止
+	StateFailed     State = "failed"     // 超过最大重启次数
+)
+
+// Progress ffmpeg -progress 输出的进度信息
+type Progress struct {
+	Frame      int64   `json:"frame"`
+	FPS        float64 `json:"fps"`
+	Bitrate    float64 `json:"bitrate"` // kbit/s
+	DropFrames int64   `json:"dropFrames"`
+	DupFrames  int64   `json:"dupFrames"`
+	OutTime    string  `json:"outTime"`
+	Speed      string  `json:"speed"`
+}
+
+// Status ffmpeg 进程的当前状态
+type Status struct {
+	Name      string    `json:"name"`
+	State     State     `json:"state"`
+	PID       int       `json:"pid"`
+	Restarts  int       `json:"restarts"`
+	StartedAt time.Time `json:"startedAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	LastError string    `json:"lastError,omitempty"`
+	Progress  Progress  `json:"progress"`
+}
+
+// StatusCallback 进程状态变化回调
+type StatusCallback func(status Status)
+
+const (
+	RestartMinDelay = time.Second
+	RestartMaxDelay = time.Minute
+	// StableRunTime 运行超过该时间后退出视为偶发故障，重启间隔从最小值重新计算
+	StableRunTime = 30 * time.Second
+	// ProgressNotifyInterval 状态不变时进度回调的最小间隔，避免每个进度块都通知前端和服务器
+	ProgressNotifyInterval = 5 * time.Second
+)
+
+// ErrSupervisorRunning 进程已在运行
+var ErrSupervisorRunning = errors.New("ffmpeg 进程已在运行")
+
+// 全局状态回调和运行中的进程，用于通过 IPC 和 WebSocket 查询状态、登出时统一停止
+var (
+	statusCallback StatusCallback
+	supervisorsMu  sync.Mutex
+	supervisors    = make(map[string]*Supervisor)
+)
+
+// SetStatusCallback 设置所有 ffmpeg 进程的状态变化回调
+func SetStatusCallback(callback StatusCallback) {
+	supervisorsMu.Lock()
+	defer supervisorsMu.Unlock()
+	statusCallback = callback
+}
+
+// Statuses 返回所有运行中 ffmpeg 进程的状态
+func Statuses() []Status {
+	supervisorsMu.Lock()
+	defer supervisorsMu.Unlock()
+	result := make([]Status, 0, len(supervisors))
+	for _, s := range supervisors {
+		result = append(result, s.Status())
+	}
+	return result
+}
+
+// StopAll 停止所有运行中的 ffmpeg 进程
+func StopAll() {
+	supervisorsMu.Lock()
+	list := make([]*Supervisor, 0, len(supervisors))
+	for _, s := range supervisors {
+		list = append(list, s)
+	}
+	supervisorsMu.Unlock()
+
+	for _, s := range list {
+		s.Stop()
+	}
+}
+
+// Supervisor 托管一个 ffmpeg 进程: 解析进度、异常退出后按退避间隔重启、主动停止时正常收尾
+type Supervisor struct {
+	Name        string
+	Binary      string // ffmpeg 路径，为空时使用 Path()，测试时可指定模拟脚本
+	Args        []string
+	MaxRestarts int // 最大重启次数，0表示不限制
+	// OnOutput 标准输出中不属于进度信息的行，例如 -segment_list pipe:1 输出的分段文件名
+	// ffmpeg 每个进度块和每条分段记录都是一次写入，不会在行内交错
+	OnOutput func(line string)
+	// Redact 记录 LastError 前的脱敏处理，ffmpeg 的错误输出通常包含带令牌的推流地址
+	Redact func(text string) string
+
+	mu         sync.Mutex
+	status     Status
+	lastNotify time.Time
+	cmd        *exec.Cmd
+	stdin      io.WriteCloser
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// NewSupervisor 创建 ffmpeg 进程托管，name 在运行中的进程之间唯一
+func NewSupervisor(name string, args []string) *Supervisor {
+	return &Supervisor{
+		Name:   name,
+		Args:   args,
+		status: Status{Name: name, State: StateStopped},
+	}
+}
+
+// Start 启动进程并在后台托管
+func (s *Supervisor) Start() error {
+	supervisorsMu.Lock()
+	defer supervisorsMu.Unlock()
+	if _, ok := supervisors[s.Name]; ok {
+		return ErrSupervisorRunning
+	}
+	supervisors[s.Name] = s
+
+	s.mu.Lock()
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.status = Status{Name: s.Name, State: StateStarting}
+	s.mu.Unlock()
+
+	go s.run()
+	return nil
+}
+
+// Stop 通知 ffmpeg 正常退出并等待托管结束，超时后强制结束
+func (s *Supervisor) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	if stop == nil {
+		s.mu.Unlock()
+		return
+	}
+	select {
+	case <-stop:
+	default:
+		close(stop)
+	}
+	cmd, stdin := s.cmd, s.stdin
+	s.mu.Unlock()
+
+	if cmd != nil {
+		_, _ = io.WriteString(stdin, "q")
+		select {
+		case <-done:
+			return
+		case <-time.After(StopTimeout):
+			log.Printf("ffmpeg[%s] 未能及时退出，强制结束", s.Name)
+			_ = cmd.Process.Kill()
+		}
+	}
+	<-done
+}
+
+// Done 托管结束时关闭
+func (s *Supervisor) Done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done
+}
+
+// Status 返回当前状态
+func (s *Supervisor) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// run 启动进程直到主动停止或超过最大重启次数
+func (s *Supervisor) run() {
+	defer func() {
+		supervisorsMu.Lock()
+		delete(supervisors, s.Name)
+		supervisorsMu.Unlock()
+		close(s.done)
+	}()
+
+	b := &backoff.Backoff{Min: RestartMinDelay, Max: RestartMaxDelay, Factor: 2, Jitter: true}
+	for {
+		started := time.Now()
+		err := s.runOnce()
+
+		if s.stopping() {
+			s.update(func(st *Status) { st.State = StateStopped; st.PID = 0 })
+			return
+		}
+		if time.Since(started) > StableRunTime {
+			b.Reset()
+		}
+
+		status := s.update(func(st *Status) {
+			st.PID = 0
+			st.Restarts++
+			if err != nil {
+				st.LastError = err.Error()
+				if s.Redact != nil {
+					st.LastError = s.Redact(st.LastError)
+				}
+			}
+			st.State = StateRestarting
+		})
+		if s.MaxRestarts > 0 && status.Restarts > s.MaxRestarts {
+			log.Printf("ffmpeg[%s] 重启次数过多，放弃: %s", s.Name, status.LastError)
+			s.update(func(st *Status) { st.State = StateFailed })
+			return
+		}
+
+		delay := b.Duration()
+		log.Printf("ffmpeg[%s] 异常退出: %s，%v 后重启", s.Name, status.LastError, delay)
+		select {
+		case <-s.stop:
+			s.update(func(st *Status) { st.State = StateStopped })
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// runOnce 运行一次 ffmpeg 直到退出
+func (s *Supervisor) runOnce() error {
+	binary := s.Binary
+	if binary == "" {
+		binary = Path()
+	}
+	// 进度以 key=value 形式输出到标准输出，-nostats 关闭标准错误上的进度行
+	args := append([]string{"-nostats", "-progress", "pipe:1"}, s.Args...)
+	cmd := exec.Command(binary, args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	// 在持有锁时检查，避免与 Stop 交错导致新进程收不到退出信号
+	select {
+	case <-s.stop:
+		s.mu.Unlock()
+		return nil
+	default:
+	}
+	if err := cmd.Start(); err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("启动 ffmpeg 失败: %w", err)
+	}
+	s.cmd, s.stdin = cmd, stdin
+	s.mu.Unlock()
+
+	s.update(func(st *Status) {
+		st.State = StateStarting
+		st.PID = cmd.Process.Pid
+		st.StartedAt = time.Now()
+		st.Progress = Progress{}
+	})
+
+	lastLine := make(chan string, 1)
+	go func() {
+		lastLine <- tailLine(stderr)
+	}()
+	s.readProgress(stdout)
+	line := <-lastLine
+
+	// 两个管道都读完后才能 Wait
+	err = cmd.Wait()
+	s.mu.Lock()
+	s.cmd, s.stdin = nil, nil
+	s.mu.Unlock()
+
+	if err != nil && line != "" {
+		err = fmt.Errorf("%w: %s", err, line)
+	}
+	if err == nil && !s.stopping() {
+		err = errors.New("ffmpeg 意外结束")
+	}
+	return err
+}
+
+// readProgress 解析 -progress 输出，每个进度块以 progress=continue/end 结束
+func (s *Supervisor) readProgress(r io.Reader) {
+	var p Progress
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			if line != "" && s.OnOutput != nil {
+				s.OnOutput(line)
+			}
+			continue
+		}
+		switch key {
+		case "frame":
+			p.Frame, _ = strconv.ParseInt(value, 10, 64)
+		case "fps":
+			p.FPS, _ = strconv.ParseFloat(value, 64)
+		case "bitrate":
+			p.Bitrate, _ = strconv.ParseFloat(strings.TrimSuffix(value, "kbits/s"), 64)
+		case "drop_frames":
+			p.DropFrames, _ = strconv.ParseInt(value, 10, 64)
+		case "dup_frames":
+			p.DupFrames, _ = strconv.ParseInt(value, 10, 64)
+		case "out_time":
+			p.OutTime = value
+		case "speed":
+			p.Speed = strings.TrimSpace(value)
+		case "progress":
+			progress := p
+			s.update(func(st *Status) {
+				st.State = StateRunning
+				st.Progress = progress
+			})
+		}
+	}
+}
+
+// tailLine 读取并丢弃标准错误，返回最后一行非空输出作为错误原因
+func tailLine(r io.Reader) string {
+	var last string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			last = line
+		}
+	}
+	return last
+}
+
+// stopping 是否已请求停止
+func (s *Supervisor) stopping() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// update 修改状态并通知回调，状态未变化时按 ProgressNotifyInterval 限流
+func (s *Supervisor) update(change func(st *Status)) Status {
+	s.mu.Lock()
+	previous := s.status.State
+	change(&s.status)
+	now := time.Now()
+	s.status.UpdatedAt = now
+	status := s.status
+	notify := status.State != previous || now.Sub(s.lastNotify) >= ProgressNotifyInterval
+	if notify {
+		s.lastNotify = now
+	}
+	s.mu.Unlock()
+
+	if !notify {
+		return status
+	}
+
+	supervisorsMu.Lock()
+	callback := statusCallback
+	supervisorsMu.Unlock()
+	if callback != nil {
+		callback(status)
+	}
+	return status
+}
@@ -0,0 +1,173 @@
+package ffmpeg
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSupervisor 使用 test/fake-ffmpeg.sh 代替真实的 ffmpeg
+func fakeSupervisor(t *testing.T, name string) *Supervisor {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟脚本需要 bash")
+	}
+	script, err := filepath.Abs("../test/fake-ffmpeg.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSupervisor(name, nil)
+	s.Binary = script
+	t.Cleanup(s.Stop)
+	return s
+}
+
+// waitFor 等待条件成立，超时后测试失败
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待超时: %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestReadProgress(t *testing.T) {
+	s := NewSupervisor("test-read-progress", nil)
+	input := strings.Join([]string{
+		"frame=120",
+		"fps=29.97",
+		"bitrate=1987.5kbits/s",
+		"drop_frames=3",
+		"dup_frames=1",
+		"out_time=00:00:04.000000",
+		"speed=1.01x ",
+		"progress=continue",
+		"frame=135",
+		"bitrate=N/A",
+	}, "\n")
+
+	s.readProgress(strings.NewReader(input))
+
+	// 未结束的进度块不应生效
+	got := s.Status()
+	want := Progress{Frame: 120, FPS: 29.97, Bitrate: 1987.5, DropFrames: 3, DupFrames: 1, OutTime: "00:00:04.000000", Speed: "1.01x"}
+	if got.State != StateRunning {
+		t.Errorf("State = %s, want %s", got.State, StateRunning)
+	}
+	if got.Progress != want {
+		t.Errorf("Progress = %+v, want %+v", got.Progress, want)
+	}
+}
+
+func TestReadProgressOutput(t *testing.T) {
+	s := NewSupervisor("test-read-output", nil)
+	var lines []string
+	s.OnOutput = func(line string) { lines = append(lines, line) }
+
+	s.readProgress(strings.NewReader("frame=1\nprogress=continue\nrecording_20240101120000.mp4\n\nframe=2\n"))
+
+	if len(lines) != 1 || lines[0] != "recording_20240101120000.mp4" {
+		t.Errorf("OnOutput lines = %q", lines)
+	}
+}
+
+func TestSupervisorProgress(t *testing.T) {
+	s := fakeSupervisor(t, "test-progress")
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, 5*time.Second, "收到进度", func() bool {
+		return s.Status().Progress.Frame >= 30
+	})
+	status := s.Status()
+	if status.State != StateRunning {
+		t.Errorf("State = %s, want %s", status.State, StateRunning)
+	}
+	if status.PID == 0 {
+		t.Error("PID = 0")
+	}
+	if status.Progress.Bitrate != 1987.5 || status.Progress.Speed != "1.00x" {
+		t.Errorf("Progress = %+v", status.Progress)
+	}
+}
+
+func TestSupervisorRestart(t *testing.T) {
+	t.Setenv("FAKE_FFMPEG_FAIL_AFTER", "1")
+	s := fakeSupervisor(t, "test-restart")
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 第一次重启间隔为 RestartMinDelay
+	waitFor(t, RestartMinDelay+5*time.Second, "异常退出后重启", func() bool {
+		return s.Status().Restarts >= 1
+	})
+	status := s.Status()
+	if !strings.Contains(status.LastError, "Connection refused") {
+		t.Errorf("LastError = %q, want stderr 最后一行", status.LastError)
+	}
+	// 重启后的进程再次失败，说明确实重新启动了；第二次间隔按退避增大
+	waitFor(t, 2*RestartMinDelay*2+5*time.Second, "重启后再次运行", func() bool {
+		return s.Status().Restarts >= 2
+	})
+	if state := s.Status().State; state != StateRestarting && state != StateStarting && state != StateRunning {
+		t.Errorf("State = %s, 未设置最大重启次数时应持续重启", state)
+	}
+}
+
+func TestSupervisorMaxRestarts(t *testing.T) {
+	t.Setenv("FAKE_FFMPEG_FAIL_AFTER", "1")
+	s := fakeSupervisor(t, "test-max-restarts")
+	s.MaxRestarts = 1
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-s.Done():
+	case <-time.After(RestartMinDelay*2 + 10*time.Second):
+		t.Fatal("超过最大重启次数后托管未结束")
+	}
+	status := s.Status()
+	if status.State != StateFailed {
+		t.Errorf("State = %s, want %s", status.State, StateFailed)
+	}
+	if status.Restarts != 2 {
+		t.Errorf("Restarts = %d, want 2", status.Restarts)
+	}
+	for _, st := range Statuses() {
+		if st.Name == s.Name {
+			t.Error("失败的进程仍在运行列表中")
+		}
+	}
+}
+
+func TestSupervisorStop(t *testing.T) {
+	s := fakeSupervisor(t, "test-stop")
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start(); err != ErrSupervisorRunning {
+		t.Errorf("重复 Start 返回 %v, want %v", err, ErrSupervisorRunning)
+	}
+	waitFor(t, 5*time.Second, "进程运行", func() bool {
+		return s.Status().State == StateRunning
+	})
+
+	// 模拟脚本收到 q 后正常退出，不应等到 StopTimeout 强制结束
+	started := time.Now()
+	s.Stop()
+	if elapsed := time.Since(started); elapsed >= StopTimeout {
+		t.Errorf("Stop 耗时 %v，未通过 q 正常退出", elapsed)
+	}
+	status := s.Status()
+	if status.State != StateStopped || status.Restarts != 0 || status.PID != 0 {
+		t.Errorf("Status = %+v, want stopped without restarts", status)
+	}
+}
@@ -1,3 +1,5 @@
+//go:build windows
+
 package foreground
 
 import (
@@ -27,15 +29,6 @@ const (
 	PROCESS_QUERY_LIMITED_INFORMATION = 0x1000
 )
 
-// WindowInfo 窗口信息结构体
-type WindowInfo struct {
-	Handle      syscall.Handle // 窗口句柄
-	Title       string         // 窗口标题
-	ProcessID   uint32         // 进程ID
-	ProcessName string         // 进程名称
-	ProcessPath string         // 进程完整路径
-}
-
 // GetForegroundWindow 获取当前焦点窗口句柄
 func GetForegroundWindow() syscall.Handle {
 	ret, _, _ := procGetForeground.Call()
@@ -194,7 +187,7 @@ func GetWindowInfo() *WindowInfo {
 	pid := GetProcessID(hwnd)
 	if pid == 0 {
 		return &WindowInfo{
-			Handle: hwnd,
+			Handle: uintptr(hwnd),
 			Title:  title,
 		}
 	}
@@ -211,7 +204,7 @@ func GetWindowInfo() *WindowInfo {
 	}
 
 	return &WindowInfo{
-		Handle:      hwnd,
+		Handle:      uintptr(hwnd),
 		Title:       title,
 		ProcessID:   pid,
 		ProcessName: processName,
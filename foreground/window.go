@@ -0,0 +1,10 @@
+package foreground
+
+// WindowInfo 窗口信息结构体
+type WindowInfo struct {
+	Handle      uintptr // 窗口句柄，Linux 下为X窗口ID
+	Title       string  // 窗口标题
+	ProcessID   uint32  // 进程ID
+	ProcessName string  // 进程名称
+	ProcessPath string  // 进程完整路径
+}
@@ -0,0 +1,170 @@
+//go:build linux
+
+package foreground
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// Window X11 顶层窗口信息
+type Window struct {
+	ID          uint32
+	Title       string
+	ProcessID   uint32
+	ProcessName string
+	Bounds      image.Rectangle // 窗口在根窗口(虚拟桌面)中的位置和大小
+}
+
+// ErrNoWindowManager 窗口管理器不支持 EWMH，无法枚举客户端窗口
+var ErrNoWindowManager = errors.New("窗口管理器未提供 _NET_CLIENT_LIST")
+
+// ListWindows 通过 EWMH 的 _NET_CLIENT_LIST 枚举当前可见的顶层窗口
+func ListWindows() ([]Window, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, fmt.Errorf("连接X服务器失败: %w", err)
+	}
+	defer conn.Close()
+
+	root := xproto.Setup(conn).DefaultScreen(conn).Root
+	clientList, err := getProperty(conn, root, "_NET_CLIENT_LIST")
+	if err != nil {
+		return nil, err
+	}
+	if clientList == nil || clientList.Format != 32 {
+		return nil, ErrNoWindowManager
+	}
+
+	var windows []Window
+	for i := 0; i+4 <= len(clientList.Value); i += 4 {
+		id := xproto.Window(xgb.Get32(clientList.Value[i:]))
+		window, ok := describeWindow(conn, root, id)
+		if ok {
+			windows = append(windows, window)
+		}
+	}
+	return windows, nil
+}
+
+// describeWindow 读取窗口的位置、标题和所属进程，未映射(最小化或隐藏)的窗口返回 false
+func describeWindow(conn *xgb.Conn, root, id xproto.Window) (Window, bool) {
+	attrs, err := xproto.GetWindowAttributes(conn, id).Reply()
+	if err != nil || attrs.MapState != xproto.MapStateViewable {
+		return Window{}, false
+	}
+	geometry, err := xproto.GetGeometry(conn, xproto.Drawable(id)).Reply()
+	if err != nil {
+		return Window{}, false
+	}
+	origin, err := xproto.TranslateCoordinates(conn, id, root, 0, 0).Reply()
+	if err != nil {
+		return Window{}, false
+	}
+
+	window := Window{
+		ID: uint32(id),
+		Bounds: image.Rect(int(origin.DstX), int(origin.DstY),
+			int(origin.DstX)+int(geometry.Width), int(origin.DstY)+int(geometry.Height)),
+	}
+	if window.ProcessID = windowPID(conn, id); window.ProcessID != 0 {
+		window.ProcessName = processName(window.ProcessID)
+	}
+	window.Title = windowTitle(conn, id)
+	return window, true
+}
+
+var (
+	activeMu   sync.Mutex
+	activeConn *xgb.Conn // 轮询焦点窗口时复用的X连接
+)
+
+// GetWindowInfo 通过 EWMH 的 _NET_ACTIVE_WINDOW 获取当前焦点窗口，连接X服务器失败或窗口管理器不支持时返回 nil
+func GetWindowInfo() *WindowInfo {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+
+	if activeConn == nil {
+		conn, err := xgb.NewConn()
+		if err != nil {
+			return nil
+		}
+		activeConn = conn
+	}
+
+	root := xproto.Setup(activeConn).DefaultScreen(activeConn).Root
+	reply, err := getProperty(activeConn, root, "_NET_ACTIVE_WINDOW")
+	if err != nil {
+		// X服务器重启等情况下连接失效，下次重新连接
+		activeConn.Close()
+		activeConn = nil
+		return nil
+	}
+	if reply == nil || reply.Format != 32 || len(reply.Value) < 4 {
+		return nil
+	}
+
+	id := xproto.Window(xgb.Get32(reply.Value))
+	info := &WindowInfo{Handle: uintptr(id)}
+	if id == xproto.WindowNone {
+		return info
+	}
+	info.Title = windowTitle(activeConn, id)
+	info.ProcessID = windowPID(activeConn, id)
+	if info.ProcessID != 0 {
+		info.ProcessPath, _ = os.Readlink(fmt.Sprintf("/proc/%d/exe", info.ProcessID))
+		info.ProcessName = processName(info.ProcessID)
+	}
+	return info
+}
+
+// windowPID 读取窗口的 _NET_WM_PID，未设置时返回0
+func windowPID(conn *xgb.Conn, id xproto.Window) uint32 {
+	if reply, _ := getProperty(conn, id, "_NET_WM_PID"); reply != nil && len(reply.Value) >= 4 {
+		return xgb.Get32(reply.Value)
+	}
+	return 0
+}
+
+// windowTitle 优先读取 UTF-8 的 _NET_WM_NAME
+func windowTitle(conn *xgb.Conn, id xproto.Window) string {
+	if reply, _ := getProperty(conn, id, "_NET_WM_NAME"); reply != nil && len(reply.Value) > 0 {
+		return string(reply.Value)
+	}
+	if reply, _ := getProperty(conn, id, "WM_NAME"); reply != nil {
+		return string(reply.Value)
+	}
+	return ""
+}
+
+// getProperty 读取窗口属性，属性名不存在时返回 nil
+func getProperty(conn *xgb.Conn, window xproto.Window, name string) (*xproto.GetPropertyReply, error) {
+	atom, err := xproto.InternAtom(conn, true, uint16(len(name)), name).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("查询属性 %s 失败: %w", name, err)
+	}
+	if atom.Atom == xproto.AtomNone {
+		return nil, nil
+	}
+	return xproto.GetProperty(conn, false, window, atom.Atom, xproto.GetPropertyTypeAny, 0, 1<<16).Reply()
+}
+
+// processName 从 /proc 读取进程可执行文件名，无权限读取时使用可能被截断的 comm
+func processName(pid uint32) string {
+	if exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid)); err == nil {
+		return filepath.Base(exe)
+	}
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !windows
+
+package foreground
+
+// GetWindowInfo 当前平台暂不支持获取焦点窗口
+func GetWindowInfo() *WindowInfo {
+	return nil
+}
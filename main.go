@@ -4,9 +4,24 @@ import (
 	"bytes"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
+	"monitor-desktop-client/audio"
+	"monitor-desktop-client/camera"
 	"monitor-desktop-client/compose"
+	"monitor-desktop-client/ffmpeg"
+	"monitor-desktop-client/netcap"
+	"monitor-desktop-client/remote"
+	"monitor-desktop-client/screencap"
+	wsc "monitor-desktop-client/transmission"
 	"monitor-desktop-client/utils"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/energye/energy/v2/cef"
@@ -30,20 +45,61 @@ type Config struct {
 	AccountID  int    // 考生账号ID
 	ExamID     int    // 考试ID
 	Token      string // 认证令牌
-	WSEndpoint string // WebSocket端点
+	WSEndpoint string // WebSocket端点，考试未指定时由服务器URL推导
+
+	AllowedDomains   []string // 考试允许访问的域名
+	ForbiddenDomains []string // 考试禁止访问的域名
+
+	UploadRateThreshold int // 持续上行速率告警阈值(KB/s)，0表示使用默认值
+
+	ScreenshotEncoding screencap.EncoderConfig // 截图编码参数
+	ScreenCaptureMode  screencap.CaptureMode   // 多显示器拼接为一张图或每个显示器单独截图
+	ScreenshotKey      string                  // 截图签名密钥，为空时使用登录令牌
+	Redaction          screencap.Redaction     // 截图隐私遮盖区域和进程
+
+	RecordingEnabled bool                  // 是否本地分段录屏
+	Recording        ffmpeg.RecorderConfig // 录屏参数
+	StreamProfile    string                // 实时推流编码预设: low-bandwidth / standard / evidence-quality
+
+	CameraEnabled  bool          // 是否拍摄身份照片并定时抓拍
+	CameraInterval time.Duration // 定时抓拍间隔
+
+	MicrophoneEnabled bool            // 是否监测麦克风音量以发现说话
+	MicrophoneVAD     audio.VADConfig // 说话检测参数
+	AudioClipEnabled  bool            // 是否保留说话事件前后的短音频片段
+
+	USBStorageBlock devices.EnforceMode // 考试期间USB存储设备管控方式，为空时只检测
+
+	RemoteAccessDetection bool          // 是否检测远程控制工具和远程会话
+	RemoteAccessInterval  time.Duration // 远程访问检测间隔
+
+	CaptureBackend string // 抓包后端: pcap / afpacket，为空时使用平台默认
 }
 
 // 全局配置
 var appConfig = &Config{
 	//ServerURL:  "http://localhost:8777", // 默认服务器地址
 	//WSEndpoint: "ws://localhost:8777/ws/monitor",
-	ServerURL:  "https://monitor.ivresse.top/api", // 默认服务器地址
-	WSEndpoint: "ws://localhost:8777/ws/monitor",
+	ServerURL:          "https://monitor.ivresse.top/api", // 默认服务器地址
+	CaptureBackend:     os.Getenv("MONITOR_CAPTURE_BACKEND"),
+	ScreenshotEncoding: screencap.DefaultEncoderConfig,
 }
 
 // 全局数据收集器
 var monitorCollector *utils.MonitorDataCollector
 
+// 本地分段录屏
+var screenRecorder *ffmpeg.Recorder
+
+// 摄像头抓拍
+var cameraMonitor *camera.Monitor
+
+// 麦克风说话检测
+var microphoneMonitor *audio.Monitor
+
+// 远程控制和屏幕共享检测
+var remoteMonitor *remote.Monitor
+
 func main() {
 	// 全局初始化
 	cef.GlobalInit(nil, resources)
@@ -76,6 +132,11 @@ func setupAppConfig() {
 
 	// 禁用开发者工具
 	//cef.BrowserWindow.Config.ChromiumConfig().SetEnableDevTools(false)
+
+	// 选择抓包后端
+	if err := netcap.SetBackend(appConfig.CaptureBackend); err != nil {
+		fmt.Printf("设置抓包后端失败: %v，使用默认后端 %s\n", err, netcap.Backend())
+	}
 }
 
 // 设置浏览器事件处理
@@ -120,32 +181,180 @@ func registerIPCEvents() {
 
 	// 注册设备信息获取事件
 	registerDeviceInfoEvent()
+
+	// 注册 ffmpeg 状态查询事件
+	registerFfmpegStatusEvent()
 }
 
 // 初始化数据上报回调函数
 func initReportCallbacks() {
 	// 初始化全局回调函数，将网站访问和截图上报连接到数据收集器
 	compose.SetReportCallbacks(reportNetworkInfo, reportScreenCap)
+	compose.SetBehaviorCallback(reportBehavior)
+	compose.SetTrafficCallback(reportTraffic)
+	ffmpeg.SetStatusCallback(reportFfmpegStatus)
 }
 
 // 网站访问上报回调
-func reportNetworkInfo(domain string) {
+func reportNetworkInfo(event netcap.SniEvent) {
 	if monitorCollector != nil && monitorCollector.IsRunning {
-		fmt.Printf("检测到网站访问: %s，准备上报\n", domain)
-		monitorCollector.ReportWebsiteVisit(domain, domain)
+		fmt.Printf("检测到网站访问: %s (进程: %s[%d])，准备上报\n", event.Domain, event.ProcessName, event.ProcessID)
+		monitorCollector.ReportWebsiteVisitDetail(utils.WebsiteVisit{
+			URL:         event.Domain,
+			ProcessID:   event.ProcessID,
+			ProcessName: event.ProcessName,
+			Category:    string(event.Category),
+		})
 
 		// 通知前端显示
-		ipc.Emit("websiteVisit", domain)
+		ipc.Emit("websiteVisit", event.Domain, event.ProcessName, string(event.Category))
 	} else {
-		fmt.Printf("收到网站访问: %s，但监控收集器未运行\n", domain)
+		fmt.Printf("收到网站访问: %s，但监控收集器未运行\n", event.Domain)
 	}
 }
 
 // 截图上报回调
-func reportScreenCap(buffer *bytes.Buffer) {
+func reportScreenCap(buffer *bytes.Buffer, meta utils.ScreenshotMeta) {
 	if monitorCollector != nil && monitorCollector.IsRunning {
 		fmt.Println("上报屏幕截图")
-		monitorCollector.UploadScreenshotData(buffer)
+		monitorCollector.UploadScreenshotData(buffer, meta)
+	}
+}
+
+// 录屏分段上报回调
+func reportRecordingSegment(path string) {
+	if monitorCollector != nil && monitorCollector.IsRunning {
+		monitorCollector.UploadRecordingSegment(path)
+	}
+}
+
+// 摄像头照片上报回调
+func reportCameraPhoto(kind string, data []byte) {
+	if monitorCollector != nil && monitorCollector.IsRunning {
+		monitorCollector.UploadCameraPhoto(data, kind)
+	}
+}
+
+// 摄像头状态变化回调，缺失或断开按高风险行为上报
+func reportCameraStatus(available bool, err error) {
+	if available {
+		reportBehavior(utils.BehaviorCameraRestored, "摄像头已恢复", utils.LevelInfo)
+		return
+	}
+	content := "摄像头不可用"
+	if err != nil {
+		content = fmt.Sprintf("摄像头不可用: %v", err)
+	}
+	reportBehavior(utils.BehaviorCameraLost, content, utils.LevelHigh)
+}
+
+// USB存储管控审计回调，每次管控操作都作为行为事件上报
+func reportUSBEnforcement(action devices.EnforceAction) {
+	content := fmt.Sprintf("USB存储管控 %s: %s %s", action.Action, action.Device.DeviceName, action.Target)
+	level := utils.LevelWarning
+	if action.Err != nil {
+		content += fmt.Sprintf(" 失败: %v", action.Err)
+		level = utils.LevelHigh
+	}
+	reportBehavior(utils.BehaviorUSBStorageBlocked, content, level)
+}
+
+// 启动时恢复的上次管控操作，此时尚未登录，登录后再上报
+var (
+	pendingUSBRestoreMu sync.Mutex
+	pendingUSBRestores  []devices.EnforceAction
+)
+
+func queueUSBRestore(action devices.EnforceAction) {
+	pendingUSBRestoreMu.Lock()
+	defer pendingUSBRestoreMu.Unlock()
+	pendingUSBRestores = append(pendingUSBRestores, action)
+}
+
+// reportPendingUSBRestores 登录后上报启动时恢复的管控操作
+func reportPendingUSBRestores() {
+	pendingUSBRestoreMu.Lock()
+	actions := pendingUSBRestores
+	pendingUSBRestores = nil
+	pendingUSBRestoreMu.Unlock()
+
+	for _, action := range actions {
+		reportUSBEnforcement(action)
+	}
+}
+
+// 持续说话回调，按警告级别上报，开启片段保留时附带音频片段地址
+func reportSpeech(event audio.SpeechEvent) {
+	utils.Go(func() {
+		if monitorCollector == nil || !monitorCollector.IsRunning {
+			return
+		}
+		content := fmt.Sprintf("检测到持续说话: 开始于 %s，峰值音量 %.1f dBFS",
+			event.Start.Format("15:04:05"), event.PeakDBFS)
+		if event.Clip != nil {
+			if url := monitorCollector.UploadAudioClip(event.Clip, event.Start); url != "" {
+				content += "，音频片段: " + url
+			}
+		}
+		reportBehavior(utils.BehaviorSpeechDetected, content, utils.LevelWarning)
+	})
+}
+
+// 麦克风音量统计回调
+func reportAudioLevel(summary audio.Summary) {
+	if monitorCollector != nil && monitorCollector.IsRunning {
+		utils.Go(func() {
+			monitorCollector.ReportAudioLevel(summary.Start, summary.End, summary.AvgDBFS, summary.PeakDBFS, summary.SpeechRatio)
+		})
+	}
+}
+
+// 麦克风状态变化回调，缺失或断开按高风险行为上报
+func reportMicrophoneStatus(available bool, err error) {
+	if available {
+		reportBehavior(utils.BehaviorMicrophoneRestored, "麦克风已恢复", utils.LevelInfo)
+		return
+	}
+	content := "麦克风不可用"
+	if err != nil {
+		content = fmt.Sprintf("麦克风不可用: %v", err)
+	}
+	reportBehavior(utils.BehaviorMicrophoneLost, content, utils.LevelHigh)
+}
+
+// 远程访问检测结果变化回调，正在被远程访问按高风险上报，仅发现工具运行按警告上报
+func reportRemoteAccess(report, previous remote.Report) {
+	switch {
+	case report.Active:
+		reportBehavior(utils.BehaviorRemoteSession, "正在被远程访问: "+report.Summary(), utils.LevelHigh)
+	case report.Detected():
+		reportBehavior(utils.BehaviorRemoteToolRunning, "发现远程控制工具: "+report.Summary(), utils.LevelWarning)
+	case previous.Detected():
+		reportBehavior(utils.BehaviorRemoteSessionEnded, "远程控制工具或远程会话已消失", utils.LevelInfo)
+	}
+}
+
+// ffmpeg 进程状态回调，通知前端并报告给服务器
+func reportFfmpegStatus(status ffmpeg.Status) {
+	if data, err := json.Marshal(status); err == nil {
+		ipc.Emit("ffmpegStatus", string(data))
+	}
+	if err := wsc.ReportFfmpegStatus(status); err != nil {
+		fmt.Println("发送ffmpeg状态失败:", err)
+	}
+}
+
+// 行为事件上报回调
+func reportBehavior(eventType int, content string, level string) {
+	if monitorCollector != nil && monitorCollector.IsRunning {
+		monitorCollector.ReportBehavior(eventType, content, level)
+	}
+}
+
+// 流量汇总上报回调
+func reportTraffic(interfaces []netcap.InterfaceTraffic, hosts []netcap.HostTraffic) {
+	if monitorCollector != nil && monitorCollector.IsRunning {
+		monitorCollector.ReportTrafficSummary(interfaces, hosts)
 	}
 }
 
@@ -161,14 +370,38 @@ func registerLoadEvent() {
 
 // 注册登录事件处理
 func registerLoginEvent() {
-	ipc.On("login", func(username, password string) {
+	// login 登录流程，confirm 不为空时为考生确认更换设备后的重新登录
+	// 重新登录使用首次登录下发的会话令牌和确认令牌，不再保留和发送密码
+	login := func(username, password string, confirm *deviceConfirm) {
 		fmt.Println("用户尝试登录:", username)
 
+		// 采集设备指纹，用于服务器校验考生是否在绑定的设备上登录
+		var (
+			deviceInfo  *devices.DeviceInfo
+			fingerprint devices.Fingerprint
+		)
+		if confirm != nil {
+			deviceInfo = confirm.device
+			fingerprint = confirm.fingerprint
+		} else if info, err := devices.GetDeviceInfo(); err != nil {
+			fmt.Println("获取设备指纹失败:", err)
+		} else {
+			deviceInfo = info
+			fingerprint = info.Fingerprint
+		}
+
 		// 构建登录请求
 		loginURL := appConfig.ServerURL + "/examinee/account/login"
-		loginData := map[string]string{
-			"account":  username,
-			"password": password,
+		loginData := map[string]interface{}{
+			"account":           username,
+			"deviceFingerprint": fingerprint,
+		}
+		headers := map[string]string{"Content-Type": "application/json"}
+		if confirm != nil {
+			loginData["deviceConfirmToken"] = confirm.token
+			headers["Authorization"] = "Bearer " + confirm.sessionToken
+		} else {
+			loginData["password"] = password
 		}
 
 		jsonData, err := json.Marshal(loginData)
@@ -179,7 +412,7 @@ func registerLoginEvent() {
 		}
 
 		// 发送登录请求
-		resp, err := utils.HttpPost(loginURL, jsonData)
+		resp, err := utils.HttpPostWithHeaders(loginURL, jsonData, headers)
 		if err != nil {
 			fmt.Println("登录请求失败:", err)
 			ipc.Emit("loginResult", false, nil, "连接服务器失败: "+err.Error())
@@ -194,6 +427,10 @@ func registerLoginEvent() {
 				Token     string `json:"token"`
 				AccountId int    `json:"accountId"`
 				ExamId    int    `json:"examId"`
+
+				DeviceStatus       string `json:"deviceStatus"`       // bound / new / confirm_required / mismatch
+				DeviceConfirmToken string `json:"deviceConfirmToken"` // 需要确认设备时下发
+				FingerprintSalt    string `json:"fingerprintSalt"`    // 设备指纹盐值
 			} `json:"data"`
 		}
 
@@ -211,11 +448,35 @@ func registerLoginEvent() {
 			return
 		}
 
+		// 当前设备不是账号绑定的设备，需要考生确认后携带令牌重新登录
+		if loginResp.Data.DeviceStatus == deviceStatusConfirmRequired {
+			fmt.Println("需要确认登录设备:", loginResp.Msg)
+			setPendingDeviceConfirm(&deviceConfirm{
+				username:     username,
+				sessionToken: loginResp.Data.Token,
+				token:        loginResp.Data.DeviceConfirmToken,
+				device:       deviceInfo,
+				fingerprint:  fingerprint,
+			})
+			ipc.Emit("deviceConfirmRequired", loginResp.Msg)
+			return
+		}
+
 		// 保存登录信息
 		appConfig.Token = loginResp.Data.Token
 		appConfig.AccountID = loginResp.Data.AccountId
 		appConfig.ExamID = loginResp.Data.ExamId
 
+		// 服务器下发新的指纹盐值时保存供之后的登录使用，本地绑定比对改用新盐值重新计算的指纹
+		if salt := loginResp.Data.FingerprintSalt; salt != "" && salt != devices.FingerprintSalt() {
+			if err := devices.SetFingerprintSalt(salt); err != nil {
+				fmt.Println("保存设备指纹盐值失败:", err)
+			}
+			if deviceInfo != nil {
+				fingerprint = deviceInfo.FingerprintWithSalt(salt)
+			}
+		}
+
 		// 获取考生和考试信息
 		examInfo, err := getExamineeInfo()
 		if err != nil {
@@ -231,20 +492,131 @@ func registerLoginEvent() {
 			appConfig.AccountID,
 			appConfig.ExamID,
 		)
+		monitorCollector.ScreenshotTrigger = compose.TriggerScreenshot
+		monitorCollector.SigningKey = []byte(appConfig.Token)
+		if appConfig.ScreenshotKey != "" {
+			monitorCollector.SigningKey = []byte(appConfig.ScreenshotKey)
+		}
+		compose.SetWatermarkIdentity(appConfig.ExamID, appConfig.AccountID)
+		screencap.SetRedaction(appConfig.Redaction)
 		monitorCollector.Start()
+		checkDeviceBinding(username, fingerprint, loginResp.Data.DeviceStatus, confirm != nil)
+		compose.SetScreenshotEncoding(appConfig.ScreenshotEncoding)
+		compose.SetScreenCaptureMode(appConfig.ScreenCaptureMode)
+		compose.SetUploadStatsProvider(monitorCollector.UploadStats)
 
 		// 启动网络监控
+		compose.SetDomainPolicy(appConfig.AllowedDomains, appConfig.ForbiddenDomains)
+		compose.SetTrafficPolicy(compose.TrafficPolicy{
+			UploadRateThreshold: float64(appConfig.UploadRateThreshold) * 1024,
+		})
 		go compose.WatchNetworkInfo()
 
 		// 启动窗口前台监控
 		go compose.MonitorForegroundWindow()
 
+		// 连接监控WebSocket，接收服务器命令(如实时推流)
+		wsc.SetStreamProfile(appConfig.StreamProfile)
+		wsc.StartWebSocketMonitor(appConfig.WSEndpoint, strconv.Itoa(appConfig.AccountID))
+
+		// 拍摄身份照片并开始定时抓拍
+		if appConfig.CameraEnabled {
+			startCameraMonitor()
+		}
+
+		// 开启USB存储设备管控，未登出再次登录时先恢复上一次考试管控的设备
+		if previous := usbEnforcer.Swap(nil); previous != nil {
+			previous.Restore()
+		}
+		if appConfig.USBStorageBlock != devices.EnforceOff {
+			enforcer := devices.NewEnforcer(appConfig.USBStorageBlock, reportUSBEnforcement)
+			enforcer.Activate()
+			usbEnforcer.Store(enforcer)
+		}
+		reportPendingUSBRestores()
+
+		// 监测麦克风音量，发现持续说话
+		if appConfig.MicrophoneEnabled {
+			startMicrophoneMonitor()
+		}
+
+		// 检测远程控制工具和远程会话
+		if appConfig.RemoteAccessDetection {
+			remoteMonitor = remote.NewMonitor(appConfig.RemoteAccessInterval, reportRemoteAccess)
+			remoteMonitor.Start()
+		}
+
+		// 启动本地分段录屏
+		if appConfig.RecordingEnabled {
+			screenRecorder = ffmpeg.NewRecorder(appConfig.Recording, reportRecordingSegment)
+			if err := screenRecorder.Start(); err != nil {
+				fmt.Println("启动录屏失败:", err)
+			}
+		}
+
 		// 发送登录成功事件
 		ipc.Emit("loginResult", true, examInfo, "")
+	}
+
+	ipc.On("login", func(username, password string) {
+		takePendingDeviceConfirm()
+		login(username, password, nil)
+	})
+
+	// 考生确认在当前设备登录
+	ipc.On("confirmDevice", func() {
+		confirm := takePendingDeviceConfirm()
+		if confirm == nil {
+			ipc.Emit("loginResult", false, nil, "没有待确认的登录设备")
+			return
+		}
+		login(confirm.username, "", confirm)
+	})
+
+	ipc.On("cancelDeviceConfirm", func() {
+		takePendingDeviceConfirm()
+		ipc.Emit("loginResult", false, nil, "已取消设备确认")
 	})
 
 	ipc.On("logout", func() {
 		fmt.Println("用户登出")
+		// 停止网络监控
+		compose.StopWatchNetworkInfo()
+
+		// 停止摄像头抓拍
+		if cameraMonitor != nil {
+			cameraMonitor.Stop()
+			cameraMonitor = nil
+		}
+
+		// 恢复被管控的USB存储设备
+		if enforcer := usbEnforcer.Swap(nil); enforcer != nil {
+			enforcer.Restore()
+		}
+
+		// 停止麦克风监测
+		if microphoneMonitor != nil {
+			microphoneMonitor.Stop()
+			microphoneMonitor = nil
+		}
+
+		// 停止远程访问检测
+		if remoteMonitor != nil {
+			remoteMonitor.Stop()
+			remoteMonitor = nil
+		}
+
+		// 停止录屏，等待最后一个分段写完
+		if screenRecorder != nil {
+			screenRecorder.Stop()
+			screenRecorder = nil
+		}
+
+		// 停止推流等 ffmpeg 进程并断开监控WebSocket
+		wsc.StopAllStreams()
+		ffmpeg.StopAll()
+		wsc.DisconnectWebsocket()
+
 		// 停止监控数据收集
 		if monitorCollector != nil {
 			monitorCollector.Stop()
@@ -255,9 +627,144 @@ func registerLoginEvent() {
 		appConfig.Token = ""
 		appConfig.AccountID = 0
 		appConfig.ExamID = 0
+		appConfig.AllowedDomains = nil
+		appConfig.ForbiddenDomains = nil
+		appConfig.ScreenshotKey = ""
+		appConfig.Redaction = screencap.Redaction{}
+		screencap.SetRedaction(appConfig.Redaction)
+	})
+}
+
+// 服务器返回的设备绑定状态
+const (
+	deviceStatusConfirmRequired = "confirm_required" // 需要考生确认更换设备
+	deviceStatusMismatch        = "mismatch"         // 服务器判定设备不一致但允许登录
+)
+
+// deviceConfirmTimeout 等待考生确认设备的时间，超时后需要重新输入密码登录
+const deviceConfirmTimeout = 5 * time.Minute
+
+// deviceConfirm 等待考生确认设备的登录请求，只保存首次登录下发的令牌，不保存密码
+type deviceConfirm struct {
+	username     string
+	sessionToken string
+	token        string
+	device       *devices.DeviceInfo
+	fingerprint  devices.Fingerprint
+	timer        *time.Timer
+}
+
+var (
+	pendingConfirmMu     sync.Mutex
+	pendingDeviceConfirm *deviceConfirm
+)
+
+// setPendingDeviceConfirm 保存待确认的登录，超时后自动清除并通知前端
+func setPendingDeviceConfirm(confirm *deviceConfirm) {
+	pendingConfirmMu.Lock()
+	defer pendingConfirmMu.Unlock()
+	if pendingDeviceConfirm != nil {
+		pendingDeviceConfirm.timer.Stop()
+	}
+	pendingDeviceConfirm = confirm
+	confirm.timer = time.AfterFunc(deviceConfirmTimeout, func() {
+		pendingConfirmMu.Lock()
+		expired := pendingDeviceConfirm == confirm
+		if expired {
+			pendingDeviceConfirm = nil
+		}
+		pendingConfirmMu.Unlock()
+		if expired {
+			ipc.Emit("loginResult", false, nil, "设备确认已超时，请重新登录")
+		}
 	})
 }
 
+// takePendingDeviceConfirm 取出并清除待确认的登录，没有时返回 nil
+func takePendingDeviceConfirm() *deviceConfirm {
+	pendingConfirmMu.Lock()
+	defer pendingConfirmMu.Unlock()
+	confirm := pendingDeviceConfirm
+	pendingDeviceConfirm = nil
+	if confirm != nil {
+		confirm.timer.Stop()
+	}
+	return confirm
+}
+
+// checkDeviceBinding 与本地缓存的设备绑定比对，不一致时按高风险行为上报
+// 设备不一致且未经确认时保留原绑定，避免考生换机后绑定被覆盖
+func checkDeviceBinding(account string, fingerprint devices.Fingerprint, serverStatus string, confirmed bool) {
+	if fingerprint.ID == "" {
+		return
+	}
+
+	if serverStatus == deviceStatusMismatch {
+		reportBehavior(utils.BehaviorDeviceMismatch, "服务器判定登录设备与绑定设备不一致", utils.LevelHigh)
+		return
+	}
+
+	binding, err := devices.LoadBinding(account)
+	if err != nil {
+		fmt.Println("读取本地设备绑定失败:", err)
+	}
+	if binding != nil && !confirmed {
+		match := devices.VerifyBinding(binding, fingerprint)
+		if !match.Stable {
+			content := fmt.Sprintf("登录设备与本地绑定设备不一致: 相似度 %.0f%%，变化部分 %v，绑定于 %s",
+				match.Score*100, match.Changed, binding.BoundAt.Format("2006-01-02 15:04:05"))
+			reportBehavior(utils.BehaviorDeviceMismatch, content, utils.LevelHigh)
+			return
+		}
+	}
+
+	// 绑定跟随少量硬件变化更新，下次比对以本次指纹为准，绑定时间保持首次绑定的时间
+	boundAt := time.Now()
+	if binding != nil && !confirmed {
+		boundAt = binding.BoundAt
+	}
+	err = devices.SaveBinding(devices.Binding{Account: account, Fingerprint: fingerprint, BoundAt: boundAt})
+	if err != nil {
+		fmt.Println("保存本地设备绑定失败:", err)
+	}
+}
+
+// startCameraMonitor 打开摄像头开始抓拍，没有摄像头时也启动监控以上报摄像头缺失，平台不支持摄像头采集时不启动
+// 登录时没有摄像头或摄像头失效后，每次抓拍前都会重新打开
+func startCameraMonitor() {
+	if _, err := camera.Devices(); errors.Is(err, camera.ErrUnsupported) {
+		fmt.Println("跳过摄像头抓拍:", err)
+		return
+	}
+	cameraMonitor = camera.NewMonitor(nil, appConfig.CameraInterval)
+	cameraMonitor.Open = openCamera
+	cameraMonitor.OnPhoto = reportCameraPhoto
+	cameraMonitor.OnStatus = reportCameraStatus
+	cameraMonitor.Start()
+}
+
+// openCamera 打开第一个可用的摄像头
+func openCamera() (camera.Source, error) {
+	s, err := camera.Open()
+	if err != nil {
+		fmt.Println("打开摄像头失败:", err)
+		return nil, err
+	}
+	fmt.Println("使用摄像头:", s.Device.Name)
+	return s, nil
+}
+
+// startMicrophoneMonitor 通过系统 ffmpeg 读取默认输入设备监测说话
+func startMicrophoneMonitor() {
+	microphoneMonitor = audio.NewMonitor(&audio.FFmpegSource{})
+	microphoneMonitor.VAD = appConfig.MicrophoneVAD
+	microphoneMonitor.ClipEnabled = appConfig.AudioClipEnabled
+	microphoneMonitor.OnSpeech = reportSpeech
+	microphoneMonitor.OnSummary = reportAudioLevel
+	microphoneMonitor.OnStatus = reportMicrophoneStatus
+	microphoneMonitor.Start()
+}
+
 // 获取考生信息和考试信息
 func getExamineeInfo() (map[string]interface{}, error) {
 	// 构建请求
@@ -291,6 +798,47 @@ func getExamineeInfo() (map[string]interface{}, error) {
 				Status        int    `json:"status"`
 				ServerTime    string `json:"serverTime"`
 				RemainingTime int64  `json:"remainingTime"`
+
+				AllowedDomains      []string `json:"allowedDomains"`
+				ForbiddenDomains    []string `json:"forbiddenDomains"`
+				UploadRateThreshold int      `json:"uploadRateThreshold"` // KB/s
+
+				ScreenshotFormat    string `json:"screenshotFormat"` // jpeg / png，webp 需注册编码器
+				ScreenshotQuality   int    `json:"screenshotQuality"`
+				ScreenshotMaxWidth  int    `json:"screenshotMaxWidth"`
+				ScreenshotMaxHeight int    `json:"screenshotMaxHeight"`
+				ScreenshotGrayscale bool   `json:"screenshotGrayscale"`
+				ScreenshotKey       string `json:"screenshotKey"`
+				ScreenshotMode      string `json:"screenshotMode"` // composite / per-display
+
+				RedactRegions []struct {
+					X      int `json:"x"`
+					Y      int `json:"y"`
+					Width  int `json:"width"`
+					Height int `json:"height"`
+				} `json:"redactRegions"` // 虚拟桌面坐标
+				RedactProcesses []string `json:"redactProcesses"`
+
+				RecordingEnabled        bool   `json:"recordingEnabled"`
+				RecordingSegmentSeconds int    `json:"recordingSegmentSeconds"`
+				RecordingContainer      string `json:"recordingContainer"` // mp4 / mkv
+				RecordingMaxDiskMB      int    `json:"recordingMaxDiskMB"`
+				StreamProfile           string `json:"streamProfile"`
+
+				CameraEnabled         bool `json:"cameraEnabled"`
+				CameraIntervalSeconds int  `json:"cameraIntervalSeconds"`
+
+				MicrophoneEnabled      bool    `json:"microphoneEnabled"`
+				SpeechThresholdDBFS    float64 `json:"speechThresholdDbfs"`
+				SpeechSustainedSeconds int     `json:"speechSustainedSeconds"`
+				AudioClipEnabled       bool    `json:"audioClipEnabled"`
+
+				USBStorageBlock string `json:"usbStorageBlock"` // unmount / readonly / deauthorize
+
+				RemoteAccessDetection       bool `json:"remoteAccessDetection"`
+				RemoteAccessIntervalSeconds int  `json:"remoteAccessIntervalSeconds"`
+
+				WebSocketEndpoint string `json:"websocketEndpoint"` // ws:// 或 wss:// 完整端点
 			} `json:"examDetails"`
 		} `json:"data"`
 	}
@@ -303,6 +851,89 @@ func getExamineeInfo() (map[string]interface{}, error) {
 		return nil, fmt.Errorf("获取考生信息失败: %s", infoResp.Msg)
 	}
 
+	// 保存考试的域名访问策略
+	appConfig.AllowedDomains = infoResp.Data.ExamDetails.AllowedDomains
+	appConfig.ForbiddenDomains = infoResp.Data.ExamDetails.ForbiddenDomains
+	appConfig.UploadRateThreshold = infoResp.Data.ExamDetails.UploadRateThreshold
+
+	// 考试未指定的截图编码参数保持默认值
+	details := infoResp.Data.ExamDetails
+	encoding := screencap.DefaultEncoderConfig
+	if details.ScreenshotFormat != "" {
+		if err := screencap.CheckFormat(screencap.Format(details.ScreenshotFormat)); err != nil {
+			fmt.Printf("%v，使用默认格式\n", err)
+		} else {
+			encoding.Format = screencap.Format(details.ScreenshotFormat)
+		}
+	}
+	if details.ScreenshotQuality > 0 {
+		encoding.Quality = details.ScreenshotQuality
+		encoding.MinQuality = min(encoding.MinQuality, details.ScreenshotQuality)
+	}
+	if details.ScreenshotMaxWidth > 0 {
+		encoding.MaxWidth = details.ScreenshotMaxWidth
+	}
+	if details.ScreenshotMaxHeight > 0 {
+		encoding.MaxHeight = details.ScreenshotMaxHeight
+	}
+	encoding.Grayscale = details.ScreenshotGrayscale
+	appConfig.ScreenshotEncoding = encoding
+	appConfig.ScreenshotKey = details.ScreenshotKey
+	mode, err := screencap.ParseCaptureMode(details.ScreenshotMode)
+	if err != nil {
+		fmt.Println(err)
+	}
+	appConfig.ScreenCaptureMode = mode
+
+	// 截图隐私遮盖
+	redaction := screencap.Redaction{Processes: details.RedactProcesses}
+	for _, r := range details.RedactRegions {
+		redaction.Regions = append(redaction.Regions, image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height))
+	}
+	appConfig.Redaction = redaction
+
+	// 本地分段录屏
+	recording := ffmpeg.DefaultRecorderConfig
+	recording.SegmentDuration = time.Duration(details.RecordingSegmentSeconds) * time.Second
+	if details.RecordingContainer != "" {
+		recording.Container = details.RecordingContainer
+	}
+	if details.RecordingMaxDiskMB > 0 {
+		recording.MaxDiskUsage = int64(details.RecordingMaxDiskMB) << 20
+	}
+	appConfig.RecordingEnabled = details.RecordingEnabled
+	appConfig.Recording = recording
+	appConfig.StreamProfile = details.StreamProfile
+
+	// 摄像头抓拍
+	appConfig.CameraEnabled = details.CameraEnabled
+	appConfig.CameraInterval = time.Duration(details.CameraIntervalSeconds) * time.Second
+
+	// 麦克风说话检测
+	vad := audio.DefaultVADConfig
+	if details.SpeechThresholdDBFS < 0 {
+		vad.Threshold = details.SpeechThresholdDBFS
+	}
+	if details.SpeechSustainedSeconds > 0 {
+		vad.MinSustained = time.Duration(details.SpeechSustainedSeconds) * time.Second
+	}
+	appConfig.MicrophoneEnabled = details.MicrophoneEnabled
+	appConfig.MicrophoneVAD = vad
+	appConfig.AudioClipEnabled = details.AudioClipEnabled
+
+	// USB存储设备管控
+	appConfig.USBStorageBlock = devices.EnforceMode(details.USBStorageBlock)
+
+	// 远程控制和远程会话检测
+	appConfig.RemoteAccessDetection = details.RemoteAccessDetection
+	appConfig.RemoteAccessInterval = time.Duration(details.RemoteAccessIntervalSeconds) * time.Second
+
+	// 监控WebSocket端点
+	appConfig.WSEndpoint = details.WebSocketEndpoint
+	if appConfig.WSEndpoint == "" {
+		appConfig.WSEndpoint = websocketEndpoint(appConfig.ServerURL)
+	}
+
 	// 构造前端需要的考试信息
 	result := map[string]interface{}{
 		"examId":        infoResp.Data.ExamDetails.Id,
@@ -323,6 +954,23 @@ func getExamineeInfo() (map[string]interface{}, error) {
 	return result, nil
 }
 
+// websocketEndpoint 由服务器URL推导监控WebSocket端点，
+// 如 https://monitor.ivresse.top/api 对应 wss://monitor.ivresse.top/api/ws/monitor
+func websocketEndpoint(serverURL string) string {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		fmt.Printf("解析服务器地址失败: %v\n", err)
+		return ""
+	}
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	} else {
+		u.Scheme = "ws"
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/ws/monitor"
+	return u.String()
+}
+
 // 安全获取字符串值
 func getStringValue(data map[string]interface{}, key string) string {
 	if value, ok := data[key]; ok {
@@ -453,63 +1101,65 @@ func registerUSBMonitorEvent() {
 		ipc.Emit("usbDevicesResult", result, "")
 	})
 
-	// 启动定时监控USB设备
-	utils.Go(monitorUSBDevices)
-}
-
-// 监控USB设备变化
-func monitorUSBDevices() {
-	var lastDevices []devices.USBDevice
-	ticker := time.NewTicker(time.Second * 3)
-	defer ticker.Stop()
+	// 上次运行在考试中异常退出时，恢复仍处于只读或禁用状态的USB存储设备，审计记录在登录后上报
+	devices.RestorePending(queueUSBRestore)
 
-	for range ticker.C {
-		currentDevices, err := devices.GetUSBDevices()
-		if err != nil {
-			continue
-		}
+	// 监听USB设备热插拔
+	watcher := devices.NewWatcher(handleUSBEvent)
+	usbWatcher.Store(watcher)
+	watcher.Start()
+}
 
-		// 检测设备变化
-		if hasDeviceChanges(lastDevices, currentDevices) {
-			// 将设备信息发送到前端
-			var result []map[string]interface{}
-			for _, device := range currentDevices {
-				deviceInfo := map[string]interface{}{
-					"name":         device.DeviceName,
-					"type":         device.DeviceType,
-					"manufacturer": device.Manufacturer,
-					"isStorage":    device.IsStorageType,
-					"driveLetters": device.DriveLetters,
-				}
-				result = append(result, deviceInfo)
-			}
+// USB设备热插拔监听，在监听协程中读取
+var usbWatcher atomic.Pointer[devices.Watcher]
 
-			ipc.Emit("usbDevicesChanged", result)
-		}
+// 考试期间的USB存储设备管控，登录登出时在 IPC 协程中设置，在监听协程中读取
+var usbEnforcer atomic.Pointer[devices.Enforcer]
 
-		lastDevices = currentDevices
+// handleUSBEvent 通知前端设备变化，考试期间接入存储设备按高风险行为上报
+func handleUSBEvent(event devices.DeviceEvent) {
+	if enforcer := usbEnforcer.Load(); enforcer != nil {
+		enforcer.Handle(event)
 	}
-}
 
-// 检测设备是否有变化
-func hasDeviceChanges(old, new []devices.USBDevice) bool {
-	if len(old) != len(new) {
-		return true
-	}
+	device := event.Device
+	ipc.Emit("usbDeviceEvent", string(event.Type), map[string]interface{}{
+		"name":         device.DeviceName,
+		"type":         device.DeviceType,
+		"manufacturer": device.Manufacturer,
+		"isStorage":    device.IsStorageType,
+		"driveLetters": device.DriveLetters,
+	})
 
-	// 简单比较设备ID
-	oldIDs := make(map[string]bool)
-	for _, device := range old {
-		oldIDs[device.DeviceID] = true
+	// 保持原有的整表通知，前端据此刷新设备列表
+	var result []map[string]interface{}
+	for _, d := range usbWatcher.Load().Devices() {
+		result = append(result, map[string]interface{}{
+			"name":         d.DeviceName,
+			"type":         d.DeviceType,
+			"manufacturer": d.Manufacturer,
+			"isStorage":    d.IsStorageType,
+			"driveLetters": d.DriveLetters,
+		})
 	}
+	ipc.Emit("usbDevicesChanged", result)
 
-	for _, device := range new {
-		if !oldIDs[device.DeviceID] {
-			return true
+	if !device.IsStorageType {
+		return
+	}
+	switch event.Type {
+	case devices.DeviceAdded:
+		content := fmt.Sprintf("接入USB存储设备: %s %s (%s)", device.Manufacturer, device.DeviceName, device.DeviceID)
+		reportBehavior(utils.BehaviorUSBStorageInserted, content, utils.LevelHigh)
+	case devices.DeviceChanged:
+		if len(device.DriveLetters) > 0 {
+			content := fmt.Sprintf("USB存储设备已挂载: %s (%s)", device.DeviceName, strings.Join(device.DriveLetters, ", "))
+			reportBehavior(utils.BehaviorUSBStorageInserted, content, utils.LevelHigh)
 		}
+	case devices.DeviceRemoved:
+		content := fmt.Sprintf("移除USB存储设备: %s (%s)", device.DeviceName, device.DeviceID)
+		reportBehavior(utils.BehaviorUSBStorageRemoved, content, utils.LevelInfo)
 	}
-
-	return false
 }
 
 // 注册设备信息获取事件
@@ -532,6 +1182,18 @@ func registerDeviceInfoEvent() {
 	})
 }
 
+// 注册 ffmpeg 状态查询事件
+func registerFfmpegStatusEvent() {
+	ipc.On("getFfmpegStatus", func() {
+		data, err := json.Marshal(ffmpeg.Statuses())
+		if err != nil {
+			fmt.Println("序列化ffmpeg状态失败:", err)
+			return
+		}
+		ipc.Emit("ffmpegStatuses", string(data))
+	})
+}
+
 // 加载配置
 func loadConfig() *Config {
 	// 这里可以从配置文件或环境变量加载
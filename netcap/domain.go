@@ -0,0 +1,214 @@
+package netcap
+
+import (
+	"log"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DomainCategory 域名分类
+type DomainCategory string
+
+const (
+	DomainUnknown   DomainCategory = "unknown"   // 未在任何名单中
+	DomainAllowed   DomainCategory = "allowed"   // 考试允许访问
+	DomainForbidden DomainCategory = "forbidden" // 考试禁止访问
+	DomainNoise     DomainCategory = "noise"     // 系统或浏览器后台流量，不上报
+)
+
+// DedupWindow 同一进程重复访问同一域名时的去重窗口
+const DedupWindow = 5 * time.Minute
+
+// NoiseDomains 内置的系统、浏览器后台及CDN、更新域名
+var NoiseDomains = []string{
+	// Windows 更新与遥测
+	"windowsupdate.com",
+	"update.microsoft.com",
+	"delivery.mp.microsoft.com",
+	"events.data.microsoft.com",
+	"settings-win.data.microsoft.com",
+	"telemetry.microsoft.com",
+	"smartscreen.microsoft.com",
+	"smartscreen-prod.microsoft.com",
+	"msftconnecttest.com",
+	"msftncsi.com",
+	"wns.windows.com",
+	"dl.delivery.mp.microsoft.com",
+	// 证书吊销检查
+	"re:^(ocsp|crl)[0-9]*\\.",
+	// Chrome / Edge 后台
+	"safebrowsing.googleapis.com",
+	"update.googleapis.com",
+	"clientservices.googleapis.com",
+	"optimizationguide-pa.googleapis.com",
+	"content-autofill.googleapis.com",
+	"edge.microsoft.com",
+	"config.edge.skype.com",
+	"edgedl.me.gvt1.com",
+	// Firefox 后台
+	"detectportal.firefox.com",
+	"push.services.mozilla.com",
+	"telemetry.mozilla.org",
+	"firefox.settings.services.mozilla.com",
+	// Linux 发行版连通性检测与更新
+	"connectivity-check.ubuntu.com",
+	"api.snapcraft.io",
+	"*.archive.ubuntu.com",
+	// CDN
+	"akamaiedge.net",
+	"akamaized.net",
+	"edgekey.net",
+	"cloudfront.net",
+}
+
+// DomainList 域名匹配名单
+// 普通条目按后缀匹配(example.com 同时匹配 a.example.com)，
+// 含 * 的条目按通配符匹配，以 re: 开头的条目按正则匹配
+type DomainList struct {
+	suffixes []string
+	globs    []string
+	regexps  []*regexp.Regexp
+}
+
+// NewDomainList 解析域名名单，无效的正则会被忽略
+func NewDomainList(patterns []string) *DomainList {
+	l := &DomainList{}
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch {
+		case p == "":
+		case strings.HasPrefix(p, "re:"):
+			re, err := regexp.Compile(p[len("re:"):])
+			if err != nil {
+				log.Printf("忽略无效的域名正则 %s: %v", p, err)
+				continue
+			}
+			l.regexps = append(l.regexps, re)
+		case strings.Contains(p, "*"):
+			l.globs = append(l.globs, p)
+		default:
+			l.suffixes = append(l.suffixes, strings.TrimPrefix(p, "."))
+		}
+	}
+	return l
+}
+
+// Match 判断域名是否命中名单
+func (l *DomainList) Match(domain string) bool {
+	if l == nil {
+		return false
+	}
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	for _, suffix := range l.suffixes {
+		if domain == suffix || strings.HasSuffix(domain, "."+suffix) {
+			return true
+		}
+	}
+	for _, glob := range l.globs {
+		if ok, _ := path.Match(glob, domain); ok {
+			return true
+		}
+	}
+	for _, re := range l.regexps {
+		if re.MatchString(domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// DomainFilter 对捕获到的域名进行分类、降噪和去重
+// 创建后在后台定时清理过期的去重记录，不再使用时调用 Close 停止
+type DomainFilter struct {
+	mu        sync.Mutex
+	noise     *DomainList
+	allowed   *DomainList
+	forbidden *DomainList
+	window    time.Duration
+	lastSeen  map[string]time.Time
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewDomainFilter 创建域名过滤器，allowed 和 forbidden 一般来自考试配置
+func NewDomainFilter(allowed, forbidden []string) *DomainFilter {
+	f := &DomainFilter{
+		noise:     NewDomainList(NoiseDomains),
+		allowed:   NewDomainList(allowed),
+		forbidden: NewDomainList(forbidden),
+		window:    DedupWindow,
+		lastSeen:  make(map[string]time.Time),
+		stop:      make(chan struct{}),
+	}
+	go f.expire()
+	return f
+}
+
+// Close 停止后台清理
+func (f *DomainFilter) Close() {
+	f.closeOnce.Do(func() {
+		close(f.stop)
+	})
+}
+
+// expire 每个去重窗口清理一次过期记录，避免长时间考试中无限增长
+func (f *DomainFilter) expire() {
+	ticker := time.NewTicker(f.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case now := <-ticker.C:
+			f.sweep(now)
+		}
+	}
+}
+
+// sweep 删除在 now 之前已超过去重窗口的记录
+func (f *DomainFilter) sweep(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for k, t := range f.lastSeen {
+		if now.Sub(t) >= f.window {
+			delete(f.lastSeen, k)
+		}
+	}
+}
+
+// Classify 返回域名分类，禁止名单优先于允许名单，两者都优先于内置噪声名单
+func (f *DomainFilter) Classify(domain string) DomainCategory {
+	switch {
+	case f.forbidden.Match(domain):
+		return DomainForbidden
+	case f.allowed.Match(domain):
+		return DomainAllowed
+	case f.noise.Match(domain):
+		return DomainNoise
+	default:
+		return DomainUnknown
+	}
+}
+
+// Accept 对访问事件分类，噪声域名和去重窗口内的重复访问返回 false
+func (f *DomainFilter) Accept(event *SniEvent) bool {
+	event.Category = f.Classify(event.Domain)
+	if event.Category == DomainNoise {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	key := strings.ToLower(event.Domain) + "|" + event.ProcessName
+	if last, ok := f.lastSeen[key]; ok && now.Sub(last) < f.window {
+		return false
+	}
+	f.lastSeen[key] = now
+	return true
+}
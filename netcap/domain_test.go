@@ -0,0 +1,128 @@
+package netcap
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDomainListMatch(t *testing.T) {
+	list := NewDomainList([]string{
+		"Example.com",
+		".exam.edu.cn",
+		"*.cdn.example.net",
+		"api-*.service.io",
+		`re:^(ocsp|crl)[0-9]*\.`,
+		"re:([",
+		"  ",
+	})
+	tests := map[string]bool{
+		// 后缀匹配，忽略大小写和末尾的点
+		"example.com":        true,
+		"WWW.Example.COM.":   true,
+		"a.b.example.com":    true,
+		"badexample.com":     false,
+		"example.com.evil":   false,
+		"exam.edu.cn":        true,
+		"online.exam.edu.cn": true,
+		// 通配符可匹配多级子域名，但不匹配上级域名本身
+		"img.cdn.example.net":   true,
+		"a.img.cdn.example.net": true,
+		"cdn.example.net":       false,
+		"api-v2.service.io":     true,
+		"api.service.io":        false,
+		// 正则
+		"ocsp.digicert.com": true,
+		"crl3.digicert.com": true,
+		"myocsp.com":        false,
+		"":                  false,
+	}
+	for domain, want := range tests {
+		if got := list.Match(domain); got != want {
+			t.Errorf("Match(%q) = %v, want %v", domain, got, want)
+		}
+	}
+
+	if len(list.regexps) != 1 {
+		t.Errorf("无效的正则应被忽略, regexps = %d", len(list.regexps))
+	}
+	var empty *DomainList
+	if empty.Match("example.com") {
+		t.Error("空名单不应匹配")
+	}
+}
+
+func TestDomainFilterClassify(t *testing.T) {
+	f := NewDomainFilter(
+		[]string{"exam.edu.cn", "googleapis.com"},
+		[]string{"chat.exam.edu.cn", "*.openai.com", "update.googleapis.com"},
+	)
+	defer f.Close()
+
+	tests := map[string]DomainCategory{
+		"online.exam.edu.cn":  DomainAllowed,
+		"chat.exam.edu.cn":    DomainForbidden,
+		"api.openai.com":      DomainForbidden,
+		"www.baidu.com":       DomainUnknown,
+		"msftconnecttest.com": DomainNoise,
+		// 禁止名单优先于允许名单，允许名单优先于内置噪声名单
+		"update.googleapis.com":       DomainForbidden,
+		"safebrowsing.googleapis.com": DomainAllowed,
+	}
+	for domain, want := range tests {
+		if got := f.Classify(domain); got != want {
+			t.Errorf("Classify(%q) = %s, want %s", domain, got, want)
+		}
+	}
+}
+
+func TestDomainFilterAccept(t *testing.T) {
+	f := NewDomainFilter(nil, []string{"forbidden.com"})
+	defer f.Close()
+
+	tests := []struct {
+		domain, process string
+		want            bool
+		category        DomainCategory
+	}{
+		{"www.example.com", "chrome.exe", true, DomainUnknown},
+		// 同一进程重复访问去重，域名大小写不同视为同一域名
+		{"WWW.example.com", "chrome.exe", false, DomainUnknown},
+		{"www.example.com", "firefox.exe", true, DomainUnknown},
+		{"ocsp.digicert.com", "chrome.exe", false, DomainNoise},
+		{"a.forbidden.com", "chrome.exe", true, DomainForbidden},
+	}
+	for _, tt := range tests {
+		event := SniEvent{Domain: tt.domain, ProcessName: tt.process}
+		if got := f.Accept(&event); got != tt.want || event.Category != tt.category {
+			t.Errorf("Accept(%s, %s) = %v [%s], want %v [%s]", tt.domain, tt.process, got, event.Category, tt.want, tt.category)
+		}
+	}
+}
+
+func TestDomainFilterSweep(t *testing.T) {
+	f := NewDomainFilter(nil, nil)
+	defer f.Close()
+
+	event := SniEvent{Domain: "www.example.com", ProcessName: "chrome.exe"}
+	if !f.Accept(&event) {
+		t.Fatal("首次访问应上报")
+	}
+	f.mu.Lock()
+	f.lastSeen["old.example.com|chrome.exe"] = time.Now().Add(-2 * DedupWindow)
+	f.mu.Unlock()
+
+	f.sweep(time.Now())
+	f.mu.Lock()
+	_, kept := f.lastSeen["www.example.com|chrome.exe"]
+	_, expired := f.lastSeen["old.example.com|chrome.exe"]
+	f.mu.Unlock()
+	if !kept || expired {
+		t.Errorf("sweep 后 kept = %v, expired = %v", kept, expired)
+	}
+
+	// 去重窗口之后再次访问重新上报
+	f.sweep(time.Now().Add(DedupWindow))
+	if !f.Accept(&event) {
+		t.Error("去重窗口过后应再次上报")
+	}
+}
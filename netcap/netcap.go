@@ -5,11 +5,11 @@ import (
 	"encoding/binary"
 	"log"
 	"monitor-desktop-client/utils"
+	"sync"
 	"time"
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
-	"github.com/google/gopacket/pcap"
 	"github.com/google/gopacket/tcpassembly"
 	"github.com/google/gopacket/tcpassembly/tcpreader"
 )
@@ -17,12 +17,27 @@ import (
 const (
 	Snaplen      = 1600
 	Promiscuous  = true
-	Timeout      = pcap.BlockForever
 	StreamExpiry = time.Minute // TCP流超时时间
 )
 
 type SniStreamFactory struct {
-	Ch chan string
+	Ch   chan SniEvent
+	Done chan struct{} // 抓包结束(设备移除或调用Close)时关闭
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// SniEvent 从TLS握手中解析出的一次域名访问及其连接来源
+type SniEvent struct {
+	Domain      string         // SNI域名
+	SrcIP       string         // 本地地址
+	SrcPort     uint16         // 本地端口
+	DstIP       string         // 远端地址
+	DstPort     uint16         // 远端端口
+	ProcessID   int32          // 发起连接的进程ID，无法归属时为0
+	ProcessName string         // 发起连接的进程名
+	Category    DomainCategory // 域名分类，由 DomainFilter 填充
 }
 type SniStream struct {
 	bytes []byte
@@ -31,17 +46,12 @@ type SniStream struct {
 
 func OpenLive(device string) *SniStreamFactory {
 
-	handle, err := pcap.OpenLive(
-		device,
-		Snaplen,
-		Promiscuous,
-		Timeout,
-	)
+	handle, err := OpenSource(device, Snaplen, Promiscuous)
 	if err != nil {
 		log.Println("无法打开网络设备:", device, err)
 		return nil
 	}
-	log.Println("成功打开网络监控设备:", device)
+	log.Printf("成功打开网络监控设备: %s (后端: %s)", device, Backend())
 
 	// 注意：不要在这里提前关闭，移到协程内部
 	// defer handle.Close()
@@ -57,7 +67,9 @@ func OpenLive(device string) *SniStreamFactory {
 
 	// 创建TCP流重组器
 	streamFactory := &SniStreamFactory{
-		Ch: make(chan string, 1024),
+		Ch:   make(chan SniEvent, 1024),
+		Done: make(chan struct{}),
+		stop: make(chan struct{}),
 	}
 	streamPool := tcpassembly.NewStreamPool(streamFactory)
 	assembler := tcpassembly.NewAssembler(streamPool)
@@ -69,6 +81,7 @@ func OpenLive(device string) *SniStreamFactory {
 
 	ticker := time.Tick(time.Minute)
 	utils.Go(func() {
+		defer close(streamFactory.Done)
 		defer handle.Close() // 移动到这里确保协程结束时关闭句柄
 		log.Printf("开始处理来自设备 %s 的网络数据包", device)
 
@@ -77,6 +90,8 @@ func OpenLive(device string) *SniStreamFactory {
 			case packet := <-packets:
 				if packet == nil {
 					log.Printf("设备 %s 停止提供数据包", device)
+					// 关闭所有流，使读取流数据的协程退出
+					assembler.FlushAll()
 					return
 				}
 				if packet.NetworkLayer() == nil || packet.TransportLayer() == nil {
@@ -98,13 +113,25 @@ func OpenLive(device string) *SniStreamFactory {
 				// 定期清理过期的流
 				log.Printf("清理设备 %s 的过期流", device)
 				assembler.FlushOlderThan(time.Now().Add(-StreamExpiry))
+
+			case <-streamFactory.stop:
+				log.Printf("停止监控网络设备: %s", device)
+				assembler.FlushAll()
+				return
 			}
 		}
 	})
 	return streamFactory
 }
 
-func (s *SniStreamFactory) New(netFlow, _ gopacket.Flow) tcpassembly.Stream {
+// Close 停止抓包并释放设备句柄，可重复调用
+func (s *SniStreamFactory) Close() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+func (s *SniStreamFactory) New(netFlow, tcpFlow gopacket.Flow) tcpassembly.Stream {
 	stream := &SniStream{}
 	r := tcpreader.NewReaderStream()
 
@@ -121,8 +148,14 @@ func (s *SniStreamFactory) New(netFlow, _ gopacket.Flow) tcpassembly.Stream {
 			stream.bytes = append(stream.bytes, buf[:n]...)
 			// 尝试解析SNI
 			if sni, ok := processDataHead(stream.bytes); ok && !stream.done {
-				log.Printf("[SNI] 成功解析域名: %s (来源: %s)", sni, netFlow)
-				s.Ch <- sni // 确保SNI数据被发送到通道
+				event := newSniEvent(sni, netFlow, tcpFlow)
+				log.Printf("[SNI] 成功解析域名: %s (来源: %s, 进程: %s[%d])", sni, netFlow, event.ProcessName, event.ProcessID)
+				// 抓包停止后消费者不再读取通道，不能一直阻塞，否则协程和重组器都无法退出
+				select {
+				case s.Ch <- event:
+				case <-s.stop:
+				case <-s.Done:
+				}
 				stream.done = true
 			}
 		}
@@ -130,6 +163,29 @@ func (s *SniStreamFactory) New(netFlow, _ gopacket.Flow) tcpassembly.Stream {
 	return &r
 }
 
+// newSniEvent 构建域名访问事件，并通过系统套接字表归属到本地进程
+// ClientHello 由客户端发出，因此流的源端即本机一侧
+func newSniEvent(domain string, netFlow, tcpFlow gopacket.Flow) SniEvent {
+	event := SniEvent{
+		Domain:  domain,
+		SrcIP:   netFlow.Src().String(),
+		DstIP:   netFlow.Dst().String(),
+		SrcPort: endpointPort(tcpFlow.Src()),
+		DstPort: endpointPort(tcpFlow.Dst()),
+	}
+	event.ProcessID, event.ProcessName = LookupOwner(event.SrcIP, event.SrcPort, event.DstIP, event.DstPort)
+	return event
+}
+
+// endpointPort 从TCP端点中取出端口号
+func endpointPort(endpoint gopacket.Endpoint) uint16 {
+	raw := endpoint.Raw()
+	if len(raw) != 2 {
+		return 0
+	}
+	return binary.BigEndian.Uint16(raw)
+}
+
 func processDataHead(data []byte) (string, bool) {
 	r := bytes.NewReader(data)
 
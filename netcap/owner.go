@@ -0,0 +1,156 @@
+package netcap
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const (
+	ConnTableRefresh  = time.Second            // 后台刷新连接表的间隔
+	ConnTableMinFresh = 200 * time.Millisecond // 查询未命中提前刷新时，两次刷新之间的最小间隔
+	ConnTableIdle     = time.Minute            // 超过该时间没有查询时停止后台刷新
+)
+
+// UnknownProcess 连接表中暂时找不到连接时的进程名
+const UnknownProcess = "unknown"
+
+// connOwnerTable 系统套接字表的缓存，用于把TCP流映射到所属进程
+// 连接表在后台协程中刷新，查询不会等待读取系统连接表
+type connOwnerTable struct {
+	mu         sync.Mutex
+	byTuple    map[string]int32 // 本地地址:端口-远端地址:端口 -> PID
+	byPort     map[uint32]int32 // 监听端口 -> PID，本地地址为通配地址时使用
+	names      map[int32]string // PID -> 进程名
+	running    bool             // 后台刷新协程是否在运行
+	lastLookup time.Time
+	wake       chan struct{} // 查询未命中时通知后台协程提前刷新
+}
+
+var owners = &connOwnerTable{
+	byTuple: make(map[string]int32),
+	byPort:  make(map[uint32]int32),
+	names:   make(map[int32]string),
+	wake:    make(chan struct{}, 1),
+}
+
+// LookupOwner 根据连接的四元组查找所属进程，缓存中没有时返回 0 和 UnknownProcess
+// 新建立的连接会在后台刷新后找到，之后的查询即可命中
+func LookupOwner(localIP string, localPort uint16, remoteIP string, remotePort uint16) (int32, string) {
+	owners.mu.Lock()
+	owners.lastLookup = time.Now()
+	if !owners.running {
+		owners.running = true
+		go owners.run()
+	}
+	pid, ok := owners.find(localIP, localPort, remoteIP, remotePort)
+	var name string
+	if ok {
+		name = owners.processName(pid)
+	}
+	owners.mu.Unlock()
+
+	if !ok {
+		select {
+		case owners.wake <- struct{}{}:
+		default:
+		}
+		return 0, UnknownProcess
+	}
+	return pid, name
+}
+
+// run 定时刷新连接表，查询未命中时提前刷新，长时间没有查询(例如停止抓包)后退出
+func (t *connOwnerTable) run() {
+	ticker := time.NewTicker(ConnTableRefresh)
+	defer ticker.Stop()
+
+	var last time.Time
+	for {
+		if wait := ConnTableMinFresh - time.Since(last); wait > 0 {
+			time.Sleep(wait)
+		}
+		t.refresh()
+		last = time.Now()
+
+		select {
+		case <-ticker.C:
+		case <-t.wake:
+		}
+
+		t.mu.Lock()
+		idle := time.Since(t.lastLookup) > ConnTableIdle
+		if idle {
+			t.running = false
+		}
+		t.mu.Unlock()
+		if idle {
+			return
+		}
+	}
+}
+
+// find 在缓存中查找连接，优先完整四元组匹配
+func (t *connOwnerTable) find(localIP string, localPort uint16, remoteIP string, remotePort uint16) (int32, bool) {
+	if pid, ok := t.byTuple[tupleKey(localIP, uint32(localPort), remoteIP, uint32(remotePort))]; ok {
+		return pid, true
+	}
+	pid, ok := t.byPort[uint32(localPort)]
+	return pid, ok
+}
+
+// refresh 在锁外读取系统套接字表，再替换缓存
+func (t *connOwnerTable) refresh() {
+	conns, err := net.Connections("tcp")
+	if err != nil {
+		log.Println("读取系统连接表失败:", err)
+		return
+	}
+	t.load(conns)
+}
+
+// load 用套接字表重建缓存，只有监听套接字按端口索引，避免客户端端口被误归属
+func (t *connOwnerTable) load(conns []net.ConnectionStat) {
+	byTuple := make(map[string]int32, len(conns))
+	byPort := make(map[uint32]int32)
+	for _, c := range conns {
+		if c.Pid == 0 {
+			continue
+		}
+		if c.Status == "LISTEN" {
+			byPort[c.Laddr.Port] = c.Pid
+			continue
+		}
+		if c.Raddr.Port != 0 {
+			byTuple[tupleKey(c.Laddr.IP, c.Laddr.Port, c.Raddr.IP, c.Raddr.Port)] = c.Pid
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.byTuple = byTuple
+	t.byPort = byPort
+	t.names = make(map[int32]string) // PID可能被复用，随连接表一起失效
+}
+
+// processName 获取进程名，结果按PID缓存
+func (t *connOwnerTable) processName(pid int32) string {
+	if name, ok := t.names[pid]; ok {
+		return name
+	}
+
+	var name string
+	if p, err := process.NewProcess(pid); err == nil {
+		name, _ = p.Name()
+	}
+	t.names[pid] = name
+	return name
+}
+
+func tupleKey(localIP string, localPort uint32, remoteIP string, remotePort uint32) string {
+	return fmt.Sprintf("%s:%d-%s:%d", localIP, localPort, remoteIP, remotePort)
+}
@@ -0,0 +1,93 @@
+package netcap
+
+import (
+	"io"
+	stdnet "net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestConnOwnerTableLoad(t *testing.T) {
+	table := &connOwnerTable{names: make(map[int32]string)}
+	table.load([]net.ConnectionStat{
+		{Pid: 100, Status: "ESTABLISHED", Laddr: net.Addr{IP: "192.168.1.10", Port: 50312}, Raddr: net.Addr{IP: "93.184.216.34", Port: 443}},
+		{Pid: 200, Status: "LISTEN", Laddr: net.Addr{IP: "0.0.0.0", Port: 8080}},
+		{Pid: 300, Status: "SYN_SENT", Laddr: net.Addr{IP: "192.168.1.10", Port: 50400}, Raddr: net.Addr{IP: "1.1.1.1", Port: 443}},
+		// 没有进程信息(其他用户的套接字)
+		{Pid: 0, Status: "ESTABLISHED", Laddr: net.Addr{IP: "192.168.1.10", Port: 50500}, Raddr: net.Addr{IP: "8.8.8.8", Port: 443}},
+	})
+
+	tests := []struct {
+		name       string
+		localIP    string
+		localPort  uint16
+		remoteIP   string
+		remotePort uint16
+		pid        int32
+		found      bool
+	}{
+		{"tuple", "192.168.1.10", 50312, "93.184.216.34", 443, 100, true},
+		{"syn-sent", "192.168.1.10", 50400, "1.1.1.1", 443, 300, true},
+		// 监听端口按端口匹配，用于本地地址为通配地址的连接
+		{"listen", "10.0.0.2", 8080, "10.0.0.3", 51000, 200, true},
+		// 客户端端口不按端口匹配，避免端口复用后归属到其他进程
+		{"client-port", "192.168.1.10", 50312, "1.2.3.4", 443, 0, false},
+		{"no-pid", "192.168.1.10", 50500, "8.8.8.8", 443, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid, ok := table.find(tt.localIP, tt.localPort, tt.remoteIP, tt.remotePort)
+			if pid != tt.pid || ok != tt.found {
+				t.Errorf("find() = %d, %v, want %d, %v", pid, ok, tt.pid, tt.found)
+			}
+		})
+	}
+}
+
+// 新连接第一次查询可能未命中，后台刷新后应归属到本进程
+func TestLookupOwner(t *testing.T) {
+	listener, err := stdnet.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer listener.Close()
+	go func() {
+		// 保持连接直到客户端关闭
+		if c, err := listener.Accept(); err == nil {
+			_, _ = io.Copy(io.Discard, c)
+			c.Close()
+		}
+	}()
+	conn, err := stdnet.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	local := conn.LocalAddr().(*stdnet.TCPAddr)
+	remote := conn.RemoteAddr().(*stdnet.TCPAddr)
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		start := time.Now()
+		pid, name := LookupOwner(local.IP.String(), uint16(local.Port), remote.IP.String(), uint16(remote.Port))
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Errorf("查询耗时 %v，不应等待读取连接表", elapsed)
+		}
+		if pid == int32(os.Getpid()) {
+			if name == "" || name == UnknownProcess {
+				t.Errorf("进程名 = %q", name)
+			}
+			return
+		}
+		if pid != 0 || name != UnknownProcess {
+			t.Fatalf("LookupOwner() = %d, %q", pid, name)
+		}
+		if time.Now().After(deadline) {
+			t.Skip("无法从系统连接表读取本进程的连接")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
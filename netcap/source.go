@@ -0,0 +1,105 @@
+package netcap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// CaptureSource 抓包数据源，屏蔽 libpcap 与 AF_PACKET 等后端差异
+type CaptureSource interface {
+	gopacket.PacketDataSource
+	LinkType() layers.LinkType
+	SetBPFFilter(filter string) error
+	Close()
+}
+
+// Interface 可抓包的网络接口
+type Interface struct {
+	Name        string
+	Description string
+	Addresses   []string // IP地址
+}
+
+// 抓包后端名称
+const (
+	BackendPcap     = "pcap"     // libpcap / Npcap
+	BackendAFPacket = "afpacket" // Linux AF_PACKET，无需libpcap
+)
+
+// captureBackend 抓包后端实现，由各平台文件在 init 中注册
+type captureBackend struct {
+	priority int // 未指定后端时优先使用数值较小的
+	open     func(device string, snaplen int, promisc bool) (CaptureSource, error)
+	list     func() ([]Interface, error)
+}
+
+var (
+	backends       = make(map[string]captureBackend)
+	currentBackend string
+)
+
+// backendRequirements 各后端的编译条件，请求的后端未编译进来时提示如何获得
+var backendRequirements = map[string]string{
+	BackendPcap:     "需要 libpcap/Npcap，Linux 下需使用 -tags pcap 构建",
+	BackendAFPacket: "仅支持 Linux",
+}
+
+func registerBackend(name string, backend captureBackend) {
+	backends[name] = backend
+	if current, ok := backends[currentBackend]; !ok || backend.priority < current.priority {
+		currentBackend = name
+	}
+}
+
+// SetBackend 选择抓包后端，名称为空时保留默认后端
+func SetBackend(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := backends[name]; !ok {
+		available := strings.Join(AvailableBackends(), ", ")
+		if requirement, known := backendRequirements[name]; known {
+			return fmt.Errorf("抓包后端 %s 未编译进当前程序(%s)，可用: %s", name, requirement, available)
+		}
+		return fmt.Errorf("不支持的抓包后端: %s，可用: %s", name, available)
+	}
+	currentBackend = name
+	return nil
+}
+
+// Backend 返回当前使用的抓包后端名称
+func Backend() string {
+	return currentBackend
+}
+
+// AvailableBackends 返回当前平台编译进来的抓包后端
+func AvailableBackends() []string {
+	var names []string
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenSource 使用当前后端打开网络设备
+func OpenSource(device string, snaplen int, promisc bool) (CaptureSource, error) {
+	backend, ok := backends[currentBackend]
+	if !ok {
+		return nil, fmt.Errorf("没有可用的抓包后端")
+	}
+	return backend.open(device, snaplen, promisc)
+}
+
+// FindAllDevs 使用当前后端枚举可抓包的网络接口
+func FindAllDevs() ([]Interface, error) {
+	backend, ok := backends[currentBackend]
+	if !ok {
+		return nil, fmt.Errorf("没有可用的抓包后端")
+	}
+	return backend.list()
+}
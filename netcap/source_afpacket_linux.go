@@ -0,0 +1,202 @@
+//go:build linux
+
+package netcap
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/afpacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	AFPacketNumBlocks   = 8                      // 环形缓冲区块数，默认块大小下约4MB
+	AFPacketPollTimeout = 500 * time.Millisecond // 读取超时，便于关闭时及时退出
+)
+
+func init() {
+	registerBackend(BackendAFPacket, captureBackend{
+		priority: 1,
+		open:     openAFPacket,
+		list:     listInterfaces,
+	})
+}
+
+// afpacketSource 基于 AF_PACKET 环形缓冲区的抓包数据源，只支持以太网链路
+// tun、ppp、wwan 等接口没有链路层头部，BPF 程序和解码器按以太网偏移读取会出错，因此不打开这类接口
+type afpacketSource struct {
+	tpacket *afpacket.TPacket
+	closed  atomic.Bool
+}
+
+// openAFPacket 通过 AF_PACKET 打开网络设备，不依赖 libpcap
+// AF_PACKET 总是捕获整帧且不切换混杂模式，snaplen 和 promisc 仅为保持接口一致
+func openAFPacket(device string, _ int, _ bool) (CaptureSource, error) {
+	if !isEthernet(device) {
+		return nil, fmt.Errorf("AF_PACKET 后端只支持以太网接口: %s", device)
+	}
+	tpacket, err := afpacket.NewTPacket(
+		afpacket.OptInterface(device),
+		afpacket.OptNumBlocks(AFPacketNumBlocks),
+		afpacket.OptPollTimeout(AFPacketPollTimeout),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &afpacketSource{tpacket: tpacket}, nil
+}
+
+func (s *afpacketSource) ReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+	for {
+		if s.closed.Load() {
+			return nil, gopacket.CaptureInfo{}, io.EOF
+		}
+		data, ci, err := s.tpacket.ReadPacketData()
+		if err == afpacket.ErrTimeout {
+			continue
+		}
+		return data, ci, err
+	}
+}
+
+func (s *afpacketSource) LinkType() layers.LinkType {
+	return layers.LinkTypeEthernet
+}
+
+// SetBPFFilter 编译并设置内核过滤器，支持与 libpcap 语义一致的 "tcp" 和 "tcp port N"
+func (s *afpacketSource) SetBPFFilter(filter string) error {
+	instructions, err := compileFilter(filter)
+	if err != nil {
+		return err
+	}
+	if instructions == nil {
+		return nil
+	}
+
+	raw, err := bpf.Assemble(instructions)
+	if err != nil {
+		return fmt.Errorf("汇编BPF过滤器失败: %w", err)
+	}
+	return s.tpacket.SetBPF(raw)
+}
+
+func (s *afpacketSource) Close() {
+	if s.closed.CompareAndSwap(false, true) {
+		s.tpacket.Close()
+	}
+}
+
+var tcpFilterPattern = regexp.MustCompile(`^tcp(?:\s+port\s+(\d+))?$`)
+
+// 过滤器匹配时返回的抓包长度，与 tcpdump 生成的程序一致
+const bpfAccept = 262144
+
+// compileFilter 将过滤表达式编译为以太网链路上的BPF程序，空表达式返回 nil
+func compileFilter(filter string) ([]bpf.Instruction, error) {
+	if filter == "" {
+		return nil, nil
+	}
+
+	m := tcpFilterPattern.FindStringSubmatch(filter)
+	if m == nil {
+		return nil, fmt.Errorf("AF_PACKET 后端不支持的过滤表达式: %s", filter)
+	}
+	if m[1] == "" {
+		return tcpProgram(), nil
+	}
+
+	port, err := strconv.ParseUint(m[1], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("无效的端口: %s", m[1])
+	}
+	return tcpPortProgram(uint32(port)), nil
+}
+
+// tcpProgram 等价于 tcpdump -d "tcp"(不含IPv6扩展头)
+func tcpProgram() []bpf.Instruction {
+	return []bpf.Instruction{
+		/* 0 */ bpf.LoadAbsolute{Off: 12, Size: 2}, // 以太网类型
+		/* 1 */ bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x86dd, SkipFalse: 2},
+		/* 2 */ bpf.LoadAbsolute{Off: 20, Size: 1}, // IPv6 下一个头部
+		/* 3 */ bpf.JumpIf{Cond: bpf.JumpEqual, Val: 6, SkipTrue: 3, SkipFalse: 4},
+		/* 4 */ bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x0800, SkipFalse: 3},
+		/* 5 */ bpf.LoadAbsolute{Off: 23, Size: 1}, // IPv4 协议
+		/* 6 */ bpf.JumpIf{Cond: bpf.JumpEqual, Val: 6, SkipFalse: 1},
+		/* 7 */ bpf.RetConstant{Val: bpfAccept},
+		/* 8 */ bpf.RetConstant{Val: 0},
+	}
+}
+
+// tcpPortProgram 等价于 tcpdump -d "tcp port N"
+func tcpPortProgram(port uint32) []bpf.Instruction {
+	return []bpf.Instruction{
+		/* 0 */ bpf.LoadAbsolute{Off: 12, Size: 2}, // 以太网类型
+		/* 1 */ bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x86dd, SkipFalse: 6},
+		/* 2 */ bpf.LoadAbsolute{Off: 20, Size: 1}, // IPv6 下一个头部
+		/* 3 */ bpf.JumpIf{Cond: bpf.JumpEqual, Val: 6, SkipFalse: 15},
+		/* 4 */ bpf.LoadAbsolute{Off: 54, Size: 2}, // IPv6 源端口
+		/* 5 */ bpf.JumpIf{Cond: bpf.JumpEqual, Val: port, SkipTrue: 12},
+		/* 6 */ bpf.LoadAbsolute{Off: 56, Size: 2}, // IPv6 目的端口
+		/* 7 */ bpf.JumpIf{Cond: bpf.JumpEqual, Val: port, SkipTrue: 10, SkipFalse: 11},
+		/* 8 */ bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x0800, SkipFalse: 10},
+		/* 9 */ bpf.LoadAbsolute{Off: 23, Size: 1}, // IPv4 协议
+		/* 10 */ bpf.JumpIf{Cond: bpf.JumpEqual, Val: 6, SkipFalse: 8},
+		/* 11 */ bpf.LoadAbsolute{Off: 20, Size: 2}, // IPv4 分片偏移
+		/* 12 */ bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x1fff, SkipTrue: 6},
+		/* 13 */ bpf.LoadMemShift{Off: 14}, // X = IPv4 头部长度
+		/* 14 */ bpf.LoadIndirect{Off: 14, Size: 2}, // IPv4 源端口
+		/* 15 */ bpf.JumpIf{Cond: bpf.JumpEqual, Val: port, SkipTrue: 2},
+		/* 16 */ bpf.LoadIndirect{Off: 16, Size: 2}, // IPv4 目的端口
+		/* 17 */ bpf.JumpIf{Cond: bpf.JumpEqual, Val: port, SkipFalse: 1},
+		/* 18 */ bpf.RetConstant{Val: bpfAccept},
+		/* 19 */ bpf.RetConstant{Val: 0},
+	}
+}
+
+// listInterfaces 通过系统网络接口列表枚举可抓包的接口
+func listInterfaces() ([]Interface, error) {
+	ifs, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Interface, 0, len(ifs))
+	for _, iface := range ifs {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 || !isEthernet(iface.Name) {
+			continue
+		}
+		item := Interface{
+			Name:        iface.Name,
+			Description: iface.Name,
+		}
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				item.Addresses = append(item.Addresses, ipNet.IP.String())
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// isEthernet 根据 /sys/class/net/<接口>/type 判断是否为以太网链路(ARPHRD_ETHER)，无线网卡也属于此类
+func isEthernet(device string) bool {
+	data, err := os.ReadFile("/sys/class/net/" + device + "/type")
+	if err != nil {
+		return false
+	}
+	linkType, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return err == nil && linkType == unix.ARPHRD_ETHER
+}
@@ -0,0 +1,114 @@
+package netcap
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/bpf"
+)
+
+// testPacket 构造携带 TCP 或 UDP 头部的以太网帧
+func testPacket(t *testing.T, ipv6 bool, transport gopacket.SerializableLayer) []byte {
+	t.Helper()
+	eth := &layers.Ethernet{
+		SrcMAC: net.HardwareAddr{0xa4, 0x4c, 0xc8, 0x01, 0x02, 0x03},
+		DstMAC: net.HardwareAddr{0x9c, 0xb6, 0xd0, 0x04, 0x05, 0x06},
+	}
+	var network gopacket.NetworkLayer
+	protocol := layers.IPProtocolUDP
+	if _, ok := transport.(*layers.TCP); ok {
+		protocol = layers.IPProtocolTCP
+	}
+	if ipv6 {
+		eth.EthernetType = layers.EthernetTypeIPv6
+		network = &layers.IPv6{Version: 6, HopLimit: 64, NextHeader: protocol,
+			SrcIP: net.ParseIP("fd00::1"), DstIP: net.ParseIP("fd00::2")}
+	} else {
+		eth.EthernetType = layers.EthernetTypeIPv4
+		network = &layers.IPv4{Version: 4, TTL: 64, Protocol: protocol,
+			SrcIP: net.IPv4(192, 168, 1, 20), DstIP: net.IPv4(93, 184, 216, 34)}
+	}
+
+	serializable := []gopacket.SerializableLayer{eth, network.(gopacket.SerializableLayer)}
+	switch l := transport.(type) {
+	case *layers.TCP:
+		l.SetNetworkLayerForChecksum(network)
+		serializable = append(serializable, l)
+	case *layers.UDP:
+		l.SetNetworkLayerForChecksum(network)
+		serializable = append(serializable, l)
+	}
+
+	buffer := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buffer, opts, serializable...); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestCompileFilter(t *testing.T) {
+	for _, filter := range []string{"udp", "tcp port", "tcp port 70000", "tcp port -1", "port 443", "tcp or udp"} {
+		if _, err := compileFilter(filter); err == nil {
+			t.Errorf("compileFilter(%q) 应返回错误", filter)
+		}
+	}
+	if instructions, err := compileFilter(""); instructions != nil || err != nil {
+		t.Errorf("compileFilter(\"\") = %v, %v", instructions, err)
+	}
+
+	https := &layers.TCP{SrcPort: 50312, DstPort: 443, SYN: true, Window: 64240}
+	reply := &layers.TCP{SrcPort: 443, DstPort: 50312, ACK: true, Window: 64240}
+	web := &layers.TCP{SrcPort: 50313, DstPort: 80, SYN: true, Window: 64240}
+	dns := &layers.UDP{SrcPort: 53000, DstPort: 443}
+
+	packets := []struct {
+		name      string
+		ipv6      bool
+		transport gopacket.SerializableLayer
+		tcp       bool // 匹配 "tcp"
+		port      bool // 匹配 "tcp port 443"
+	}{
+		{"ipv4-https", false, https, true, true},
+		{"ipv4-reply", false, reply, true, true},
+		{"ipv4-web", false, web, true, false},
+		{"ipv4-udp", false, dns, false, false},
+		{"ipv6-https", true, https, true, true},
+		{"ipv6-reply", true, reply, true, true},
+		{"ipv6-web", true, web, true, false},
+		{"ipv6-udp", true, dns, false, false},
+	}
+
+	filters := []struct {
+		filter string
+		want   func(tcp, port bool) bool
+	}{
+		{"tcp", func(tcp, _ bool) bool { return tcp }},
+		{"tcp port 443", func(_, port bool) bool { return port }},
+		{"tcp  port  443", func(_, port bool) bool { return port }},
+	}
+	for _, f := range filters {
+		instructions, err := compileFilter(f.filter)
+		if err != nil {
+			t.Fatalf("compileFilter(%q) = %v", f.filter, err)
+		}
+		if _, err := bpf.Assemble(instructions); err != nil {
+			t.Fatalf("%q 汇编失败: %v", f.filter, err)
+		}
+		vm, err := bpf.NewVM(instructions)
+		if err != nil {
+			t.Fatalf("%q 程序无效: %v", f.filter, err)
+		}
+		for _, p := range packets {
+			n, err := vm.Run(testPacket(t, p.ipv6, p.transport))
+			if err != nil {
+				t.Fatalf("%q 运行 %s 失败: %v", f.filter, p.name, err)
+			}
+			if accepted, want := n > 0, f.want(p.tcp, p.port); accepted != want {
+				t.Errorf("%q 匹配 %s = %v, want %v", f.filter, p.name, accepted, want)
+			}
+		}
+	}
+}
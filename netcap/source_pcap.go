@@ -0,0 +1,48 @@
+//go:build !linux || pcap
+
+package netcap
+
+import (
+	"github.com/google/gopacket/pcap"
+)
+
+// Timeout libpcap 读取超时
+const Timeout = pcap.BlockForever
+
+func init() {
+	registerBackend(BackendPcap, captureBackend{
+		priority: 0,
+		open:     openPcap,
+		list:     listPcap,
+	})
+}
+
+// openPcap 通过 libpcap / Npcap 打开网络设备
+func openPcap(device string, snaplen int, promisc bool) (CaptureSource, error) {
+	handle, err := pcap.OpenLive(device, int32(snaplen), promisc, Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return handle, nil
+}
+
+// listPcap 通过 libpcap / Npcap 枚举网络接口
+func listPcap() ([]Interface, error) {
+	ifs, err := pcap.FindAllDevs()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Interface, 0, len(ifs))
+	for _, iface := range ifs {
+		item := Interface{
+			Name:        iface.Name,
+			Description: iface.Description,
+		}
+		for _, addr := range iface.Addresses {
+			item.Addresses = append(item.Addresses, addr.IP.String())
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
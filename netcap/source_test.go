@@ -0,0 +1,38 @@
+package netcap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetBackend(t *testing.T) {
+	previous := currentBackend
+	t.Cleanup(func() { currentBackend = previous })
+
+	if err := SetBackend(""); err != nil || Backend() != previous {
+		t.Fatalf("SetBackend(\"\") = %v, Backend() = %s", err, Backend())
+	}
+	for _, name := range AvailableBackends() {
+		if err := SetBackend(name); err != nil || Backend() != name {
+			t.Errorf("SetBackend(%q) = %v, Backend() = %s", name, err, Backend())
+		}
+	}
+
+	currentBackend = previous
+	for _, name := range []string{BackendPcap, BackendAFPacket, "dpdk"} {
+		if _, ok := backends[name]; ok {
+			continue
+		}
+		err := SetBackend(name)
+		if err == nil {
+			t.Fatalf("未编译的后端 %s 应返回错误", name)
+		}
+		// 错误中列出可用的后端，并保留原来的后端
+		if !strings.Contains(err.Error(), strings.Join(AvailableBackends(), ", ")) || Backend() != previous {
+			t.Errorf("SetBackend(%q) = %v, Backend() = %s", name, err, Backend())
+		}
+		if requirement, ok := backendRequirements[name]; ok && !strings.Contains(err.Error(), requirement) {
+			t.Errorf("SetBackend(%q) 未说明编译条件: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package netcap
+
+import (
+	"log"
+	"monitor-desktop-client/utils"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+// CounterSnaplen 流量计数只需要IP头部
+const CounterSnaplen = 128
+
+// HostTraffic 与单个远端主机之间的流量，定义在 utils 中以便上报接口使用具体类型
+type HostTraffic = utils.HostTraffic
+
+// InterfaceTraffic 单个网络接口在统计周期内的流量
+type InterfaceTraffic = utils.InterfaceTraffic
+
+// TrafficCounter 使用不带BPF过滤的抓包句柄按远端主机统计流量
+type TrafficCounter struct {
+	device string
+	local  map[string]bool // 本接口的本地地址，用于区分收发方向
+
+	mu    sync.Mutex
+	hosts map[string]*HostTraffic
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// OpenCounter 打开网络设备的流量计数句柄，localAddrs 为该接口的本地IP地址
+func OpenCounter(device string, localAddrs []string) *TrafficCounter {
+	handle, err := OpenSource(device, CounterSnaplen, false)
+	if err != nil {
+		log.Println("无法打开流量统计设备:", device, err)
+		return nil
+	}
+
+	counter := &TrafficCounter{
+		device: device,
+		local:  make(map[string]bool),
+		hosts:  make(map[string]*HostTraffic),
+		stop:   make(chan struct{}),
+	}
+	for _, addr := range localAddrs {
+		counter.local[addr] = true
+	}
+
+	source := gopacket.NewPacketSource(handle, handle.LinkType())
+	source.Lazy = true
+	source.NoCopy = true
+	packets := source.Packets()
+
+	utils.Go(func() {
+		defer handle.Close()
+		for {
+			select {
+			case packet := <-packets:
+				if packet == nil {
+					return
+				}
+				counter.count(packet)
+			case <-counter.stop:
+				return
+			}
+		}
+	})
+	return counter
+}
+
+// count 按方向累加单个数据包
+func (c *TrafficCounter) count(packet gopacket.Packet) {
+	network := packet.NetworkLayer()
+	if network == nil {
+		return
+	}
+	flow := network.NetworkFlow()
+	src, dst := flow.Src().String(), flow.Dst().String()
+	size := uint64(packet.Metadata().Length)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case c.local[src]:
+		h := c.host(dst)
+		h.BytesSent += size
+		h.PacketsSent++
+	case c.local[dst]:
+		h := c.host(src)
+		h.BytesRecv += size
+		h.PacketsRecv++
+	}
+}
+
+func (c *TrafficCounter) host(addr string) *HostTraffic {
+	h, ok := c.hosts[addr]
+	if !ok {
+		h = &HostTraffic{Device: c.device, Host: addr}
+		c.hosts[addr] = h
+	}
+	return h
+}
+
+// Peek 返回上次 Snapshot 以来的各远端主机流量
+func (c *TrafficCounter) Peek() []HostTraffic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.collect()
+}
+
+// Snapshot 返回上次调用以来的各远端主机流量并清零
+func (c *TrafficCounter) Snapshot() []HostTraffic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := c.collect()
+	c.hosts = make(map[string]*HostTraffic)
+	return result
+}
+
+func (c *TrafficCounter) collect() []HostTraffic {
+	result := make([]HostTraffic, 0, len(c.hosts))
+	for _, h := range c.hosts {
+		result = append(result, *h)
+	}
+	return result
+}
+
+// Close 停止计数并释放设备句柄，可重复调用
+func (c *TrafficCounter) Close() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+// TopHosts 按上行字节数排序并截取前 n 个主机
+func TopHosts(hosts []HostTraffic, n int) []HostTraffic {
+	sort.Slice(hosts, func(i, j int) bool {
+		if hosts[i].BytesSent != hosts[j].BytesSent {
+			return hosts[i].BytesSent > hosts[j].BytesSent
+		}
+		return hosts[i].BytesRecv > hosts[j].BytesRecv
+	})
+	if len(hosts) > n {
+		hosts = hosts[:n]
+	}
+	return hosts
+}
+
+// InterfaceSampler 基于系统网卡计数器计算各接口在两次采样之间的流量
+type InterfaceSampler struct {
+	last     map[string]psnet.IOCountersStat
+	lastTime time.Time
+}
+
+// NewInterfaceSampler 创建接口流量采样器
+func NewInterfaceSampler() *InterfaceSampler {
+	return &InterfaceSampler{}
+}
+
+// Sample 读取系统网卡计数器，返回与上次采样之间的差值，首次采样返回空
+func (s *InterfaceSampler) Sample() ([]InterfaceTraffic, error) {
+	counters, err := psnet.IOCounters(true)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	current := make(map[string]psnet.IOCountersStat, len(counters))
+	for _, c := range counters {
+		current[c.Name] = c
+	}
+
+	var result []InterfaceTraffic
+	if s.last != nil {
+		seconds := now.Sub(s.lastTime).Seconds()
+		for name, c := range current {
+			prev, ok := s.last[name]
+			// 计数器回绕或接口重建时跳过本次
+			if !ok || c.BytesSent < prev.BytesSent || c.BytesRecv < prev.BytesRecv {
+				continue
+			}
+			t := InterfaceTraffic{
+				Name:        name,
+				BytesSent:   c.BytesSent - prev.BytesSent,
+				BytesRecv:   c.BytesRecv - prev.BytesRecv,
+				PacketsSent: c.PacketsSent - prev.PacketsSent,
+				PacketsRecv: c.PacketsRecv - prev.PacketsRecv,
+			}
+			if seconds > 0 {
+				t.UploadRate = float64(t.BytesSent) / seconds
+				t.DownloadRate = float64(t.BytesRecv) / seconds
+			}
+			result = append(result, t)
+		}
+	}
+
+	s.last = current
+	s.lastTime = now
+	return result, nil
+}
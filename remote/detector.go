@@ -0,0 +1,240 @@
+package remote
+
+import (
+	"fmt"
+	"log"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// 检测项
+const (
+	SignalProcess    = "process"    // 远程控制工具进程正在运行
+	SignalListen     = "listen"     // 监听远程控制工具的端口
+	SignalConnection = "connection" // 远程控制端口上有入站连接
+	SignalDisplay    = "display"    // 存在远程控制工具提供的或考试开始后出现的X显示
+	SignalSession    = "session"    // 当前桌面本身是远程会话
+)
+
+// Tool 远程控制或屏幕共享工具
+type Tool struct {
+	Name      string
+	Processes []string // 小写进程名，不含 .exe
+	Ports     []uint32 // 默认监听端口
+}
+
+// Tools 需要检测的远程控制工具
+var Tools = []Tool{
+	{Name: "AnyDesk", Processes: []string{"anydesk"}, Ports: []uint32{7070}},
+	{Name: "TeamViewer", Processes: []string{"teamviewer", "teamviewerd", "teamviewer_service", "tv_w32", "tv_x64"}, Ports: []uint32{5938}},
+	{Name: "RustDesk", Processes: []string{"rustdesk"}, Ports: []uint32{21118}},
+	{Name: "ToDesk", Processes: []string{"todesk", "todesk_service"}},
+	{Name: "向日葵", Processes: []string{"sunloginclient", "sunlogin", "sunloginservice"}},
+	{Name: "Chrome Remote Desktop", Processes: []string{"remoting_host", "chrome-remote-desktop-host"}},
+	{Name: "x11vnc", Processes: []string{"x11vnc"}, Ports: portRange(5900, 5909)},
+	{Name: "VNC", Processes: []string{
+		"xvnc", "xtigervnc", "xtightvnc", "vncserver", "x0vncserver", "tigervncserver", "tightvncserver",
+		"vncserver-x11", "vncserver-x11-core", "wayvnc", "krfb", "vino-server", "winvnc", "winvnc4", "tvnserver",
+	}, Ports: portRange(5900, 5909)},
+	{Name: "xrdp", Processes: []string{"xrdp", "xrdp-sesman"}},
+	{Name: "GNOME 远程桌面", Processes: []string{"gnome-remote-desktop-daemon", "gnome-remote-desktop"}},
+	// 无法读取套接字归属进程时，3389 端口统一视为远程桌面(RDP)
+	{Name: "远程桌面", Ports: []uint32{3389}},
+}
+
+// Finding 一条远程访问证据
+type Finding struct {
+	Signal string `json:"signal"`
+	Tool   string `json:"tool"` // 无法确定工具时为空
+	Detail string `json:"detail"`
+	PID    int32  `json:"pid,omitempty"`
+}
+
+// Report 远程访问检测结果
+type Report struct {
+	Active   bool      `json:"active"` // 存在入站连接或当前桌面为远程会话，判定为正在被远程访问
+	Tools    []string  `json:"tools"`
+	Findings []Finding `json:"findings"`
+}
+
+// Detected 是否发现任何远程访问迹象
+func (r Report) Detected() bool {
+	return len(r.Findings) > 0
+}
+
+// Key 用于判断两次检测结果是否相同，忽略PID和连接的远端端口等易变信息
+func (r Report) Key() string {
+	var parts []string
+	for _, f := range r.Findings {
+		part := f.Signal + "/" + f.Tool
+		if !slices.Contains(parts, part) {
+			parts = append(parts, part)
+		}
+	}
+	sort.Strings(parts)
+	return fmt.Sprintf("%t|%s", r.Active, strings.Join(parts, ","))
+}
+
+// Summary 生成上报内容
+func (r Report) Summary() string {
+	details := make([]string, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		details = append(details, f.Detail)
+	}
+	return strings.Join(details, "; ")
+}
+
+// Baseline 开始检测时已经存在的环境，例如登录界面或其他用户的X显示
+type Baseline struct {
+	Displays []string
+}
+
+// NewBaseline 记录当前环境，之后的检测忽略其中不属于远程控制工具的部分
+func NewBaseline() Baseline {
+	return Baseline{Displays: displays()}
+}
+
+// Detect 综合进程、监听端口、入站连接和桌面会话检测远程访问
+func Detect(baseline Baseline) Report {
+	procs, findings := processFindings()
+	findings = append(findings, connectionFindings(procs)...)
+	findings = append(findings, sessionFindings(procs, baseline)...)
+	return newReport(findings)
+}
+
+func newReport(findings []Finding) Report {
+	report := Report{Findings: findings}
+	for _, f := range findings {
+		if f.Signal == SignalConnection || f.Signal == SignalSession {
+			report.Active = true
+		}
+		if f.Tool != "" && !slices.Contains(report.Tools, f.Tool) {
+			report.Tools = append(report.Tools, f.Tool)
+		}
+	}
+	return report
+}
+
+// processFindings 查找远程控制工具进程，返回 PID 到工具名称的映射
+func processFindings() (map[int32]string, []Finding) {
+	procs := make(map[int32]string)
+	list, err := process.Processes()
+	if err != nil {
+		log.Println("获取进程列表失败:", err)
+		return procs, nil
+	}
+
+	var findings []Finding
+	for _, p := range list {
+		name, err := p.Name()
+		if err != nil {
+			continue
+		}
+		tool, ok := lookupTool(name)
+		if !ok {
+			continue
+		}
+		procs[p.Pid] = tool.Name
+		findings = append(findings, Finding{
+			Signal: SignalProcess,
+			Tool:   tool.Name,
+			Detail: fmt.Sprintf("%s 进程 %s(%d) 正在运行", tool.Name, name, p.Pid),
+			PID:    p.Pid,
+		})
+	}
+	return procs, findings
+}
+
+// connectionFindings 检查远程控制工具进程或默认端口上的监听和入站连接
+// 非管理员权限下读取不到其他用户进程的套接字归属，此时按端口判断工具
+// 只监听回环地址的端口无法从其他机器连接，来自回环地址的连接是本机程序之间的通信，都不作为证据
+func connectionFindings(procs map[int32]string) []Finding {
+	conns, err := net.Connections("tcp")
+	if err != nil {
+		log.Println("读取系统连接表失败:", err)
+		return nil
+	}
+
+	listening := make(map[uint32]string) // 本地端口 -> 工具名称
+	var findings []Finding
+	for _, c := range conns {
+		if c.Status != "LISTEN" || isLoopback(c.Laddr.IP) {
+			continue
+		}
+		tool, ok := procs[c.Pid]
+		if !ok {
+			tool, ok = portTool(c.Laddr.Port)
+		}
+		if !ok {
+			continue
+		}
+		if _, seen := listening[c.Laddr.Port]; seen {
+			continue
+		}
+		listening[c.Laddr.Port] = tool
+		findings = append(findings, Finding{
+			Signal: SignalListen,
+			Tool:   tool,
+			Detail: fmt.Sprintf("%s 正在监听端口 %d", tool, c.Laddr.Port),
+			PID:    c.Pid,
+		})
+	}
+
+	// 本地端口为监听端口的已建立连接是入站连接
+	for _, c := range conns {
+		if c.Status != "ESTABLISHED" || isLoopback(c.Raddr.IP) {
+			continue
+		}
+		tool, ok := listening[c.Laddr.Port]
+		if !ok {
+			continue
+		}
+		findings = append(findings, Finding{
+			Signal: SignalConnection,
+			Tool:   tool,
+			Detail: fmt.Sprintf("%s 端口 %d 有来自 %s:%d 的连接", tool, c.Laddr.Port, c.Raddr.IP, c.Raddr.Port),
+			PID:    c.Pid,
+		})
+	}
+	return findings
+}
+
+// isLoopback 是否为回环地址，包括 IPv4 映射的 IPv6 地址
+func isLoopback(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	return err == nil && addr.Unmap().IsLoopback()
+}
+
+// lookupTool 根据进程名查找工具，忽略大小写和 .exe 后缀
+func lookupTool(name string) (Tool, bool) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	for _, tool := range Tools {
+		if slices.Contains(tool.Processes, name) {
+			return tool, true
+		}
+	}
+	return Tool{}, false
+}
+
+// portTool 根据默认端口查找工具
+func portTool(port uint32) (string, bool) {
+	for _, tool := range Tools {
+		if slices.Contains(tool.Ports, port) {
+			return tool.Name, true
+		}
+	}
+	return "", false
+}
+
+func portRange(from, to uint32) []uint32 {
+	ports := make([]uint32, 0, to-from+1)
+	for port := from; port <= to; port++ {
+		ports = append(ports, port)
+	}
+	return ports
+}
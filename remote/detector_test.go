@@ -0,0 +1,123 @@
+package remote
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		findings []Finding
+		active   bool
+		tools    []string
+	}{
+		{"empty", nil, false, nil},
+		{"process", []Finding{{Signal: SignalProcess, Tool: "AnyDesk", PID: 100}}, false, []string{"AnyDesk"}},
+		{"listen", []Finding{
+			{Signal: SignalProcess, Tool: "AnyDesk", PID: 100},
+			{Signal: SignalListen, Tool: "AnyDesk", PID: 100},
+		}, false, []string{"AnyDesk"}},
+		// 入站连接或远程会话判定为正在被远程访问
+		{"connection", []Finding{
+			{Signal: SignalListen, Tool: "VNC"},
+			{Signal: SignalConnection, Tool: "VNC"},
+		}, true, []string{"VNC"}},
+		{"session", []Finding{{Signal: SignalSession, Tool: "xrdp"}}, true, []string{"xrdp"}},
+		{"display-without-tool", []Finding{{Signal: SignalDisplay}}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := newReport(tt.findings)
+			if report.Active != tt.active || !reflect.DeepEqual(report.Tools, tt.tools) {
+				t.Errorf("newReport() = %+v, want Active %v, Tools %q", report, tt.active, tt.tools)
+			}
+			if report.Detected() != (len(tt.findings) > 0) {
+				t.Errorf("Detected() = %v", report.Detected())
+			}
+		})
+	}
+}
+
+func TestReportKey(t *testing.T) {
+	base := newReport([]Finding{
+		{Signal: SignalListen, Tool: "VNC", PID: 100, Detail: "VNC 正在监听端口 5900"},
+		{Signal: SignalConnection, Tool: "VNC", PID: 100, Detail: "VNC 端口 5900 有来自 192.168.1.20:50312 的连接"},
+	})
+
+	tests := []struct {
+		name     string
+		findings []Finding
+		same     bool
+	}{
+		// PID、远端端口和顺序变化不影响结果
+		{"volatile", []Finding{
+			{Signal: SignalConnection, Tool: "VNC", PID: 200, Detail: "VNC 端口 5900 有来自 192.168.1.20:50999 的连接"},
+			{Signal: SignalListen, Tool: "VNC", PID: 200, Detail: "VNC 正在监听端口 5900"},
+		}, true},
+		{"duplicate", []Finding{
+			{Signal: SignalListen, Tool: "VNC"},
+			{Signal: SignalConnection, Tool: "VNC"},
+			{Signal: SignalConnection, Tool: "VNC"},
+		}, true},
+		{"inactive", []Finding{{Signal: SignalListen, Tool: "VNC"}}, false},
+		{"other-tool", []Finding{
+			{Signal: SignalListen, Tool: "AnyDesk"},
+			{Signal: SignalConnection, Tool: "AnyDesk"},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := newReport(tt.findings).Key()
+			if (key == base.Key()) != tt.same {
+				t.Errorf("Key() = %q, base %q, want same %v", key, base.Key(), tt.same)
+			}
+		})
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"127.1.2.3", true},
+		{"::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"192.168.1.20", false},
+		{"::ffff:192.168.1.20", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"", false},
+		{"*", false},
+	}
+	for _, tt := range tests {
+		if got := isLoopback(tt.ip); got != tt.want {
+			t.Errorf("isLoopback(%q) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestLookupTool(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"anydesk", "AnyDesk"},
+		{"AnyDesk.exe", "AnyDesk"},
+		{"TeamViewer_Service.EXE", "TeamViewer"},
+		{"Xvnc", "VNC"},
+		{"x11vnc", "x11vnc"},
+		{"SunloginClient.exe", "向日葵"},
+		{"anydesk.exe.bak", ""},
+		{"firefox", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		tool, ok := lookupTool(tt.name)
+		if ok != (tt.want != "") || tool.Name != tt.want {
+			t.Errorf("lookupTool(%q) = %q, %v, want %q", tt.name, tool.Name, ok, tt.want)
+		}
+	}
+}
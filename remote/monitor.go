@@ -0,0 +1,94 @@
+package remote
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultInterval 默认检测间隔
+const DefaultInterval = 15 * time.Second
+
+// ChangeCallback 检测结果变化回调，previous 为上一次的结果
+type ChangeCallback func(report, previous Report)
+
+// Monitor 定时检测远程访问，只在结果变化时回调
+type Monitor struct {
+	Interval time.Duration
+	OnChange ChangeCallback
+
+	mu       sync.Mutex
+	stop     chan struct{}
+	last     Report
+	baseline Baseline
+}
+
+// NewMonitor 创建远程访问监控
+func NewMonitor(interval time.Duration, onChange ChangeCallback) *Monitor {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Monitor{Interval: interval, OnChange: onChange}
+}
+
+// Start 记录考试开始时的环境，立即检测一次并开始定时检测
+func (m *Monitor) Start() {
+	m.mu.Lock()
+	if m.stop != nil {
+		m.mu.Unlock()
+		return
+	}
+	m.baseline = NewBaseline()
+	m.stop = make(chan struct{})
+	stop := m.stop
+	m.mu.Unlock()
+
+	go m.run(stop)
+}
+
+// Stop 停止检测
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+// Last 返回最近一次检测结果
+func (m *Monitor) Last() Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+func (m *Monitor) run(stop chan struct{}) {
+	m.check()
+
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.check()
+		}
+	}
+}
+
+func (m *Monitor) check() {
+	m.mu.Lock()
+	baseline := m.baseline
+	m.mu.Unlock()
+	report := Detect(baseline)
+
+	m.mu.Lock()
+	previous := m.last
+	m.last = report
+	m.mu.Unlock()
+
+	if report.Key() != previous.Key() && m.OnChange != nil {
+		m.OnChange(report, previous)
+	}
+}
@@ -0,0 +1,135 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// x11SocketDir X服务器的本地套接字目录，每个显示对应一个 X<n>
+var x11SocketDir = "/tmp/.X11-unix"
+
+// sessionEnvs 远程会话启动脚本设置的环境变量
+var sessionEnvs = map[string]string{
+	"XRDP_SESSION": "xrdp",
+	"VNCDESKTOP":   "VNC",
+}
+
+// vncExtensions VNC 服务器在X服务器中注册的扩展
+var vncExtensions = []string{"VNC-EXTENSION", "TIGERVNC"}
+
+// sessionFindings 检查当前桌面是否为远程会话，以及远程控制工具提供的或考试开始后出现的其他X显示
+func sessionFindings(procs map[int32]string, baseline Baseline) []Finding {
+	var findings []Finding
+	for env, tool := range sessionEnvs {
+		if os.Getenv(env) != "" {
+			findings = append(findings, Finding{Signal: SignalSession, Tool: tool, Detail: fmt.Sprintf("当前桌面为 %s 会话(%s)", tool, env)})
+		}
+	}
+	findings = append(findings, extensionFindings()...)
+	findings = append(findings, displayFindings(procs, baseline)...)
+	return findings
+}
+
+// extensionFindings Xvnc 等服务器的桌面会注册 VNC 扩展
+func extensionFindings() []Finding {
+	if os.Getenv("DISPLAY") == "" {
+		return nil
+	}
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	for _, name := range vncExtensions {
+		reply, err := xproto.QueryExtension(conn, uint16(len(name)), name).Reply()
+		if err == nil && reply.Present {
+			return []Finding{{Signal: SignalSession, Tool: "VNC", Detail: "当前X显示注册了 " + name + " 扩展"}}
+		}
+	}
+	return nil
+}
+
+// displays 列出本机所有X显示，例如 ":0"、":1"
+func displays() []string {
+	sockets, _ := filepath.Glob(filepath.Join(x11SocketDir, "X*"))
+	sort.Strings(sockets)
+
+	names := make([]string, 0, len(sockets))
+	for _, socket := range sockets {
+		names = append(names, ":"+strings.TrimPrefix(filepath.Base(socket), "X"))
+	}
+	return names
+}
+
+// displayFindings 当前显示以外的X显示，由远程控制工具提供时注明工具
+// 登录界面和其他用户的桌面也会产生额外显示，无法归属于远程控制工具且考试开始前已存在的显示不作为证据
+func displayFindings(procs map[int32]string, baseline Baseline) []Finding {
+	current := currentDisplay()
+	owners := displayOwners(procs)
+	var findings []Finding
+	for _, display := range displays() {
+		if display == current {
+			continue
+		}
+		if owner, ok := owners[display]; ok {
+			findings = append(findings, Finding{
+				Signal: SignalDisplay,
+				Tool:   owner.tool,
+				Detail: fmt.Sprintf("%s 提供了额外的X显示 %s(PID %d)", owner.tool, display, owner.pid),
+				PID:    owner.pid,
+			})
+			continue
+		}
+		if !slices.Contains(baseline.Displays, display) {
+			findings = append(findings, Finding{Signal: SignalDisplay, Detail: "考试开始后出现额外的X显示 " + display})
+		}
+	}
+	return findings
+}
+
+// currentDisplay 返回 $DISPLAY 的显示编号，例如 ":0"
+func currentDisplay() string {
+	display := os.Getenv("DISPLAY")
+	if i := strings.LastIndex(display, ":"); i >= 0 {
+		display = display[i:]
+	}
+	if i := strings.Index(display, "."); i >= 0 {
+		display = display[:i]
+	}
+	return display
+}
+
+type displayOwner struct {
+	tool string
+	pid  int32
+}
+
+// displayOwners 从远程控制工具的命令行参数中找出其提供的显示，例如 "Xvnc :1"
+func displayOwners(procs map[int32]string) map[string]displayOwner {
+	owners := make(map[string]displayOwner)
+	for pid, tool := range procs {
+		p, err := process.NewProcess(pid)
+		if err != nil {
+			continue
+		}
+		cmdline, err := p.CmdlineSlice()
+		if err != nil {
+			continue
+		}
+		for _, arg := range cmdline {
+			if len(arg) > 1 && arg[0] == ':' && strings.Trim(arg[1:], "0123456789") == "" {
+				owners[arg] = displayOwner{tool: tool, pid: pid}
+			}
+		}
+	}
+	return owners
+}
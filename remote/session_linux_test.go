@@ -0,0 +1,70 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCurrentDisplay(t *testing.T) {
+	tests := []struct {
+		display string
+		want    string
+	}{
+		{":0", ":0"},
+		{":0.0", ":0"},
+		{"host:1", ":1"},
+		{"localhost:10.0", ":10"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Setenv("DISPLAY", tt.display)
+		if got := currentDisplay(); got != tt.want {
+			t.Errorf("DISPLAY=%q currentDisplay() = %q, want %q", tt.display, got, tt.want)
+		}
+	}
+}
+
+// fakeDisplays 在临时目录中创建X显示套接字文件
+func fakeDisplays(t *testing.T, names ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	previous := x11SocketDir
+	x11SocketDir = dir
+	t.Cleanup(func() { x11SocketDir = previous })
+}
+
+func TestDisplayFindings(t *testing.T) {
+	fakeDisplays(t, "X0", "X1")
+	t.Setenv("DISPLAY", ":0.0")
+	if got := displays(); !reflect.DeepEqual(got, []string{":0", ":1"}) {
+		t.Fatalf("displays() = %q", got)
+	}
+
+	// 考试开始前已存在的显示(例如登录界面)不作为证据
+	baseline := NewBaseline()
+	if findings := displayFindings(nil, baseline); len(findings) != 0 {
+		t.Errorf("基线中的显示不应作为证据: %+v", findings)
+	}
+
+	// 考试开始后出现的显示
+	fakeDisplays(t, "X0", "X1", "X2")
+	findings := displayFindings(nil, baseline)
+	if len(findings) != 1 || findings[0].Signal != SignalDisplay || findings[0].Tool != "" {
+		t.Fatalf("displayFindings() = %+v", findings)
+	}
+	if want := "考试开始后出现额外的X显示 :2"; findings[0].Detail != want {
+		t.Errorf("Detail = %q, want %q", findings[0].Detail, want)
+	}
+
+	// 没有基线时当前显示以外的显示都作为证据
+	if findings := displayFindings(nil, Baseline{}); len(findings) != 2 {
+		t.Errorf("没有基线时 displayFindings() = %+v", findings)
+	}
+}
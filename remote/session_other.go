@@ -0,0 +1,13 @@
+//go:build !linux && !windows
+
+package remote
+
+// displays 其他平台不检测额外的显示
+func displays() []string {
+	return nil
+}
+
+// sessionFindings 其他平台只检测进程和连接
+func sessionFindings(procs map[int32]string, baseline Baseline) []Finding {
+	return nil
+}
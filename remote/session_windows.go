@@ -0,0 +1,28 @@
+package remote
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// smRemoteSession GetSystemMetrics 参数，当前进程运行在远程桌面会话中时返回非零值
+const smRemoteSession = 0x1000
+
+var procGetSystemMetrics = windows.NewLazySystemDLL("user32.dll").NewProc("GetSystemMetrics")
+
+// displays Windows 不检测额外的显示
+func displays() []string {
+	return nil
+}
+
+// sessionFindings 检查当前进程是否运行在远程桌面(RDP)会话中
+func sessionFindings(procs map[int32]string, baseline Baseline) []Finding {
+	remote, _, _ := procGetSystemMetrics.Call(smRemoteSession)
+	session := os.Getenv("SESSIONNAME")
+	if remote == 0 && !strings.HasPrefix(strings.ToUpper(session), "RDP-") {
+		return nil
+	}
+	return []Finding{{Signal: SignalSession, Tool: "远程桌面", Detail: "当前桌面为远程桌面会话 " + session}}
+}
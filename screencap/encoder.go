@@ -0,0 +1,228 @@
+package screencap
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"sync"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// Format 截图编码格式
+type Format string
+
+const (
+	FormatJPEG Format = "jpeg"
+	FormatPNG  Format = "png"
+	FormatWebP Format = "webp" // 标准库不含WebP编码器，需通过 RegisterEncoder 注册后才能使用
+)
+
+// EncodeFunc 图像编码函数，quality 为 1-100，无损格式可忽略
+type EncodeFunc func(w io.Writer, img image.Image, quality int) error
+
+var encoders = map[Format]EncodeFunc{
+	FormatJPEG: func(w io.Writer, img image.Image, quality int) error {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	},
+	FormatPNG: func(w io.Writer, img image.Image, _ int) error {
+		encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+		return encoder.Encode(w, img)
+	},
+}
+
+// RegisterEncoder 注册额外的编码格式，例如基于cgo的WebP编码器
+func RegisterEncoder(format Format, encode EncodeFunc) {
+	encoders[format] = encode
+}
+
+// CheckFormat 检查格式是否有已注册的编码器，未注册时返回错误而不是静默改用JPEG
+func CheckFormat(format Format) error {
+	if _, ok := encoders[format]; !ok {
+		return fmt.Errorf("不支持的截图格式: %s", format)
+	}
+	return nil
+}
+
+// Extension 返回格式对应的文件扩展名
+func (f Format) Extension() string {
+	switch f {
+	case FormatJPEG:
+		return "jpg"
+	default:
+		return string(f)
+	}
+}
+
+// EncoderConfig 截图编码配置
+type EncoderConfig struct {
+	Format     Format // 编码格式
+	Quality    int    // JPEG最高质量(1-100)
+	MinQuality int    // 自适应降级时的最低质量
+	MaxWidth   int    // 最大宽度，0表示不限制
+	MaxHeight  int    // 最大高度，0表示不限制
+	Grayscale  bool   // 是否转为灰度图
+}
+
+// DefaultEncoderConfig 默认编码配置: 1080p以内、质量80的JPEG
+var DefaultEncoderConfig = EncoderConfig{
+	Format:     FormatJPEG,
+	Quality:    80,
+	MinQuality: 40,
+	MaxWidth:   1920,
+	MaxHeight:  1080,
+}
+
+const (
+	LowBandwidth   = 100 * 1024 // 低于该上传带宽(字节/秒)时降低质量
+	BacklogLimit   = 2          // 上传队列积压达到该数量时降低质量
+	qualityStep    = 10         // 每次降级降低的质量
+	qualityRecover = 5          // 每次恢复提高的质量
+	minScale       = 0.5        // 质量降到最低后继续缩小分辨率的下限
+	scaleStep      = 0.25
+)
+
+// Encoder 截图编码管线: 缩放、灰度、编码，并根据上传状况自适应调整质量和分辨率
+type Encoder struct {
+	mu      sync.Mutex
+	config  EncoderConfig
+	quality int     // 当前质量
+	scale   float64 // 当前在最大尺寸基础上的缩放比例
+}
+
+// Encoded 编码结果
+type Encoded struct {
+	Buffer  *bytes.Buffer
+	Format  Format
+	Width   int
+	Height  int
+	Quality int
+}
+
+// NewEncoder 创建截图编码器
+func NewEncoder(config EncoderConfig) *Encoder {
+	if err := CheckFormat(config.Format); err != nil {
+		if config.Format != "" {
+			log.Printf("%v，使用JPEG编码", err)
+		}
+		config.Format = DefaultEncoderConfig.Format
+	}
+	if config.Quality <= 0 || config.Quality > 100 {
+		config.Quality = DefaultEncoderConfig.Quality
+	}
+	if config.MinQuality <= 0 || config.MinQuality > config.Quality {
+		config.MinQuality = config.Quality
+	}
+	return &Encoder{config: config, quality: config.Quality, scale: 1}
+}
+
+// Config 返回编码配置
+func (e *Encoder) Config() EncoderConfig {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.config
+}
+
+// Adapt 根据上传队列积压数量和测得的上传带宽(字节/秒，0表示未知)调整质量和分辨率
+// 先降低质量，质量到底后再缩小分辨率；上传恢复顺畅后按相反顺序逐步恢复
+func (e *Encoder) Adapt(backlog int, bandwidth float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	congested := backlog >= BacklogLimit || (bandwidth > 0 && bandwidth < LowBandwidth)
+	healthy := backlog == 0 && (bandwidth == 0 || bandwidth >= 2*LowBandwidth)
+
+	switch {
+	case congested && e.quality > e.config.MinQuality:
+		e.quality = max(e.quality-qualityStep, e.config.MinQuality)
+	case congested && e.scale > minScale:
+		e.scale = max(e.scale-scaleStep, minScale)
+	case healthy && e.scale < 1:
+		e.scale = min(e.scale+scaleStep, 1)
+	case healthy && e.quality < e.config.Quality:
+		e.quality = min(e.quality+qualityRecover, e.config.Quality)
+	default:
+		return
+	}
+	log.Printf("截图编码参数调整: 质量 %d, 缩放 %.2f (积压 %d, 带宽 %.0f B/s)", e.quality, e.scale, backlog, bandwidth)
+}
+
+// Encode 按当前参数处理并编码截图，mark 不为空时在缩放后的图像上绘制水印
+func (e *Encoder) Encode(img image.Image, mark *Watermark) (*Encoded, error) {
+	e.mu.Lock()
+	config, quality, scale := e.config, e.quality, e.scale
+	e.mu.Unlock()
+
+	// 未限制最大尺寸时，自适应缩放以原始尺寸为基准
+	maxWidth, maxHeight := config.MaxWidth, config.MaxHeight
+	if scale < 1 {
+		bounds := img.Bounds()
+		if maxWidth <= 0 || maxWidth > bounds.Dx() {
+			maxWidth = bounds.Dx()
+		}
+		if maxHeight <= 0 || maxHeight > bounds.Dy() {
+			maxHeight = bounds.Dy()
+		}
+	}
+	img = resize(img, int(float64(maxWidth)*scale), int(float64(maxHeight)*scale))
+	if mark != nil {
+		rgba, ok := img.(*image.RGBA)
+		if !ok {
+			rgba = image.NewRGBA(img.Bounds())
+			xdraw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, xdraw.Src)
+			img = rgba
+		}
+		DrawWatermark(rgba, *mark)
+	}
+	if config.Grayscale {
+		img = toGray(img)
+	}
+
+	format := config.Format
+	encode := encoders[format]
+
+	buffer := bytes.NewBuffer(nil)
+	if err := encode(buffer, img, quality); err != nil {
+		return nil, fmt.Errorf("截图编码失败: %w", err)
+	}
+
+	return &Encoded{
+		Buffer:  buffer,
+		Format:  format,
+		Width:   img.Bounds().Dx(),
+		Height:  img.Bounds().Dy(),
+		Quality: quality,
+	}, nil
+}
+
+// resize 等比缩小到不超过 maxWidth*maxHeight，不放大；两者为0时不缩放
+func resize(img image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	ratio := 1.0
+	if maxWidth > 0 && w > maxWidth {
+		ratio = float64(maxWidth) / float64(w)
+	}
+	if maxHeight > 0 && h > maxHeight {
+		ratio = min(ratio, float64(maxHeight)/float64(h))
+	}
+	if ratio >= 1 {
+		return img
+	}
+
+	target := image.NewRGBA(image.Rect(0, 0, max(int(float64(w)*ratio), 1), max(int(float64(h)*ratio), 1)))
+	xdraw.ApproxBiLinear.Scale(target, target.Bounds(), img, bounds, xdraw.Src, nil)
+	return target
+}
+
+// toGray 转换为灰度图，JPEG灰度图只有一个通道，体积明显更小
+func toGray(img image.Image) image.Image {
+	gray := image.NewGray(img.Bounds())
+	xdraw.Draw(gray, gray.Bounds(), img, img.Bounds().Min, xdraw.Src)
+	return gray
+}
@@ -0,0 +1,118 @@
+package screencap
+
+import (
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+// state 返回编码器当前的质量和缩放比例
+func (e *Encoder) state() (int, float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.quality, e.scale
+}
+
+func TestEncoderAdapt(t *testing.T) {
+	e := NewEncoder(EncoderConfig{Format: FormatJPEG, Quality: 80, MinQuality: 60})
+
+	type step struct {
+		backlog   int
+		bandwidth float64
+		quality   int
+		scale     float64
+	}
+	steps := []step{
+		// 先逐步降低质量
+		{BacklogLimit, 0, 70, 1},
+		{0, LowBandwidth / 2, 60, 1},
+		// 质量到底后缩小分辨率，不低于下限
+		{BacklogLimit, 0, 60, 0.75},
+		{BacklogLimit + 3, 0, 60, 0.5},
+		{BacklogLimit, 0, 60, 0.5},
+		// 既不拥堵也不顺畅时保持不变
+		{1, 0, 60, 0.5},
+		{0, LowBandwidth, 60, 0.5},
+		// 恢复时先恢复分辨率，再逐步恢复质量，不超过配置质量
+		{0, 0, 60, 0.75},
+		{0, 2 * LowBandwidth, 60, 1},
+		{0, 0, 65, 1},
+		{0, 0, 70, 1},
+		{0, 0, 75, 1},
+		{0, 0, 80, 1},
+		{0, 0, 80, 1},
+	}
+	for i, s := range steps {
+		e.Adapt(s.backlog, s.bandwidth)
+		if quality, scale := e.state(); quality != s.quality || scale != s.scale {
+			t.Fatalf("第 %d 步 Adapt(%d, %.0f): 质量 %d, 缩放 %.2f, want %d, %.2f",
+				i+1, s.backlog, s.bandwidth, quality, scale, s.quality, s.scale)
+		}
+	}
+}
+
+func TestNewEncoderDefaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     EncoderConfig
+		format     Format
+		quality    int
+		minQuality int
+	}{
+		{"empty", EncoderConfig{}, FormatJPEG, DefaultEncoderConfig.Quality, DefaultEncoderConfig.Quality},
+		{"unregistered", EncoderConfig{Format: FormatWebP, Quality: 70, MinQuality: 30}, FormatJPEG, 70, 30},
+		{"min-above-max", EncoderConfig{Format: FormatPNG, Quality: 50, MinQuality: 90}, FormatPNG, 50, 50},
+		{"out-of-range", EncoderConfig{Format: FormatJPEG, Quality: 120, MinQuality: 40}, FormatJPEG, DefaultEncoderConfig.Quality, 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewEncoder(tt.config).Config()
+			if config.Format != tt.format || config.Quality != tt.quality || config.MinQuality != tt.minQuality {
+				t.Errorf("Config() = %+v", config)
+			}
+		})
+	}
+}
+
+func TestEncoderEncode(t *testing.T) {
+	e := NewEncoder(EncoderConfig{Format: FormatJPEG, Quality: 80, MinQuality: 40, MaxWidth: 1920, MaxHeight: 1080})
+	img := solid(3840, 2160, red)
+
+	encoded, err := e.Encode(img, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded.Width != 1920 || encoded.Height != 1080 || encoded.Quality != 80 || encoded.Format != FormatJPEG {
+		t.Errorf("Encode() = %dx%d, 质量 %d, 格式 %s", encoded.Width, encoded.Height, encoded.Quality, encoded.Format)
+	}
+	decoded, err := jpeg.Decode(encoded.Buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds() != image.Rect(0, 0, 1920, 1080) {
+		t.Errorf("解码尺寸 = %v", decoded.Bounds())
+	}
+
+	// 质量到底后的缩放以最大尺寸为基准
+	for range 6 {
+		e.Adapt(BacklogLimit, 0)
+	}
+	encoded, err = e.Encode(img, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded.Width != 960 || encoded.Height != 540 || encoded.Quality != 40 {
+		t.Errorf("降级后 Encode() = %dx%d, 质量 %d", encoded.Width, encoded.Height, encoded.Quality)
+	}
+
+	// 未限制最大尺寸时以原始尺寸为基准
+	e = NewEncoder(EncoderConfig{Format: FormatJPEG, Quality: 40, MinQuality: 40})
+	e.Adapt(BacklogLimit, 0)
+	encoded, err = e.Encode(solid(1000, 500, red), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded.Width != 750 || encoded.Height != 375 {
+		t.Errorf("未限制最大尺寸时 Encode() = %dx%d, want 750x375", encoded.Width, encoded.Height)
+	}
+}
@@ -0,0 +1,71 @@
+package screencap
+
+import (
+	"image"
+	"math/bits"
+)
+
+// dHash 缩略图尺寸: 9x8，每行相邻像素比较得到 8x8=64 位
+const (
+	hashWidth  = 9
+	hashHeight = 8
+)
+
+// DHash 计算图像的差异哈希(dHash)，画面内容相近的截图哈希的汉明距离也小
+func DHash(img image.Image) uint64 {
+	gray := shrinkGray(img, hashWidth, hashHeight)
+
+	var hash uint64
+	for y := 0; y < hashHeight; y++ {
+		for x := 0; x < hashWidth-1; x++ {
+			hash <<= 1
+			if gray[y*hashWidth+x] < gray[y*hashWidth+x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// HashDistance 返回两个哈希之间的汉明距离
+func HashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// shrinkGray 按区域平均将图像缩小为 w*h 的灰度值
+func shrinkGray(img image.Image, w, h int) []float64 {
+	bounds := img.Bounds()
+	result := make([]float64, w*h)
+	if bounds.Empty() {
+		return result
+	}
+
+	// 大图按步长采样，避免对4K截图逐像素求平均
+	step := 1
+	if cell := bounds.Dx() / w; cell > 64 {
+		step = cell / 32
+	}
+
+	for cy := 0; cy < h; cy++ {
+		y0 := bounds.Min.Y + cy*bounds.Dy()/h
+		y1 := bounds.Min.Y + (cy+1)*bounds.Dy()/h
+		for cx := 0; cx < w; cx++ {
+			x0 := bounds.Min.X + cx*bounds.Dx()/w
+			x1 := bounds.Min.X + (cx+1)*bounds.Dx()/w
+
+			var sum float64
+			var n int
+			for y := y0; y < y1; y += step {
+				for x := x0; x < x1; x += step {
+					r, g, b, _ := img.At(x, y).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+					n++
+				}
+			}
+			if n > 0 {
+				result[cy*w+cx] = sum / float64(n)
+			}
+		}
+	}
+	return result
+}
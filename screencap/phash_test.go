@@ -0,0 +1,82 @@
+package screencap
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// gradient 生成从左到右由暗变亮的灰度图，offset 整体调整亮度
+func gradient(w, h int, offset int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(min(max(x*200/w+offset, 0), 255))
+			img.SetRGBA(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	return img
+}
+
+func TestHashDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0xff, 0x0f, 4},
+		{0, ^uint64(0), 64},
+		{0xaaaaaaaaaaaaaaaa, 0x5555555555555555, 64},
+	}
+	for _, tt := range tests {
+		if got := HashDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("HashDistance(%#x, %#x) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDHash(t *testing.T) {
+	base := gradient(1920, 1080, 0)
+	hash := DHash(base)
+	// 从左到右变亮，每个相邻像素比较都为1
+	if hash != ^uint64(0) {
+		t.Fatalf("DHash(gradient) = %#x", hash)
+	}
+	if got := DHash(solid(640, 480, red)); got != 0 {
+		t.Errorf("DHash(纯色) = %#x, want 0", got)
+	}
+	if got := DHash(image.NewRGBA(image.Rectangle{})); got != 0 {
+		t.Errorf("DHash(空图) = %#x, want 0", got)
+	}
+
+	// 内容发生明显变化的区域
+	changed := gradient(1920, 1080, 0)
+	for y := 0; y < 540; y++ {
+		for x := 0; x < 960; x++ {
+			changed.SetRGBA(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+
+	tests := []struct {
+		name    string
+		img     image.Image
+		maxDist int
+		minDist int
+	}{
+		{"same", gradient(1920, 1080, 0), 0, 0},
+		// 亮度整体变化和缩放不影响哈希
+		{"brighter", gradient(1920, 1080, 20), 0, 0},
+		{"scaled", gradient(960, 540, 0), 0, 0},
+		{"offset-origin", gradient(2020, 1180, 0).SubImage(image.Rect(100, 100, 2020, 1180)), 0, 0},
+		{"changed", changed, 64, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := HashDistance(hash, DHash(tt.img))
+			if d < tt.minDist || d > tt.maxDist {
+				t.Errorf("距离 = %d, want [%d, %d]", d, tt.minDist, tt.maxDist)
+			}
+		})
+	}
+}
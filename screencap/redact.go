@@ -0,0 +1,74 @@
+package screencap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Redaction 截图隐私遮盖配置
+type Redaction struct {
+	Regions   []image.Rectangle // 需要遮盖的区域，使用虚拟桌面坐标
+	Processes []string          // 需要遮盖窗口的进程名，不区分大小写，可省略 .exe 后缀
+}
+
+var (
+	redactionMu sync.RWMutex
+	redaction   Redaction
+)
+
+// SetRedaction 设置截图遮盖配置，对之后的所有截图生效
+func SetRedaction(r Redaction) {
+	redactionMu.Lock()
+	defer redactionMu.Unlock()
+	redaction = r
+}
+
+// currentRedaction 返回当前遮盖配置
+func currentRedaction() Redaction {
+	redactionMu.RLock()
+	defer redactionMu.RUnlock()
+	return redaction
+}
+
+// redactRects 汇总本次截图需要遮盖的区域: 固定区域加上配置进程的窗口
+func (r Redaction) redactRects() []image.Rectangle {
+	rects := append([]image.Rectangle(nil), r.Regions...)
+	if len(r.Processes) > 0 {
+		rects = append(rects, processWindowRects(r.Processes)...)
+	}
+	return rects
+}
+
+// redact 将与显示器相交的区域涂黑，img 的原点对应 bounds.Min
+func redact(img *image.RGBA, bounds image.Rectangle, rects []image.Rectangle) {
+	black := image.NewUniform(color.Black)
+	for _, rect := range rects {
+		target := rect.Intersect(bounds)
+		if target.Empty() {
+			continue
+		}
+		draw.Draw(img, target.Sub(bounds.Min).Add(img.Bounds().Min), black, image.Point{}, draw.Src)
+	}
+}
+
+// matchProcess 判断进程名是否在遮盖名单中
+func matchProcess(name string, processes []string) bool {
+	name = normalizeProcessName(name)
+	if name == "" {
+		return false
+	}
+	for _, p := range processes {
+		if normalizeProcessName(p) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeProcessName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(filepath.Base(name)), ".exe")
+}
@@ -0,0 +1,27 @@
+//go:build linux
+
+package screencap
+
+import (
+	"image"
+	"log"
+
+	"monitor-desktop-client/foreground"
+)
+
+// processWindowRects 通过X11枚举属于指定进程的可见窗口区域
+func processWindowRects(processes []string) []image.Rectangle {
+	windows, err := foreground.ListWindows()
+	if err != nil {
+		log.Printf("枚举窗口失败，无法遮盖进程窗口: %v", err)
+		return nil
+	}
+
+	var rects []image.Rectangle
+	for _, w := range windows {
+		if matchProcess(w.ProcessName, processes) {
+			rects = append(rects, w.Bounds)
+		}
+	}
+	return rects
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package screencap
+
+import "image"
+
+// processWindowRects 目前只支持在 Linux/X11 上按进程遮盖窗口
+func processWindowRects(_ []string) []image.Rectangle {
+	return nil
+}
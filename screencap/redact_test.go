@@ -0,0 +1,77 @@
+package screencap
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	black := color.RGBA{A: 255}
+	// 副显示器位于主显示器右侧，截图原点对应显示器左上角
+	bounds := image.Rect(1920, 0, 3200, 1024)
+	img := solid(bounds.Dx(), bounds.Dy(), red)
+	redact(img, bounds, []image.Rectangle{
+		image.Rect(1800, 100, 2000, 200), // 跨越两个显示器，只遮盖相交部分
+		image.Rect(3000, 900, 3300, 1100),
+		image.Rect(0, 0, 1920, 1080), // 不相交
+	})
+
+	tests := []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		{"cross-left", 0, 100, black},
+		{"cross-right", 79, 199, black},
+		{"cross-outside", 80, 100, red},
+		{"cross-below", 0, 200, red},
+		{"corner", 1279, 1023, black},
+		{"corner-start", 1080, 900, black},
+		{"before-corner", 1079, 900, red},
+		{"untouched", 640, 500, red},
+	}
+	for _, tt := range tests {
+		if got := img.RGBAAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: (%d,%d) = %v, want %v", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestRedactRects(t *testing.T) {
+	regions := []image.Rectangle{image.Rect(0, 0, 100, 100)}
+	SetRedaction(Redaction{Regions: regions})
+	t.Cleanup(func() { SetRedaction(Redaction{}) })
+
+	rects := currentRedaction().redactRects()
+	if len(rects) != 1 || rects[0] != regions[0] {
+		t.Fatalf("redactRects() = %v", rects)
+	}
+	// 返回的区域是副本，修改后不影响配置
+	rects[0] = image.Rect(1, 1, 2, 2)
+	if current := currentRedaction(); current.Regions[0] != regions[0] {
+		t.Errorf("配置被修改: %v", current.Regions)
+	}
+}
+
+func TestMatchProcess(t *testing.T) {
+	processes := []string{"WeChat.exe", "qq", "/usr/bin/keepassxc"}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"WeChat.exe", true},
+		{"wechat", true},
+		{"QQ.EXE", true},
+		{"/opt/tencent/qq", true},
+		{"keepassxc", true},
+		{"wechatapp", false},
+		{"firefox", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := matchProcess(tt.name, processes); got != tt.want {
+			t.Errorf("matchProcess(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,18 +1,128 @@
 package screencap
 
 import (
+	"errors"
+	"fmt"
 	"github.com/kbinani/screenshot"
 	"image"
+	"image/draw"
 	"image/jpeg"
 	"log"
 	"os"
 	"time"
 )
 
-var bounds = screenshot.GetDisplayBounds(0)
+// CaptureMode 截图模式
+type CaptureMode int
 
+const (
+	ModeComposite  CaptureMode = iota // 将所有显示器拼接为一张图
+	ModePerDisplay                    // 每个显示器单独一张图
+)
+
+// ParseCaptureMode 解析配置中的截图模式: composite / per-display，为空时使用拼接模式
+func ParseCaptureMode(name string) (CaptureMode, error) {
+	switch name {
+	case "", "composite":
+		return ModeComposite, nil
+	case "per-display":
+		return ModePerDisplay, nil
+	default:
+		return ModeComposite, fmt.Errorf("不支持的截图模式: %s", name)
+	}
+}
+
+// ErrNoDisplay 没有可用的显示器
+var ErrNoDisplay = errors.New("没有检测到活动的显示器")
+
+// Display 显示器信息
+type Display struct {
+	Index  int             // 显示器序号，-1 表示拼接后的整个桌面
+	Bounds image.Rectangle // 显示器在虚拟桌面中的位置和大小
+}
+
+// Capture 一次截图结果
+type Capture struct {
+	Display
+	DisplayCount int // 截图时的活动显示器数量
+	Image        *image.RGBA
+	Hash         uint64 // 感知哈希(dHash)，用于判断画面是否变化
+}
+
+// Displays 枚举当前所有活动显示器，每次调用都会重新读取以适应分辨率和显示器变化
+func Displays() []Display {
+	n := screenshot.NumActiveDisplays()
+	displays := make([]Display, 0, n)
+	for i := 0; i < n; i++ {
+		bounds := screenshot.GetDisplayBounds(i)
+		if bounds.Empty() {
+			continue
+		}
+		displays = append(displays, Display{Index: i, Bounds: bounds})
+	}
+	return displays
+}
+
+// CaptureDisplays 按模式截取所有显示器
+func CaptureDisplays(mode CaptureMode) ([]Capture, error) {
+	displays := Displays()
+	if len(displays) == 0 {
+		return nil, ErrNoDisplay
+	}
+
+	// 隐私遮盖在拼接、哈希和编码之前完成，被遮盖的内容不会离开本机
+	rects := currentRedaction().redactRects()
+
+	var captures []Capture
+	for _, d := range displays {
+		img, err := screenshot.CaptureRect(d.Bounds)
+		if err != nil {
+			log.Printf("截取显示器 %d 失败: %v", d.Index, err)
+			continue
+		}
+		redact(img, d.Bounds, rects)
+		captures = append(captures, Capture{Display: d, DisplayCount: len(displays), Image: img})
+	}
+	if len(captures) == 0 {
+		return nil, ErrNoDisplay
+	}
+
+	if mode == ModeComposite && len(captures) > 1 {
+		captures = []Capture{stitch(captures, len(displays))}
+	}
+	for i := range captures {
+		captures[i].Hash = DHash(captures[i].Image)
+	}
+	return captures, nil
+}
+
+// stitch 按各显示器在虚拟桌面中的位置拼接截图，空白区域为黑色
+func stitch(captures []Capture, displayCount int) Capture {
+	var union image.Rectangle
+	for _, c := range captures {
+		union = union.Union(c.Bounds)
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, union.Dx(), union.Dy()))
+	for _, c := range captures {
+		target := c.Bounds.Sub(union.Min)
+		draw.Draw(canvas, target, c.Image, c.Image.Bounds().Min, draw.Src)
+	}
+
+	return Capture{
+		Display:      Display{Index: -1, Bounds: union},
+		DisplayCount: displayCount,
+		Image:        canvas,
+	}
+}
+
+// ScreenCap 截取整个桌面，多显示器时返回拼接图
 func ScreenCap() (*image.RGBA, error) {
-	return screenshot.CaptureRect(bounds)
+	captures, err := CaptureDisplays(ModeComposite)
+	if err != nil {
+		return nil, err
+	}
+	return captures[0].Image, nil
 }
 
 func SaveTestCap() {
@@ -0,0 +1,74 @@
+package screencap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// solid 生成指定大小的纯色截图
+func solid(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+var (
+	red  = color.RGBA{R: 255, A: 255}
+	blue = color.RGBA{B: 255, A: 255}
+)
+
+func TestStitch(t *testing.T) {
+	// 主显示器 1920x1080，副显示器 1280x1024 位于左侧并向下错开
+	captures := []Capture{
+		{Display: Display{Index: 0, Bounds: image.Rect(0, 0, 1920, 1080)}, Image: solid(1920, 1080, red)},
+		{Display: Display{Index: 1, Bounds: image.Rect(-1280, 200, 0, 1224)}, Image: solid(1280, 1024, blue)},
+	}
+	c := stitch(captures, 2)
+
+	if c.Index != -1 || c.DisplayCount != 2 || c.Bounds != image.Rect(-1280, 0, 1920, 1224) {
+		t.Fatalf("stitch() = Index %d, DisplayCount %d, Bounds %v", c.Index, c.DisplayCount, c.Bounds)
+	}
+	if got := c.Image.Bounds(); got != image.Rect(0, 0, 3200, 1224) {
+		t.Fatalf("拼接图尺寸 = %v", got)
+	}
+
+	tests := []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		{"primary", 1280, 0, red},
+		{"primary-corner", 3199, 1079, red},
+		{"secondary", 0, 200, blue},
+		{"secondary-corner", 1279, 1223, blue},
+		// 两个显示器都没有覆盖的区域为黑色
+		{"gap-above", 0, 199, color.RGBA{}},
+		{"gap-below", 1280, 1080, color.RGBA{}},
+	}
+	for _, tt := range tests {
+		if got := c.Image.RGBAAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: (%d,%d) = %v, want %v", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestParseCaptureMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    CaptureMode
+		wantErr bool
+	}{
+		{"", ModeComposite, false},
+		{"composite", ModeComposite, false},
+		{"per-display", ModePerDisplay, false},
+		{"mirror", ModeComposite, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCaptureMode(tt.name)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseCaptureMode(%q) = %v, %v", tt.name, got, err)
+		}
+	}
+}
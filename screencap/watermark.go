@@ -0,0 +1,69 @@
+package screencap
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Watermark 截图水印内容
+type Watermark struct {
+	ExamID    int
+	AccountID int
+	Time      time.Time
+	Sequence  uint64 // 本次登录内的截图序号，用于发现缺失或替换的截图
+}
+
+// 水印文字与背景的边距和参考宽度，超过参考宽度的截图按比例放大水印
+const (
+	watermarkPadding   = 4
+	watermarkBaseWidth = 1280
+)
+
+var (
+	watermarkBackground = color.RGBA{A: 160}
+	watermarkForeground = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// Text 返回水印文字
+func (w Watermark) Text() string {
+	return fmt.Sprintf("EXAM %d  ACCT %d  %s  #%d", w.ExamID, w.AccountID, w.Time.Format("2006-01-02 15:04:05"), w.Sequence)
+}
+
+// DrawWatermark 在截图右下角绘制半透明底色的水印，大屏截图按宽度放大水印，小图裁剪水印
+func DrawWatermark(img *image.RGBA, w Watermark) {
+	face := basicfont.Face7x13
+	text := w.Text()
+
+	// 先在小图上绘制文字，再按比例放大贴到截图上
+	width := font.MeasureString(face, text).Ceil() + 2*watermarkPadding
+	height := face.Height + 2*watermarkPadding
+	label := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.Draw(label, label.Bounds(), image.NewUniform(watermarkBackground), image.Point{}, xdraw.Src)
+
+	drawer := font.Drawer{
+		Dst:  label,
+		Src:  image.NewUniform(watermarkForeground),
+		Face: face,
+		Dot:  fixed.P(watermarkPadding, watermarkPadding+face.Ascent),
+	}
+	drawer.DrawString(text)
+
+	bounds := img.Bounds()
+	if scale := bounds.Dx() / watermarkBaseWidth; scale > 1 {
+		scaled := image.NewRGBA(image.Rect(0, 0, width*scale, height*scale))
+		xdraw.NearestNeighbor.Scale(scaled, scaled.Bounds(), label, label.Bounds(), xdraw.Src, nil)
+		label = scaled
+	}
+
+	// 截图比水印窄或矮时从左上角开始绘制并裁掉超出的部分，不压缩文字
+	size := label.Bounds().Size()
+	origin := image.Pt(max(bounds.Max.X-size.X, bounds.Min.X), max(bounds.Max.Y-size.Y, bounds.Min.Y))
+	xdraw.Draw(img, image.Rectangle{Min: origin, Max: origin.Add(size)}, label, image.Point{}, xdraw.Over)
+}
@@ -0,0 +1,90 @@
+package screencap
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+var testWatermark = Watermark{
+	ExamID:    1024,
+	AccountID: 20230001,
+	Time:      time.Date(2026, 9, 1, 8, 30, 0, 0, time.Local),
+	Sequence:  42,
+}
+
+// labelSize 返回未放大的水印尺寸
+func labelSize(w Watermark) image.Point {
+	face := basicfont.Face7x13
+	return image.Pt(font.MeasureString(face, w.Text()).Ceil()+2*watermarkPadding, face.Height+2*watermarkPadding)
+}
+
+// changedBounds 返回与原图不同的像素所在的区域
+func changedBounds(img *image.RGBA, original *image.RGBA) image.Rectangle {
+	var changed image.Rectangle
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.RGBAAt(x, y) != original.RGBAAt(x, y) {
+				changed = changed.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return changed
+}
+
+func TestWatermarkText(t *testing.T) {
+	want := "EXAM 1024  ACCT 20230001  2026-09-01 08:30:00  #42"
+	if got := testWatermark.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+}
+
+func TestDrawWatermark(t *testing.T) {
+	size := labelSize(testWatermark)
+
+	tests := []struct {
+		name  string
+		w, h  int
+		label image.Rectangle // 水印所在区域
+	}{
+		{"bottom-right", 1280, 720, image.Rect(1280-size.X, 720-size.Y, 1280, 720)},
+		// 宽度为参考宽度的整数倍时按倍数放大
+		{"scaled", 2560, 1440, image.Rect(2560-2*size.X, 1440-2*size.Y, 2560, 1440)},
+		// 截图比水印窄时从左边开始绘制，裁掉右侧超出的部分
+		{"narrow", 120, 40, image.Rect(0, 40-size.Y, 120, 40)},
+		{"tiny", 60, 10, image.Rect(0, 0, 60, 10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := solid(tt.w, tt.h, red)
+			DrawWatermark(img, testWatermark)
+			if got := changedBounds(img, solid(tt.w, tt.h, red)); got != tt.label {
+				t.Errorf("水印区域 = %v, want %v", got, tt.label)
+			}
+		})
+	}
+}
+
+// 裁剪后的水印与完整水印的对应部分逐像素相同，文字没有被压缩
+func TestDrawWatermarkClip(t *testing.T) {
+	size := labelSize(testWatermark)
+	full := solid(1280, 720, red)
+	DrawWatermark(full, testWatermark)
+	narrow := solid(120, 40, red)
+	DrawWatermark(narrow, testWatermark)
+
+	fullOrigin := image.Pt(1280-size.X, 720-size.Y)
+	narrowOrigin := image.Pt(0, 40-size.Y)
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < 120; x++ {
+			want := full.RGBAAt(fullOrigin.X+x, fullOrigin.Y+y)
+			if got := narrow.RGBAAt(narrowOrigin.X+x, narrowOrigin.Y+y); got != want {
+				t.Fatalf("(%d,%d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+#!/usr/bin/env bash
+# 模拟 ffmpeg 的 -progress 输出，用于测试 ffmpeg.Supervisor:
+#   MONITOR_FFMPEG=test/fake-ffmpeg.sh 或设置 Supervisor.Binary
+# FAKE_FFMPEG_FAIL_AFTER=N  输出 N 个进度块后以错误退出，用于测试重启
+# FAKE_FFMPEG_DROP=N        每个进度块增加的丢帧数
+frame=0
+drop=0
+block=0
+while true; do
+  block=$((block + 1))
+  frame=$((frame + 15))
+  drop=$((drop + ${FAKE_FFMPEG_DROP:-0}))
+  printf 'frame=%d\nfps=30.00\nbitrate=1987.5kbits/s\ndrop_frames=%d\ndup_frames=0\nout_time=00:00:%02d.000000\nspeed=1.00x\nprogress=continue\n' \
+    "$frame" "$drop" "$((block / 2 % 60))"
+  if [ -n "$FAKE_FFMPEG_FAIL_AFTER" ] && [ "$block" -ge "$FAKE_FFMPEG_FAIL_AFTER" ]; then
+    echo "Connection to tcp://127.0.0.1:1935 failed: Connection refused" >&2
+    exit 1
+  fi
+  if read -r -t 0.5 -n 1 key && [ "$key" = "q" ]; then
+    echo "progress=end"
+    exit 0
+  fi
+done
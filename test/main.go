@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"github.com/google/gopacket/pcap"
 	"log"
 	"monitor-desktop-client/devices"
 	"monitor-desktop-client/ffmpeg"
@@ -11,7 +10,6 @@ import (
 	"monitor-desktop-client/screencap"
 	"monitor-desktop-client/utils"
 	"strings"
-	"syscall"
 	"time"
 )
 
@@ -39,17 +37,19 @@ func UnPackFfmpeg() {
 	if err != nil {
 		log.Println(err)
 	}
-	go func() {
-		err := ffmpeg.RtmpPushScreen("rtmp://localhost:1935/live/test")
-		if err != nil {
-			log.Println(err)
-		}
-	}()
+	push, err := ffmpeg.NewScreenPush("test", "rtmp://localhost:1935/live/test", ffmpeg.ProfileStandard)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err = push.Start(); err != nil {
+		log.Println(err)
+	}
 }
 
 func WatchNetworkInfo() {
 
-	ifs, err := pcap.FindAllDevs()
+	ifs, err := netcap.FindAllDevs()
 	if err != nil {
 		log.Fatal(err)
 		return
@@ -88,15 +88,12 @@ func GetHardwareInfo() {
 
 // MonitorForegroundWindow 监控前台窗口变化
 func MonitorForegroundWindow() {
-	var prev syscall.Handle = 0
+	var prev uintptr
 
 	throttler := utils.NewAdvancedThrottler(time.Second * 3)
 	for {
 		info := foreground.GetWindowInfo()
-		if info == nil {
-			continue
-		}
-		if info.Handle != prev {
+		if info != nil && info.Handle != prev {
 			fmt.Printf("焦点切换 -> 进程: %-20s PID: %-6d 窗口: %-50s 路径: %s\n", info.ProcessName, info.ProcessID, info.Title, info.ProcessPath)
 			throttler.Do(func() {
 				screencap.SaveTestCap()
@@ -3,6 +3,7 @@ package wsc
 import (
 	"errors"
 	"log"
+	"monitor-desktop-client/ffmpeg"
 	"monitor-desktop-client/utils"
 	"time"
 )
@@ -76,11 +77,19 @@ func registerMonitorHandlers(client *WebSocketClient) {
 			log.Printf("发送硬件活动数据失败: %s", err.Error())
 		}
 	})
+
+	// 处理服务器请求 ffmpeg 进程状态
+	client.RegisterHandler("REQUEST_FFMPEG_STATUS", func(message WebSocketMessage) {
+		err := ReportFfmpegStatus(ffmpeg.Statuses()...)
+		if err != nil {
+			log.Printf("发送ffmpeg状态失败: %s", err.Error())
+		}
+	})
 }
 
 // 执行服务器下发的命令
 func executeCommand(command string, params map[string]interface{}) {
-	log.Printf("执行命令: %s, 参数: %v", command, params)
+	log.Printf("执行命令: %s, 参数: %v", command, maskToken(params))
 
 	// 根据命令类型执行不同操作
 	switch command {
@@ -100,11 +109,33 @@ func executeCommand(command string, params map[string]interface{}) {
 		log.Println("收到更新配置命令，准备更新配置...")
 		// 实际更新配置逻辑...
 
+	case "START_STREAM":
+		log.Println("收到开始推流命令")
+		startStream(params)
+
+	case "STOP_STREAM":
+		log.Println("收到停止推流命令")
+		id, _ := params["streamId"].(string)
+		stopStream(id, "服务器停止推流")
+
 	default:
 		log.Printf("未知命令: %s", command)
 	}
 }
 
+// maskToken 隐藏命令参数中的令牌，避免写入日志
+func maskToken(params map[string]interface{}) map[string]interface{} {
+	if _, ok := params["token"]; !ok {
+		return params
+	}
+	masked := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		masked[k] = v
+	}
+	masked["token"] = "***"
+	return masked
+}
+
 func TestWs() {
 	done := make(chan bool)
 	ws := New("ws://127.0.0.1:7777/ws")
@@ -0,0 +1,258 @@
+package wsc
+
+import (
+	"fmt"
+	"log"
+	"monitor-desktop-client/ffmpeg"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReportFfmpegStatus 报告 ffmpeg 进程状态(推流、录屏等)
+func ReportFfmpegStatus(statuses ...ffmpeg.Status) error {
+	client := GetWebSocketClient()
+	if client == nil || !client.IsConnected {
+		return nil
+	}
+
+	message := WebSocketMessage{
+		Type:       "FFMPEG_STATUS",
+		Message:    "ffmpeg进程状态",
+		FromUserId: client.UserId,
+		Timestamp:  time.Now().UnixMilli(),
+		Data:       statuses,
+	}
+
+	return client.SendMessage(message)
+}
+
+// 服务器发起的实时推流
+const (
+	MaxConcurrentStreams = 1                // 同时进行的推流数量上限
+	DefaultStreamTimeout = 10 * time.Minute // 未指定时长时自动停止的时间
+	MaxStreamTimeout     = time.Hour
+	StreamMaxRestarts    = 3 // 推流断开后的最大重启次数
+)
+
+// 推流状态
+const (
+	StreamStarted  = "started"
+	StreamStopped  = "stopped"
+	StreamRejected = "rejected"
+	StreamFailed   = "failed"
+)
+
+// liveStream 进行中的推流
+type liveStream struct {
+	supervisor *ffmpeg.Supervisor
+	timer      *time.Timer
+}
+
+var (
+	streamsMu sync.Mutex
+	streams   = make(map[string]*liveStream)
+
+	// 考试配置的默认编码预设
+	streamProfile = ffmpeg.ProfileStandard
+)
+
+// SetStreamProfile 设置推流默认使用的编码预设，命令中的 profile 参数优先
+func SetStreamProfile(profile string) {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+	if profile == "" {
+		profile = ffmpeg.ProfileStandard
+	}
+	streamProfile = profile
+}
+
+// startStream 处理 START_STREAM 命令
+// 参数: streamId、url(rtmp/rtmps/srt)、token(短期推流令牌)、expiresAt(令牌过期时间，毫秒时间戳)、timeout(秒)、profile(编码预设)
+func startStream(params map[string]interface{}) {
+	id, _ := params["streamId"].(string)
+	rawURL, _ := params["url"].(string)
+	token, _ := params["token"].(string)
+	profile, _ := params["profile"].(string)
+	if id == "" {
+		log.Printf("START_STREAM 缺少 streamId")
+		return
+	}
+
+	if expiresAt, ok := params["expiresAt"].(float64); ok && expiresAt > 0 && time.Now().UnixMilli() > int64(expiresAt) {
+		reportStreamState(id, StreamRejected, "推流令牌已过期")
+		return
+	}
+	target, err := streamURL(rawURL, token)
+	if err != nil {
+		reportStreamState(id, StreamRejected, err.Error())
+		return
+	}
+
+	timeout := DefaultStreamTimeout
+	if seconds, ok := params["timeout"].(float64); ok && seconds > 0 {
+		timeout = min(time.Duration(seconds)*time.Second, MaxStreamTimeout)
+	}
+
+	streamsMu.Lock()
+	if _, ok := streams[id]; ok {
+		streamsMu.Unlock()
+		reportStreamState(id, StreamRejected, "推流已在进行中")
+		return
+	}
+	if len(streams) >= MaxConcurrentStreams {
+		streamsMu.Unlock()
+		reportStreamState(id, StreamRejected, fmt.Sprintf("同时推流数量已达上限 %d", MaxConcurrentStreams))
+		return
+	}
+
+	if profile == "" {
+		profile = streamProfile
+	}
+	supervisor, err := ffmpeg.NewScreenPush("stream-"+id, target, profile)
+	if err != nil {
+		streamsMu.Unlock()
+		reportStreamState(id, StreamRejected, err.Error())
+		return
+	}
+	supervisor.MaxRestarts = StreamMaxRestarts
+	// 推流失败原因会写入日志并上报服务器，不能包含推流令牌
+	supervisor.Redact = func(text string) string {
+		return maskTokenIn(text, token)
+	}
+	if err := supervisor.Start(); err != nil {
+		streamsMu.Unlock()
+		reportStreamState(id, StreamRejected, err.Error())
+		return
+	}
+	stream := &liveStream{
+		supervisor: supervisor,
+		timer: time.AfterFunc(timeout, func() {
+			log.Printf("推流 %s 达到时长上限，自动停止", id)
+			stopStream(id, "达到时长上限")
+		}),
+	}
+	streams[id] = stream
+	streamsMu.Unlock()
+
+	log.Printf("开始推流 %s，最长 %v", id, timeout)
+	reportStreamState(id, StreamStarted, "")
+
+	// 重启次数耗尽时清理并报告失败
+	go func() {
+		<-supervisor.Done()
+		if !removeStream(id, stream) {
+			return
+		}
+		stream.timer.Stop()
+		reportStreamState(id, StreamFailed, supervisor.Status().LastError)
+	}()
+}
+
+// stopStream 停止推流，处理 STOP_STREAM 命令、超时和登出
+func stopStream(id string, reason string) {
+	streamsMu.Lock()
+	stream, ok := streams[id]
+	streamsMu.Unlock()
+	if !ok || !removeStream(id, stream) {
+		return
+	}
+
+	stream.timer.Stop()
+	stream.supervisor.Stop()
+	log.Printf("推流 %s 已停止: %s", id, reason)
+	reportStreamState(id, StreamStopped, reason)
+}
+
+// removeStream 从推流列表中移除，已被移除时返回 false
+func removeStream(id string, stream *liveStream) bool {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+	if streams[id] != stream {
+		return false
+	}
+	delete(streams, id)
+	return true
+}
+
+// StopAllStreams 停止所有推流，在登出时调用
+func StopAllStreams() {
+	streamsMu.Lock()
+	ids := make([]string, 0, len(streams))
+	for id := range streams {
+		ids = append(ids, id)
+	}
+	streamsMu.Unlock()
+
+	for _, id := range ids {
+		stopStream(id, "客户端登出")
+	}
+}
+
+// streamURL 校验推流地址并附加推流令牌，SRT 使用 streamid 传递令牌
+func streamURL(rawURL string, token string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("无效的推流地址")
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "rtmp", "rtmps":
+		if token != "" {
+			q := u.Query()
+			q.Set("token", token)
+			u.RawQuery = q.Encode()
+		}
+	case "srt":
+		if token != "" {
+			q := u.Query()
+			if q.Get("streamid") == "" {
+				q.Set("streamid", token)
+			} else {
+				q.Set("token", token)
+			}
+			u.RawQuery = q.Encode()
+		}
+	default:
+		return "", fmt.Errorf("不支持的推流协议: %s", u.Scheme)
+	}
+	return u.String(), nil
+}
+
+// maskTokenIn 去除文本中的令牌，包括URL查询参数中转义后的形式
+func maskTokenIn(text string, token string) string {
+	if token == "" {
+		return text
+	}
+	text = strings.ReplaceAll(text, token, "***")
+	return strings.ReplaceAll(text, url.QueryEscape(token), "***")
+}
+
+// reportStreamState 报告推流状态
+func reportStreamState(id string, state string, reason string) {
+	if state == StreamRejected || state == StreamFailed {
+		log.Printf("推流 %s %s: %s", id, state, reason)
+	}
+
+	client := GetWebSocketClient()
+	if client == nil || !client.IsConnected {
+		return
+	}
+
+	message := WebSocketMessage{
+		Type:       "STREAM_STATUS",
+		Message:    state,
+		FromUserId: client.UserId,
+		Timestamp:  time.Now().UnixMilli(),
+		Data: map[string]interface{}{
+			"streamId": id,
+			"state":    state,
+			"reason":   reason,
+			"time":     time.Now().Format(time.RFC3339),
+		},
+	}
+	if err := client.SendMessage(message); err != nil {
+		log.Printf("发送推流状态失败: %s", err.Error())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"monitor-desktop-client/utils"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -40,16 +41,14 @@ var (
 
 // SetupWebsocket 设置并连接到后端WebSocket服务
 func SetupWebsocket(serverAddress string, userId string) *WebSocketClient {
-	// 如果已经有连接，先关闭
-	if wsClient != nil && wsClient.IsConnected {
-		if wsClient.Conn != nil {
-			// 使用CloseErr变量来关闭连接
-			wsClient.Conn.Close()
-		}
-	}
+	// 如果已经有连接，先关闭并停止重连
+	DisconnectWebsocket()
 
-	// 构建WebSocket URL
+	// 构建WebSocket URL，传入完整的 ws:// 或 wss:// 端点时直接在末尾拼接用户ID
 	serverUrl = fmt.Sprintf("ws://%s/ws/monitor/%s", serverAddress, userId)
+	if strings.HasPrefix(serverAddress, "ws://") || strings.HasPrefix(serverAddress, "wss://") {
+		serverUrl = strings.TrimSuffix(serverAddress, "/") + "/" + userId
+	}
 
 	// 初始化客户端
 	wsClient = &WebSocketClient{
@@ -286,13 +285,16 @@ func IsClientConnected() bool {
 	return wsClient != nil && wsClient.IsConnected
 }
 
-// DisconnectWebsocket 断开WebSocket连接
+// DisconnectWebsocket 断开WebSocket连接，未连接时也停止后台重连
 func DisconnectWebsocket() {
-	if wsClient != nil && wsClient.IsConnected && wsClient.Conn != nil {
-		wsClient.Conn.Close()
-		wsClient.IsConnected = false
-		stopHeartbeat()
+	if wsClient == nil {
+		return
+	}
+	if wsClient.Conn != nil {
+		wsClient.Conn.Stop()
 	}
+	wsClient.IsConnected = false
+	stopHeartbeat()
 }
 
 // GetWebSocketClient 获取WebSocket客户端实例
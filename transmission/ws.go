@@ -40,6 +40,10 @@ type Wsc struct {
 	onTextMessageReceived func(message string)
 	// 接受到Binary消息回调
 	onBinaryMessageReceived func(data []byte)
+
+	// 调用 Stop 后关闭，停止重连
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 type Config struct {
@@ -98,6 +102,7 @@ func New(url string) *Wsc {
 			connMu:        &sync.RWMutex{},
 			sendMu:        &sync.Mutex{},
 		},
+		stop: make(chan struct{}),
 	}
 }
 
@@ -174,10 +179,19 @@ func (wsc *Wsc) Connect() {
 			if wsc.onConnectError != nil {
 				wsc.onConnectError(err)
 			}
-			// 重试
-			time.Sleep(nextRec)
+			// 重试，Stop 后不再重连
+			select {
+			case <-wsc.stop:
+				return
+			case <-time.After(nextRec):
+			}
 			continue
 		}
+		// 拨号期间调用了 Stop，丢弃新建立的连接
+		if wsc.stopped() {
+			_ = wsc.WebSocket.Conn.Close()
+			return
+		}
 		// 变更连接状态
 		wsc.WebSocket.connMu.Lock()
 		wsc.WebSocket.isConnected = true
@@ -337,9 +351,30 @@ func (wsc *Wsc) closeAndRecConn() {
 		return
 	}
 	wsc.clean()
+	if wsc.stopped() {
+		return
+	}
 	utils.Go(wsc.Connect)
 }
 
+// Stop 停止重连并关闭连接，尚未连接成功时同样生效
+func (wsc *Wsc) Stop() {
+	wsc.stopOnce.Do(func() {
+		close(wsc.stop)
+	})
+	wsc.Close()
+}
+
+// 是否已调用 Stop
+func (wsc *Wsc) stopped() bool {
+	select {
+	case <-wsc.stop:
+		return true
+	default:
+		return false
+	}
+}
+
 // Close 主动关闭连接
 func (wsc *Wsc) Close() {
 	wsc.CloseWithMsg("")
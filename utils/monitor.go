@@ -2,13 +2,21 @@ package utils
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/bits"
+	"math/rand"
 	"mime/multipart"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/process"
@@ -31,21 +39,72 @@ type MonitorDataCollector struct {
 	// 采集间隔时间(秒)
 	ScreenshotInterval int
 	ProcessInterval    int
+
+	// 定时截图触发函数，应与事件触发的截图共用同一个限流器
+	ScreenshotTrigger func()
+
+	// 截图去重: 与上次上传截图的哈希距离小于该值时只上报"未变化"记录，0表示不去重
+	ScreenshotDedupDistance int
+
+	// 截图签名密钥，服务器用同一密钥校验截图的HMAC，为空时不签名
+	SigningKey []byte
+
+	stopCh chan struct{}
+
+	// 各显示器上次上传的截图，用于去重
+	screenshotMu   sync.Mutex
+	lastScreenshot map[int]uploadedScreenshot
+
+	// 截图异步上传队列，积压数量和测得的上传带宽用于自适应调整截图编码
+	screenshotQueue chan screenshotJob
+	uploadMu        sync.Mutex
+	uploadBandwidth float64 // 平滑后的上传带宽(字节/秒)，0表示尚未测量
+
+	// 录屏分段上传队列
+	recordingQueue chan string
+}
+
+// screenshotJob 待上传的截图
+type screenshotJob struct {
+	buffer *bytes.Buffer
+	meta   ScreenshotMeta
 }
 
+// uploadedScreenshot 已上传截图的哈希和地址
+type uploadedScreenshot struct {
+	hash uint64
+	url  string
+}
+
+// ScreenshotJitter 定时截图间隔的随机浮动比例，避免考生预测截图时间
+const ScreenshotJitter = 0.5
+
+const (
+	ScreenshotQueueSize = 8   // 截图上传队列长度，队列满时丢弃新截图
+	RecordingQueueSize  = 32  // 录屏分段上传队列长度，队列满时分段留在本地等待下次上传
+	bandwidthSmoothing  = 0.3 // 上传带宽指数平滑系数
+	minBandwidthSample  = 16 * 1024
+)
+
 // NewMonitorDataCollector 创建监控数据收集器
 func NewMonitorDataCollector(serverURL string, token string, accountID int, examID int) *MonitorDataCollector {
 	return &MonitorDataCollector{
-		ServerURL:         serverURL,
-		Token:             token,
-		AccountID:         accountID,
-		ExamID:            examID,
-		IsRunning:         false,
-		ScreenshotEnabled: true,
-		ProcessEnabled:    true,
-		WebsiteEnabled:    true,
-		BehaviorEnabled:   true,
-		ProcessInterval:   60, // 默认15秒一次进程检查
+		ServerURL:          serverURL,
+		Token:              token,
+		AccountID:          accountID,
+		ExamID:             examID,
+		IsRunning:          false,
+		ScreenshotEnabled:  true,
+		ProcessEnabled:     true,
+		WebsiteEnabled:     true,
+		BehaviorEnabled:    true,
+		ScreenshotInterval: 60, // 默认60秒左右一次定时截图
+		ProcessInterval:    60, // 默认15秒一次进程检查
+
+		ScreenshotDedupDistance: 5, // 64位dHash中少于5位不同视为画面未变化
+		lastScreenshot:          make(map[int]uploadedScreenshot),
+		screenshotQueue:         make(chan screenshotJob, ScreenshotQueueSize),
+		recordingQueue:          make(chan string, RecordingQueueSize),
 	}
 }
 
@@ -56,12 +115,22 @@ func (m *MonitorDataCollector) Start() {
 	}
 
 	m.IsRunning = true
+	m.stopCh = make(chan struct{})
 
 	// 启动进程信息收集
 	if m.ProcessEnabled {
 		go m.startProcessCollection()
 	}
 
+	// 启动截图和录屏分段上传
+	go m.startScreenshotUpload(m.stopCh)
+	go m.startRecordingUpload(m.stopCh)
+
+	// 启动定时截图
+	if m.ScreenshotEnabled && m.ScreenshotInterval > 0 && m.ScreenshotTrigger != nil {
+		go m.startScreenshotSchedule(m.stopCh)
+	}
+
 	// 网站访问记录不需要定期收集，会在访问时即时上报
 
 	fmt.Println("监控数据收集已启动")
@@ -69,6 +138,9 @@ func (m *MonitorDataCollector) Start() {
 
 // Stop 停止数据收集
 func (m *MonitorDataCollector) Stop() {
+	if m.IsRunning {
+		close(m.stopCh)
+	}
 	m.IsRunning = false
 	fmt.Println("监控数据收集已停止")
 }
@@ -101,8 +173,46 @@ func (m *MonitorDataCollector) startProcessCollection() {
 	}
 }
 
+// startScreenshotSchedule 按配置间隔加随机抖动定时截图，收集器停止后退出
+func (m *MonitorDataCollector) startScreenshotSchedule(stop chan struct{}) {
+	for {
+		timer := time.NewTimer(nextScreenshotDelay(m.ScreenshotInterval))
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if m.IsRunning && m.ScreenshotEnabled {
+			m.ScreenshotTrigger()
+		}
+	}
+}
+
+// nextScreenshotDelay 在 interval*(1±ScreenshotJitter) 范围内随机选取下一次截图的等待时间
+func nextScreenshotDelay(intervalSeconds int) time.Duration {
+	interval := time.Duration(intervalSeconds) * time.Second
+	factor := 1 - ScreenshotJitter + rand.Float64()*2*ScreenshotJitter
+	return time.Duration(float64(interval) * factor)
+}
+
+// WebsiteVisit 网站访问记录
+type WebsiteVisit struct {
+	URL         string // 访问地址或域名
+	Title       string // 页面标题
+	ProcessID   int32  // 发起访问的进程ID，未知时为0
+	ProcessName string // 发起访问的进程名
+	Category    string // 域名分类: allowed / forbidden / unknown
+}
+
 // ReportWebsiteVisit 上报网站访问记录
 func (m *MonitorDataCollector) ReportWebsiteVisit(url string, title string) {
+	m.ReportWebsiteVisitDetail(WebsiteVisit{URL: url, Title: title})
+}
+
+// ReportWebsiteVisitDetail 上报带来源进程信息的网站访问记录
+func (m *MonitorDataCollector) ReportWebsiteVisitDetail(visit WebsiteVisit) {
 	if !m.IsRunning || !m.WebsiteEnabled {
 		return
 	}
@@ -110,10 +220,17 @@ func (m *MonitorDataCollector) ReportWebsiteVisit(url string, title string) {
 	visitData := map[string]interface{}{
 		"examId":            m.ExamID,
 		"examineeAccountId": m.AccountID,
-		"url":               url,
-		"title":             title,
+		"url":               visit.URL,
+		"title":             visit.Title,
 		"visitTime":         time.Now().Format("2006-01-02T15:04:05"),
 	}
+	if visit.Category != "" {
+		visitData["category"] = visit.Category
+	}
+	if visit.ProcessID != 0 {
+		visitData["processId"] = visit.ProcessID
+		visitData["processName"] = visit.ProcessName
+	}
 
 	jsonData, err := json.Marshal(visitData)
 	if err != nil {
@@ -128,13 +245,39 @@ func (m *MonitorDataCollector) ReportWebsiteVisit(url string, title string) {
 	}
 
 	// 发送数据
-	url = fmt.Sprintf("%s/monitor/data/website-visit", m.ServerURL)
+	url := fmt.Sprintf("%s/monitor/data/website-visit", m.ServerURL)
 	_, err = HttpPostWithHeaders(url, jsonData, headers)
 	if err != nil {
 		fmt.Printf("上报网站访问记录失败: %v\n", err)
 	}
 }
 
+// 行为事件类型
+const (
+	BehaviorNetworkInterface   = 101 // 网络接口接入或移除
+	BehaviorTrafficAnomaly     = 102 // 持续大流量上传
+	BehaviorDisplayChange      = 201 // 显示器数量变化
+	BehaviorCameraLost         = 301 // 摄像头缺失或断开
+	BehaviorCameraRestored     = 302 // 摄像头恢复
+	BehaviorSpeechDetected     = 303 // 检测到持续说话
+	BehaviorMicrophoneLost     = 304 // 麦克风缺失或断开
+	BehaviorMicrophoneRestored = 305 // 麦克风恢复
+	BehaviorUSBStorageInserted = 401 // 考试期间接入或挂载USB存储设备
+	BehaviorUSBStorageRemoved  = 402 // USB存储设备移除
+	BehaviorUSBStorageBlocked  = 403 // USB存储设备管控操作审计
+	BehaviorDeviceMismatch     = 501 // 登录设备与绑定设备不一致
+	BehaviorRemoteToolRunning  = 601 // 远程控制工具运行或监听端口
+	BehaviorRemoteSession      = 602 // 正在被远程访问
+	BehaviorRemoteSessionEnded = 603 // 远程控制工具或远程会话消失
+)
+
+// 行为事件级别
+const (
+	LevelInfo    = "info"
+	LevelWarning = "warning"
+	LevelHigh    = "high"
+)
+
 // ReportBehavior 上报行为数据
 func (m *MonitorDataCollector) ReportBehavior(eventType int, content string, level string) {
 	if !m.IsRunning || !m.BehaviorEnabled {
@@ -170,6 +313,61 @@ func (m *MonitorDataCollector) ReportBehavior(eventType int, content string, lev
 	}
 }
 
+// HostTraffic 与单个远端主机之间的流量
+type HostTraffic struct {
+	Device      string `json:"device"`
+	Host        string `json:"host"`
+	BytesSent   uint64 `json:"bytesSent"`
+	BytesRecv   uint64 `json:"bytesRecv"`
+	PacketsSent uint64 `json:"packetsSent"`
+	PacketsRecv uint64 `json:"packetsRecv"`
+}
+
+// InterfaceTraffic 单个网络接口在统计周期内的流量
+type InterfaceTraffic struct {
+	Name         string  `json:"name"`
+	BytesSent    uint64  `json:"bytesSent"`
+	BytesRecv    uint64  `json:"bytesRecv"`
+	PacketsSent  uint64  `json:"packetsSent"`
+	PacketsRecv  uint64  `json:"packetsRecv"`
+	UploadRate   float64 `json:"uploadRate"`   // 字节/秒
+	DownloadRate float64 `json:"downloadRate"` // 字节/秒
+}
+
+// ReportTrafficSummary 上报网络流量汇总
+func (m *MonitorDataCollector) ReportTrafficSummary(interfaces []InterfaceTraffic, hosts []HostTraffic) {
+	if !m.IsRunning || !m.WebsiteEnabled {
+		return
+	}
+
+	trafficData := map[string]interface{}{
+		"examId":            m.ExamID,
+		"examineeAccountId": m.AccountID,
+		"recordTime":        time.Now().Format("2006-01-02T15:04:05"),
+		"interfaces":        interfaces,
+		"hosts":             hosts,
+	}
+
+	jsonData, err := json.Marshal(trafficData)
+	if err != nil {
+		fmt.Printf("序列化流量数据失败: %v\n", err)
+		return
+	}
+
+	// 构建请求头
+	headers := map[string]string{
+		"Authorization": "Bearer " + m.Token,
+		"Content-Type":  "application/json",
+	}
+
+	// 发送数据
+	url := fmt.Sprintf("%s/monitor/data/traffic", m.ServerURL)
+	_, err = HttpPostWithHeaders(url, jsonData, headers)
+	if err != nil {
+		fmt.Printf("上报流量汇总失败: %v\n", err)
+	}
+}
+
 // UploadFile 上传文件到服务器
 func (m *MonitorDataCollector) UploadFile(fileBytes []byte, filename string) (string, error) {
 	// 创建一个缓冲区，用于存储multipart表单数据
@@ -212,11 +410,13 @@ func (m *MonitorDataCollector) UploadFile(fileBytes []byte, filename string) (st
 
 	// 发送请求
 	client := &http.Client{Timeout: 30 * time.Second}
+	start := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("发送上传请求失败: %w", err)
 	}
 	defer resp.Body.Close()
+	m.recordUpload(len(fileBytes), time.Since(start))
 
 	// 读取响应
 	respBody, err := ioutil.ReadAll(resp.Body)
@@ -243,17 +443,98 @@ func (m *MonitorDataCollector) UploadFile(fileBytes []byte, filename string) (st
 	return filename, nil
 }
 
+// ScreenshotMeta 截图附加信息
+type ScreenshotMeta struct {
+	DisplayIndex int // 显示器序号，-1 表示多显示器拼接图
+	DisplayCount int // 截图时的活动显示器数量
+	Width        int
+	Height       int
+	Hash         uint64 // 感知哈希(dHash)
+	Format       string // 文件扩展名: jpg / png / webp，为空时为jpg
+	Quality      int    // 编码质量
+	CaptureTime  time.Time
+	Sequence     uint64 // 截图序号，与水印一致
+}
+
+// screenshotTimeLayout 截图时间格式，同时用于文件名、上报记录和签名
+const screenshotTimeLayout = "2006-01-02T15:04:05"
+
+// signScreenshot 计算截图签名: HMAC-SHA256(图像字节 + 换行 + 元数据)，画面未变化的记录图像字节为空
+// 元数据格式: examId|accountId|captureTime|sequence|displayIndex|hash|screenshotUrl
+func (m *MonitorDataCollector) signScreenshot(data []byte, filename string, meta ScreenshotMeta) string {
+	if len(m.SigningKey) == 0 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, m.SigningKey)
+	mac.Write(data)
+	fmt.Fprintf(mac, "\n%d|%d|%s|%d|%d|%016x|%s", m.ExamID, m.AccountID, meta.CaptureTime.Format(screenshotTimeLayout),
+		meta.Sequence, meta.DisplayIndex, meta.Hash, filename)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// recordUpload 记录一次上传的数据量和耗时，更新平滑后的上传带宽
+func (m *MonitorDataCollector) recordUpload(size int, elapsed time.Duration) {
+	// 太小的文件主要耗时在请求往返，不能反映带宽
+	if size < minBandwidthSample || elapsed <= 0 {
+		return
+	}
+	sample := float64(size) / elapsed.Seconds()
+
+	m.uploadMu.Lock()
+	defer m.uploadMu.Unlock()
+	if m.uploadBandwidth == 0 {
+		m.uploadBandwidth = sample
+	} else {
+		m.uploadBandwidth = bandwidthSmoothing*sample + (1-bandwidthSmoothing)*m.uploadBandwidth
+	}
+}
+
+// UploadStats 返回截图上传队列积压数量和平滑后的上传带宽(字节/秒)
+func (m *MonitorDataCollector) UploadStats() (backlog int, bandwidth float64) {
+	m.uploadMu.Lock()
+	defer m.uploadMu.Unlock()
+	return len(m.screenshotQueue), m.uploadBandwidth
+}
+
+// startScreenshotUpload 依次上传队列中的截图，避免截图编码等待网络
+func (m *MonitorDataCollector) startScreenshotUpload(stop chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case job := <-m.screenshotQueue:
+			m.uploadScreenshot(job.buffer, job.meta)
+		}
+	}
+}
+
 // 上报屏幕截图
-func (m *MonitorDataCollector) uploadScreenshot(imageBuffer *bytes.Buffer) {
+func (m *MonitorDataCollector) uploadScreenshot(imageBuffer *bytes.Buffer, meta ScreenshotMeta) {
 	if !m.IsRunning || !m.ScreenshotEnabled {
 		return
 	}
 
+	// 画面与上次上传的截图基本相同时，只发送带哈希的"未变化"记录，保持时间线连续
+	if meta.CaptureTime.IsZero() {
+		meta.CaptureTime = time.Now()
+	}
+	if last, ok := m.unchangedScreenshot(meta); ok {
+		m.reportScreenshotRecord(last.url, meta, true, m.signScreenshot(nil, last.url, meta))
+		return
+	}
+
 	// 创建唯一的文件名
-	timestamp := time.Now().Format("20060102150405")
+	timestamp := meta.CaptureTime.Format("20060102150405")
 	studentId := fmt.Sprintf("%d", m.AccountID)
 	examId := fmt.Sprintf("%d", m.ExamID)
-	filename := fmt.Sprintf("screenshot/screenshot_%s_%s_%s.jpg", examId, studentId, timestamp)
+	ext := meta.Format
+	if ext == "" {
+		ext = "jpg"
+	}
+	filename := fmt.Sprintf("screenshot/screenshot_%s_%s_%s.%s", examId, studentId, timestamp, ext)
+	if meta.DisplayIndex >= 0 && meta.DisplayCount > 1 {
+		filename = fmt.Sprintf("screenshot/screenshot_%s_%s_%s_d%d.%s", examId, studentId, timestamp, meta.DisplayIndex, ext)
+	}
 
 	// 上传文件
 	_, err := m.UploadFile(imageBuffer.Bytes(), filename)
@@ -262,12 +543,47 @@ func (m *MonitorDataCollector) uploadScreenshot(imageBuffer *bytes.Buffer) {
 		return
 	}
 
+	m.screenshotMu.Lock()
+	m.lastScreenshot[meta.DisplayIndex] = uploadedScreenshot{hash: meta.Hash, url: filename}
+	m.screenshotMu.Unlock()
+
+	m.reportScreenshotRecord(filename, meta, false, m.signScreenshot(imageBuffer.Bytes(), filename, meta))
+}
+
+// unchangedScreenshot 判断截图与同一显示器上次上传的截图是否足够相似
+func (m *MonitorDataCollector) unchangedScreenshot(meta ScreenshotMeta) (uploadedScreenshot, bool) {
+	if m.ScreenshotDedupDistance <= 0 {
+		return uploadedScreenshot{}, false
+	}
+
+	m.screenshotMu.Lock()
+	defer m.screenshotMu.Unlock()
+
+	last, ok := m.lastScreenshot[meta.DisplayIndex]
+	if !ok || bits.OnesCount64(last.hash^meta.Hash) >= m.ScreenshotDedupDistance {
+		return uploadedScreenshot{}, false
+	}
+	return last, true
+}
+
+// reportScreenshotRecord 上报截图记录，unchanged 表示画面未变化、复用上次上传的截图地址
+func (m *MonitorDataCollector) reportScreenshotRecord(filename string, meta ScreenshotMeta, unchanged bool, signature string) {
 	// 构建截图记录数据
 	screenshotData := map[string]interface{}{
 		"examId":            m.ExamID,
 		"examineeAccountId": m.AccountID,
-		"captureTime":       time.Now().Format("2006-01-02T15:04:05"),
+		"captureTime":       meta.CaptureTime.Format(screenshotTimeLayout),
 		"screenshotUrl":     filename,
+		"sequence":          meta.Sequence,
+		"signature":         signature,
+		"displayIndex":      meta.DisplayIndex,
+		"displayCount":      meta.DisplayCount,
+		"width":             meta.Width,
+		"height":            meta.Height,
+		"hash":              fmt.Sprintf("%016x", meta.Hash),
+		"format":            meta.Format,
+		"quality":           meta.Quality,
+		"unchanged":         unchanged,
 	}
 
 	jsonData, err := json.Marshal(screenshotData)
@@ -287,14 +603,174 @@ func (m *MonitorDataCollector) uploadScreenshot(imageBuffer *bytes.Buffer) {
 	_, err = HttpPostWithHeaders(url, jsonData, headers)
 	if err != nil {
 		fmt.Printf("上报截图失败: %v\n", err)
+	} else if unchanged {
+		fmt.Printf("画面未变化，跳过上传: %s\n", filename)
 	} else {
 		fmt.Printf("成功上传截图: %s\n", filename)
 	}
 }
 
-// UploadScreenshotData 公开方法，用于上传截图数据
-func (m *MonitorDataCollector) UploadScreenshotData(imageBuffer *bytes.Buffer) {
-	m.uploadScreenshot(imageBuffer)
+// UploadScreenshotData 公开方法，将截图放入上传队列，队列已满时丢弃
+func (m *MonitorDataCollector) UploadScreenshotData(imageBuffer *bytes.Buffer, meta ScreenshotMeta) {
+	select {
+	case m.screenshotQueue <- screenshotJob{buffer: imageBuffer, meta: meta}:
+	default:
+		fmt.Println("截图上传队列已满，丢弃本次截图")
+	}
+}
+
+// UploadCameraPhoto 上传摄像头照片并上报记录，kind 为 identity(身份照片) 或 periodic(定时抓拍)
+func (m *MonitorDataCollector) UploadCameraPhoto(data []byte, kind string) {
+	if !m.IsRunning {
+		return
+	}
+
+	now := time.Now()
+	filename := fmt.Sprintf("camera/camera_%d_%d_%s_%s.jpg", m.ExamID, m.AccountID, kind, now.Format("20060102150405"))
+	_, err := m.UploadFile(data, filename)
+	if err != nil {
+		fmt.Printf("上传摄像头照片失败: %v\n", err)
+		return
+	}
+
+	photoData := map[string]interface{}{
+		"examId":            m.ExamID,
+		"examineeAccountId": m.AccountID,
+		"captureTime":       now.Format("2006-01-02T15:04:05"),
+		"photoUrl":          filename,
+		"photoType":         kind,
+	}
+	jsonData, err := json.Marshal(photoData)
+	if err != nil {
+		fmt.Printf("序列化摄像头照片数据失败: %v\n", err)
+		return
+	}
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + m.Token,
+		"Content-Type":  "application/json",
+	}
+
+	url := fmt.Sprintf("%s/monitor/data/camera", m.ServerURL)
+	_, err = HttpPostWithHeaders(url, jsonData, headers)
+	if err != nil {
+		fmt.Printf("上报摄像头照片失败: %v\n", err)
+	} else {
+		fmt.Printf("成功上传摄像头照片: %s\n", filename)
+	}
+}
+
+// UploadAudioClip 上传说话事件前后的音频片段，返回文件地址，失败时返回空字符串
+func (m *MonitorDataCollector) UploadAudioClip(data []byte, start time.Time) string {
+	if !m.IsRunning {
+		return ""
+	}
+
+	filename := fmt.Sprintf("audio/speech_%d_%d_%s.wav", m.ExamID, m.AccountID, start.Format("20060102150405"))
+	if _, err := m.UploadFile(data, filename); err != nil {
+		fmt.Printf("上传音频片段失败: %v\n", err)
+		return ""
+	}
+	return filename
+}
+
+// ReportAudioLevel 上报一个统计窗口的麦克风音量概况，不包含任何音频内容
+func (m *MonitorDataCollector) ReportAudioLevel(start, end time.Time, avgDBFS, peakDBFS, speechRatio float64) {
+	if !m.IsRunning {
+		return
+	}
+
+	levelData := map[string]interface{}{
+		"examId":            m.ExamID,
+		"examineeAccountId": m.AccountID,
+		"startTime":         start.Format("2006-01-02T15:04:05"),
+		"endTime":           end.Format("2006-01-02T15:04:05"),
+		"avgDbfs":           avgDBFS,
+		"peakDbfs":          peakDBFS,
+		"speechRatio":       speechRatio,
+	}
+	jsonData, err := json.Marshal(levelData)
+	if err != nil {
+		fmt.Printf("序列化音量数据失败: %v\n", err)
+		return
+	}
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + m.Token,
+		"Content-Type":  "application/json",
+	}
+
+	url := fmt.Sprintf("%s/monitor/data/audio", m.ServerURL)
+	if _, err = HttpPostWithHeaders(url, jsonData, headers); err != nil {
+		fmt.Printf("上报音量数据失败: %v\n", err)
+	}
+}
+
+// UploadRecordingSegment 将写完的录屏分段放入上传队列
+func (m *MonitorDataCollector) UploadRecordingSegment(path string) {
+	select {
+	case m.recordingQueue <- path:
+	default:
+		fmt.Printf("录屏上传队列已满，分段留在本地: %s\n", path)
+	}
+}
+
+// startRecordingUpload 依次上传录屏分段
+func (m *MonitorDataCollector) startRecordingUpload(stop chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case path := <-m.recordingQueue:
+			m.uploadRecording(path)
+		}
+	}
+}
+
+// uploadRecording 上传录屏分段并上报记录，成功后删除本地文件
+func (m *MonitorDataCollector) uploadRecording(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("读取录屏分段失败: %v\n", err)
+		return
+	}
+
+	filename := fmt.Sprintf("recording/%d_%d_%s", m.ExamID, m.AccountID, filepath.Base(path))
+	_, err = m.UploadFile(data, filename)
+	if err != nil {
+		fmt.Printf("上传录屏分段失败: %v\n", err)
+		return
+	}
+
+	recordingData := map[string]interface{}{
+		"examId":            m.ExamID,
+		"examineeAccountId": m.AccountID,
+		"recordingUrl":      filename,
+		"size":              len(data),
+		"uploadTime":        time.Now().Format("2006-01-02T15:04:05"),
+	}
+	jsonData, err := json.Marshal(recordingData)
+	if err != nil {
+		fmt.Printf("序列化录屏数据失败: %v\n", err)
+		return
+	}
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + m.Token,
+		"Content-Type":  "application/json",
+	}
+
+	url := fmt.Sprintf("%s/monitor/data/recording", m.ServerURL)
+	_, err = HttpPostWithHeaders(url, jsonData, headers)
+	if err != nil {
+		fmt.Printf("上报录屏记录失败: %v\n", err)
+		return
+	}
+
+	if err := os.Remove(path); err != nil {
+		fmt.Printf("删除已上传的录屏分段失败: %v\n", err)
+	}
+	fmt.Printf("成功上传录屏分段: %s\n", filename)
 }
 
 // 上报进程信息